@@ -111,6 +111,10 @@ func main() {
 		postgresManager,
 		mysqlManager,
 	)
+	monitoringUsecase.StartStatementSnapshots()
+	defer monitoringUsecase.StopStatementSnapshots()
+	monitoringUsecase.StartPostgreSQLRates()
+	defer monitoringUsecase.StopPostgreSQLRates()
 	configUsecase := usecase.NewConfigUsecase(configPath)
 
 	// Initialize HTTP handler (now with managers and configLoader)
@@ -322,16 +322,8 @@ func (h *Handler) GetConnectionStatus(c *fiber.Ctx) error {
 	}
 
 	// Check Kafka
-	if h.kafkaManager.IsConnected() {
-		status["kafka"] = map[string]interface{}{
-			"status":  "connected",
-			"brokers": h.kafkaManager.GetConnectionInfo(),
-		}
-	} else {
-		status["kafka"] = map[string]interface{}{
-			"status": "disconnected",
-		}
-	}
+	kafkaStatus := h.kafkaManager.GetConnectionStatus()
+	status["kafka"] = kafkaStatus
 
 	// Check PostgreSQL
 	pgStatus := h.postgresManager.GetConnectionStatus()
@@ -0,0 +1,839 @@
+// ==================== internal/delivery/http/kafka_handler.go ====================
+package http
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/Danos/backend/internal/infrastructure/kafka"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ==================== Kafka Cluster Endpoints ====================
+
+// kafkaCluster resolves the cluster named by the :cluster route parameter
+func (h *Handler) kafkaCluster(c *fiber.Ctx) (*kafka.ClusterManager, error) {
+	return h.kafkaManager.GetCluster(c.Params("cluster"))
+}
+
+// ListKafkaClusters lists the configured clusters and their connection state
+func (h *Handler) ListKafkaClusters(c *fiber.Ctx) error {
+	return successResponse(c, h.kafkaManager.GetConnectionStatus())
+}
+
+// ==================== Kafka Partition Endpoints ====================
+
+// GetKafkaTopicPartitions lists partition details of a topic. The optional
+// "filter" query narrows the list to under_replicated, offline or
+// non_preferred partitions.
+func (h *Handler) GetKafkaTopicPartitions(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	partitions, err := cluster.GetTopicPartitions(c.Params("topic"))
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	filter := c.Query("filter")
+	if filter == "" {
+		return successResponse(c, partitions)
+	}
+
+	filtered := make([]domain.KafkaPartitionDetail, 0)
+	for _, partition := range partitions {
+		switch filter {
+		case "under_replicated":
+			if partition.UnderReplicated {
+				filtered = append(filtered, partition)
+			}
+		case "offline":
+			if partition.Offline || len(partition.OfflineReplicas) > 0 {
+				filtered = append(filtered, partition)
+			}
+		case "non_preferred":
+			if !partition.IsPreferredLeader {
+				filtered = append(filtered, partition)
+			}
+		default:
+			return errorResponse(c, fiber.StatusBadRequest, "Invalid filter. Use: under_replicated, offline or non_preferred")
+		}
+	}
+
+	return successResponse(c, filtered)
+}
+
+// GetKafkaBrokerPlacement returns leader and replica counts per broker
+func (h *Handler) GetKafkaBrokerPlacement(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	placement, err := cluster.GetBrokerPlacement()
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, placement)
+}
+
+// ==================== Kafka Disk Usage Endpoints ====================
+
+// GetKafkaBrokerDisk returns log dir usage per broker
+func (h *Handler) GetKafkaBrokerDisk(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	disks, err := cluster.GetBrokerDisk()
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, disks)
+}
+
+// GetKafkaTopicsNearRetention lists topics whose largest partition is near
+// retention.bytes. The optional "threshold" query is a percentage and
+// defaults to 80.
+func (h *Handler) GetKafkaTopicsNearRetention(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	threshold := 80.0
+	if value := c.Query("threshold"); value != "" {
+		threshold, err = strconv.ParseFloat(value, 64)
+		if err != nil || threshold < 0 {
+			return errorResponse(c, fiber.StatusBadRequest, "threshold must be a non-negative percentage")
+		}
+	}
+
+	topics, err := cluster.GetTopicsNearRetention(threshold)
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, topics)
+}
+
+// ==================== Kafka Topic Spec Endpoints ====================
+
+// PlanKafkaTopics diffs the topics declared in topics.yaml against the cluster
+func (h *Handler) PlanKafkaTopics(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	plan, err := cluster.PlanTopics(h.configLoader.GetTopicSpecs(c.Params("cluster")))
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, plan)
+}
+
+// ApplyKafkaTopics executes the safe changes of the topics.yaml plan
+func (h *Handler) ApplyKafkaTopics(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	result, err := cluster.ApplyTopics(h.configLoader.GetTopicSpecs(c.Params("cluster")))
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, result)
+}
+
+// ==================== Kafka Reassignment Endpoints ====================
+
+// PlanKafkaReassignment generates a balanced replica assignment without
+// applying it
+func (h *Handler) PlanKafkaReassignment(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var req domain.KafkaReassignmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	plan, err := cluster.PlanReassignment(req)
+	if err != nil {
+		if errors.Is(err, kafka.ErrInvalidReassignment) {
+			return errorResponse(c, fiber.StatusBadRequest, err.Error())
+		}
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, plan)
+}
+
+// ExecuteKafkaReassignment submits a previously generated plan
+func (h *Handler) ExecuteKafkaReassignment(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var req domain.KafkaReassignmentExecuteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	if req.ThrottleBytesPerSec < 0 {
+		return errorResponse(c, fiber.StatusBadRequest, "throttle_bytes_per_sec must not be negative")
+	}
+
+	status, err := cluster.ExecuteReassignment(req)
+	if err != nil {
+		if errors.Is(err, kafka.ErrInvalidReassignment) {
+			return errorResponse(c, fiber.StatusConflict, err.Error())
+		}
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, status)
+}
+
+// GetKafkaReassignment returns the progress of the last reassignment
+func (h *Handler) GetKafkaReassignment(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	status, err := cluster.GetReassignmentStatus()
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+	return successResponse(c, status)
+}
+
+// ElectKafkaPreferredLeaders moves leadership back to preferred replicas
+func (h *Handler) ElectKafkaPreferredLeaders(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var req domain.KafkaLeaderElectionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return errorResponse(c, fiber.StatusBadRequest, "Invalid request body: "+err.Error())
+		}
+	}
+
+	results, err := cluster.ElectPreferredLeaders(req.Topics)
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, results)
+}
+
+// ==================== Kafka ACL Endpoints ====================
+
+// ListKafkaACLs lists ACL bindings. Query parameters resource_type,
+// resource_name, pattern_type, principal, host, operation and permission
+// narrow the result.
+func (h *Handler) ListKafkaACLs(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var filter domain.KafkaACLFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid query: "+err.Error())
+	}
+
+	acls, err := cluster.ListACLs(filter)
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, acls)
+}
+
+// CreateKafkaACLs creates one or more ACL bindings
+func (h *Handler) CreateKafkaACLs(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var req domain.KafkaCreateACLsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	if len(req.ACLs) == 0 {
+		return errorResponse(c, fiber.StatusBadRequest, "acls is required")
+	}
+
+	if err := cluster.CreateACLs(req.ACLs); err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successMessageResponse(c, "ACLs created successfully")
+}
+
+// DeleteKafkaACLs deletes the ACL bindings matching the filter in the body.
+// At least a principal or a resource name is required so a request cannot
+// wipe every ACL by accident.
+func (h *Handler) DeleteKafkaACLs(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var filter domain.KafkaACLFilter
+	if err := c.BodyParser(&filter); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	if filter.Principal == "" && filter.ResourceName == "" {
+		return errorResponse(c, fiber.StatusBadRequest, "principal or resource_name is required")
+	}
+
+	deleted, err := cluster.DeleteACLs(filter)
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, deleted)
+}
+
+// GetKafkaEffectivePermissions shows what a principal may do, per resource
+func (h *Handler) GetKafkaEffectivePermissions(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	principal := c.Query("principal")
+	if principal == "" {
+		return errorResponse(c, fiber.StatusBadRequest, "principal is required")
+	}
+
+	permissions, err := cluster.GetEffectivePermissions(principal, c.Query("host"))
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, permissions)
+}
+
+// ==================== Kafka Quota Endpoints ====================
+
+// ListKafkaQuotas lists client quotas. Query parameters user, client_id,
+// default_user and default_client_id narrow the result.
+func (h *Handler) ListKafkaQuotas(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var filter domain.KafkaQuotaEntity
+	if err := c.QueryParser(&filter); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid query: "+err.Error())
+	}
+
+	quotas, err := cluster.DescribeQuotas(filter)
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, quotas)
+}
+
+// AlterKafkaQuota sets or removes the quotas of a user, client ID or default
+// entity
+func (h *Handler) AlterKafkaQuota(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var req domain.KafkaAlterQuotaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	if err := cluster.AlterQuota(req); err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successMessageResponse(c, "Client quotas altered successfully")
+}
+
+// ==================== Kafka Transaction Endpoints ====================
+
+// ListKafkaTransactions lists transactions. The optional comma separated
+// "state" query selects states; it defaults to every open transaction.
+func (h *Handler) ListKafkaTransactions(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	transactions, err := cluster.GetTransactions(splitQuery(c.Query("state")))
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, transactions)
+}
+
+// GetKafkaHangingTransactions lists open transactions that block the last
+// stable offset without a live transaction behind them. The optional comma
+// separated "topics" query limits the search.
+func (h *Handler) GetKafkaHangingTransactions(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	hanging, err := cluster.GetHangingTransactions(splitQuery(c.Query("topics")))
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, hanging)
+}
+
+// GetKafkaTopicProducers lists active producers per partition of a topic
+func (h *Handler) GetKafkaTopicProducers(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	producers, err := cluster.GetTopicProducers(c.Params("topic"))
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, producers)
+}
+
+// splitQuery splits a comma separated query value, dropping empty items
+func splitQuery(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ==================== Kafka Consumer Group Endpoints ====================
+
+// GetKafkaConsumerGroup returns members, assignments and lag of a group
+func (h *Handler) GetKafkaConsumerGroup(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	group, err := cluster.GetConsumerGroup(c.Params("group"))
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, group)
+}
+
+// DeleteKafkaConsumerGroup deletes an empty consumer group. The body must
+// repeat the group ID in "confirm".
+func (h *Handler) DeleteKafkaConsumerGroup(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var req domain.KafkaDeleteGroupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	groupID := c.Params("group")
+	if req.Confirm != groupID {
+		return errorResponse(c, fiber.StatusBadRequest, "confirm must match the group id")
+	}
+
+	if err := cluster.DeleteConsumerGroup(groupID); err != nil {
+		return errorResponse(c, fiber.StatusConflict, err.Error())
+	}
+	return successMessageResponse(c, "Consumer group deleted successfully")
+}
+
+// DeleteKafkaConsumerGroupOffsets deletes a group's committed offsets for a
+// topic. The body must repeat the group ID in "confirm".
+func (h *Handler) DeleteKafkaConsumerGroupOffsets(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var req domain.KafkaDeleteOffsetsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	groupID := c.Params("group")
+	if req.Topic == "" {
+		return errorResponse(c, fiber.StatusBadRequest, "topic is required")
+	}
+	if req.Confirm != groupID {
+		return errorResponse(c, fiber.StatusBadRequest, "confirm must match the group id")
+	}
+
+	if err := cluster.DeleteConsumerGroupOffsets(groupID, req.Topic, req.Partitions); err != nil {
+		return errorResponse(c, fiber.StatusConflict, err.Error())
+	}
+	return successMessageResponse(c, "Consumer group offsets deleted successfully")
+}
+
+// ==================== Kafka Message Endpoints ====================
+
+// BrowseKafkaMessages returns messages of a topic, decoded with the schema
+// registry when one is configured. Query: partition (default all), offset or
+// timestamp (unix ms) to read forward from, limit.
+func (h *Handler) BrowseKafkaMessages(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	req := domain.KafkaBrowseRequest{Partition: -1, Offset: -1}
+	if err := c.QueryParser(&req); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid query: "+err.Error())
+	}
+
+	messages, err := cluster.BrowseMessages(c.Params("topic"), req)
+	if err != nil {
+		return errorResponse(c, messageErrorStatus(err), err.Error())
+	}
+	return successResponse(c, messages)
+}
+
+// messageErrorStatus maps the errors of message reads to a status code
+func messageErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, kafka.ErrInvalidMessageRequest):
+		return fiber.StatusBadRequest
+	case errors.Is(err, kafka.ErrTopicNotFound), errors.Is(err, kafka.ErrPartitionNotFound):
+		return fiber.StatusNotFound
+	}
+	return fiber.StatusInternalServerError
+}
+
+// SearchKafkaMessages scans recent messages of a topic for keys and values
+// matching regular expressions
+func (h *Handler) SearchKafkaMessages(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var req domain.KafkaSearchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	result, err := cluster.SearchMessages(c.Params("topic"), req)
+	if err != nil {
+		return errorResponse(c, messageErrorStatus(err), err.Error())
+	}
+	return successResponse(c, result)
+}
+
+// ==================== Kafka Schema Registry Endpoints ====================
+
+// ListKafkaSchemaSubjects lists the subjects of the cluster's schema registry
+func (h *Handler) ListKafkaSchemaSubjects(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	subjects, err := cluster.GetSchemaSubjects()
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, subjects)
+}
+
+// ListKafkaSchemaVersions lists the versions of a subject
+func (h *Handler) ListKafkaSchemaVersions(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	versions, err := cluster.GetSchemaVersions(c.Params("subject"))
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, versions)
+}
+
+// GetKafkaSchema returns a version of a subject; the version may be "latest"
+func (h *Handler) GetKafkaSchema(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	schema, err := cluster.GetSchema(c.Params("subject"), c.Params("version"))
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, schema)
+}
+
+// GetKafkaSchemaCompatibility returns the compatibility level of a subject,
+// or the global level on the route without a subject
+func (h *Handler) GetKafkaSchemaCompatibility(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	compatibility, err := cluster.GetSchemaCompatibility(c.Params("subject"))
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, compatibility)
+}
+
+// ==================== Kafka Connect Endpoints ====================
+
+// GetKafkaConnect describes the Kafka Connect cluster of a Kafka cluster:
+// connectors with their tasks and failure traces, workers and plugins
+func (h *Handler) GetKafkaConnect(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	connect, err := cluster.GetConnect()
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, connect)
+}
+
+// GetKafkaConnector returns a connector with its config and tasks
+func (h *Handler) GetKafkaConnector(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	connector, err := cluster.GetConnector(c.Params("connector"))
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, connector)
+}
+
+// PauseKafkaConnector suspends a connector and its tasks
+func (h *Handler) PauseKafkaConnector(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	if err := cluster.PauseConnector(c.Params("connector")); err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successMessageResponse(c, "Connector pause requested")
+}
+
+// ResumeKafkaConnector resumes a paused connector
+func (h *Handler) ResumeKafkaConnector(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	if err := cluster.ResumeConnector(c.Params("connector")); err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successMessageResponse(c, "Connector resume requested")
+}
+
+// RestartKafkaConnector restarts a connector. The optional body selects
+// whether its tasks, or only the failed ones, restart too.
+func (h *Handler) RestartKafkaConnector(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var req domain.KafkaConnectRestartRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return errorResponse(c, fiber.StatusBadRequest, "Invalid request body: "+err.Error())
+		}
+	}
+
+	if err := cluster.RestartConnector(c.Params("connector"), req); err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successMessageResponse(c, "Connector restart requested")
+}
+
+// RestartKafkaConnectorTask restarts a single task of a connector
+func (h *Handler) RestartKafkaConnectorTask(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	task, err := strconv.Atoi(c.Params("task"))
+	if err != nil || task < 0 {
+		return errorResponse(c, fiber.StatusBadRequest, "task must be a non-negative number")
+	}
+
+	if err := cluster.RestartConnectorTask(c.Params("connector"), task); err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successMessageResponse(c, "Connector task restart requested")
+}
+
+// UpdateKafkaConnectorConfig replaces the config of an existing connector.
+// The body is the full connector config.
+func (h *Handler) UpdateKafkaConnectorConfig(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var config map[string]string
+	if err := c.BodyParser(&config); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	connector, err := cluster.UpdateConnectorConfig(c.Params("connector"), config)
+	if err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	return successResponse(c, connector)
+}
+
+// ==================== Kafka Job Endpoints ====================
+
+// StartKafkaSchemaInference starts a job that samples recent messages of a
+// topic and infers the schema of their JSON values
+func (h *Handler) StartKafkaSchemaInference(c *fiber.Ctx) error {
+	if _, err := h.kafkaCluster(c); err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var req domain.KafkaSchemaInferenceRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return errorResponse(c, fiber.StatusBadRequest, "Invalid request body: "+err.Error())
+		}
+	}
+
+	job, err := h.kafkaManager.StartSchemaInference(c.Params("cluster"), c.Params("topic"), req)
+	if err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	return successResponse(c, job)
+}
+
+// StartKafkaReplay starts a job that copies messages of a topic to a
+// destination topic, on the same or another cluster
+func (h *Handler) StartKafkaReplay(c *fiber.Ctx) error {
+	if _, err := h.kafkaCluster(c); err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var req domain.KafkaReplayRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+
+	job, err := h.kafkaManager.StartReplay(c.Params("cluster"), c.Params("topic"), req)
+	if err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	return successResponse(c, job)
+}
+
+// GetKafkaReplication reports replication lag and checkpoint freshness of
+// the MirrorMaker 2 flows into a cluster
+func (h *Handler) GetKafkaReplication(c *fiber.Ctx) error {
+	if _, err := h.kafkaCluster(c); err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	statuses, err := h.kafkaManager.GetReplicationStatus(c.Params("cluster"))
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, statuses)
+}
+
+// ListKafkaGroupEvents lists recent consumer group membership, assignment
+// and rebalance events, newest first. Query parameters group and type filter
+// the events.
+func (h *Handler) ListKafkaGroupEvents(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return errorResponse(c, fiber.StatusBadRequest, "limit must be a non-negative number")
+		}
+	}
+
+	events, err := cluster.GetGroupEvents(c.Query("group"), c.Query("type"), limit)
+	if err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	return successResponse(c, events)
+}
+
+// GetKafkaQuorum describes the KRaft controller quorum: the active
+// controller, voters and observers with their log end offsets and fetch times
+func (h *Handler) GetKafkaQuorum(c *fiber.Ctx) error {
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	quorum, err := cluster.GetQuorum()
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, quorum)
+}
+
+// ListKafkaJobs lists running and recently finished jobs of every cluster
+func (h *Handler) ListKafkaJobs(c *fiber.Ctx) error {
+	return successResponse(c, h.kafkaManager.ListJobs())
+}
+
+// GetKafkaJob returns the progress or result of a job
+func (h *Handler) GetKafkaJob(c *fiber.Ctx) error {
+	job, err := h.kafkaManager.GetJob(c.Params("id"))
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+	return successResponse(c, job)
+}
+
+// CancelKafkaJob stops a running job
+func (h *Handler) CancelKafkaJob(c *fiber.Ctx) error {
+	if err := h.kafkaManager.CancelJob(c.Params("id")); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	return successMessageResponse(c, "Job cancelled")
+}
@@ -0,0 +1,115 @@
+// ==================== internal/delivery/http/postgres_handler.go ====================
+package http
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/Danos/backend/internal/usecase"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ==================== PostgreSQL Session Endpoints ====================
+
+// ListPostgreSQLSessions lists the client sessions of a database. Query
+// parameters state and min_duration (seconds in the current state) filter
+// the sessions. The database must have monitoring.track_activity enabled.
+func (h *Handler) ListPostgreSQLSessions(c *fiber.Ctx) error {
+	name := c.Params("database")
+	if _, err := h.postgresManager.GetClient(name); err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var filter domain.PostgreSQLSessionFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid query: "+err.Error())
+	}
+
+	sessions, err := h.monitoringUsecase.GetPostgreSQLSessions(name, filter)
+	if err != nil {
+		if errors.Is(err, usecase.ErrActivityTrackingDisabled) {
+			return errorResponse(c, fiber.StatusForbidden, err.Error())
+		}
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, sessions)
+}
+
+// CancelPostgreSQLSession cancels the running query of a session. The body
+// must repeat the PID in "confirm".
+func (h *Handler) CancelPostgreSQLSession(c *fiber.Ctx) error {
+	return h.signalPostgreSQLSession(c, false)
+}
+
+// TerminatePostgreSQLSession closes a session. The body must repeat the PID
+// in "confirm".
+func (h *Handler) TerminatePostgreSQLSession(c *fiber.Ctx) error {
+	return h.signalPostgreSQLSession(c, true)
+}
+
+func (h *Handler) signalPostgreSQLSession(c *fiber.Ctx, terminate bool) error {
+	name := c.Params("database")
+	if _, err := h.postgresManager.GetClient(name); err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	pid, err := strconv.Atoi(c.Params("pid"))
+	if err != nil || pid <= 0 {
+		return errorResponse(c, fiber.StatusBadRequest, "pid must be a positive number")
+	}
+
+	var req domain.PostgreSQLSignalRequest
+	if err := c.BodyParser(&req); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+	if req.Confirm != pid {
+		return errorResponse(c, fiber.StatusBadRequest, "confirm must match the pid")
+	}
+
+	message := "Session query cancelled successfully"
+	if terminate {
+		err = h.monitoringUsecase.TerminatePostgreSQLSession(name, pid)
+		message = "Session terminated successfully"
+	} else {
+		err = h.monitoringUsecase.CancelPostgreSQLSession(name, pid)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrActivityTrackingDisabled):
+			return errorResponse(c, fiber.StatusForbidden, err.Error())
+		case errors.Is(err, usecase.ErrSessionNotFound):
+			return errorResponse(c, fiber.StatusNotFound, err.Error())
+		}
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successMessageResponse(c, message)
+}
+
+// ==================== PostgreSQL Statement Endpoints ====================
+
+// GetPostgreSQLTopStatements ranks the pg_stat_statements statements of a
+// database over the last window. Query: sort, limit, window (minutes).
+func (h *Handler) GetPostgreSQLTopStatements(c *fiber.Ctx) error {
+	name := c.Params("database")
+	if _, err := h.postgresManager.GetClient(name); err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var req domain.PostgreSQLTopStatementsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid query: "+err.Error())
+	}
+
+	statements, err := h.monitoringUsecase.GetPostgreSQLTopStatements(name, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidStatementRequest):
+			return errorResponse(c, fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, usecase.ErrStatementsUnavailable):
+			return errorResponse(c, fiber.StatusConflict, err.Error())
+		}
+		return errorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return successResponse(c, statements)
+}
@@ -43,6 +43,70 @@ func SetupRoutes(app *fiber.App, handler *Handler, wsManager *WebSocketManager)
 		config.Post("/mysql", handler.SaveMySQLConfig)
 	}
 
+	// Kafka endpoints
+	kafka := api.Group("/kafka")
+	{
+		kafka.Get("/clusters", handler.ListKafkaClusters)
+		kafka.Get("/jobs", handler.ListKafkaJobs)
+		kafka.Get("/jobs/:id", handler.GetKafkaJob)
+		kafka.Delete("/jobs/:id", handler.CancelKafkaJob)
+	}
+
+	// Endpoints of a single named Kafka cluster
+	cluster := kafka.Group("/clusters/:cluster")
+	{
+		cluster.Get("/topics/:topic/partitions", handler.GetKafkaTopicPartitions)
+		cluster.Get("/brokers/placement", handler.GetKafkaBrokerPlacement)
+		cluster.Get("/brokers/disk", handler.GetKafkaBrokerDisk)
+		cluster.Get("/quorum", handler.GetKafkaQuorum)
+		cluster.Get("/topics/retention", handler.GetKafkaTopicsNearRetention)
+		cluster.Get("/topics/plan", handler.PlanKafkaTopics)
+		cluster.Post("/topics/apply", handler.ApplyKafkaTopics)
+		cluster.Post("/reassignments/plan", handler.PlanKafkaReassignment)
+		cluster.Post("/reassignments/execute", handler.ExecuteKafkaReassignment)
+		cluster.Get("/reassignments", handler.GetKafkaReassignment)
+		cluster.Post("/leaders/elect", handler.ElectKafkaPreferredLeaders)
+		cluster.Get("/acls", handler.ListKafkaACLs)
+		cluster.Post("/acls", handler.CreateKafkaACLs)
+		cluster.Delete("/acls", handler.DeleteKafkaACLs)
+		cluster.Get("/acls/effective", handler.GetKafkaEffectivePermissions)
+		cluster.Get("/topics/:topic/producers", handler.GetKafkaTopicProducers)
+		cluster.Get("/transactions", handler.ListKafkaTransactions)
+		cluster.Get("/transactions/hanging", handler.GetKafkaHangingTransactions)
+		cluster.Get("/quotas", handler.ListKafkaQuotas)
+		cluster.Post("/quotas", handler.AlterKafkaQuota)
+		cluster.Get("/groups/:group", handler.GetKafkaConsumerGroup)
+		cluster.Delete("/groups/:group", handler.DeleteKafkaConsumerGroup)
+		cluster.Delete("/groups/:group/offsets", handler.DeleteKafkaConsumerGroupOffsets)
+		cluster.Get("/group-events", handler.ListKafkaGroupEvents)
+		cluster.Get("/topics/:topic/messages", handler.BrowseKafkaMessages)
+		cluster.Post("/topics/:topic/messages/search", handler.SearchKafkaMessages)
+		cluster.Get("/schemas/subjects", handler.ListKafkaSchemaSubjects)
+		cluster.Get("/schemas/subjects/:subject/versions", handler.ListKafkaSchemaVersions)
+		cluster.Get("/schemas/subjects/:subject/versions/:version", handler.GetKafkaSchema)
+		cluster.Get("/schemas/subjects/:subject/compatibility", handler.GetKafkaSchemaCompatibility)
+		cluster.Get("/schemas/compatibility", handler.GetKafkaSchemaCompatibility)
+		cluster.Post("/topics/:topic/schema/infer", handler.StartKafkaSchemaInference)
+		cluster.Post("/topics/:topic/replay", handler.StartKafkaReplay)
+		cluster.Get("/replication", handler.GetKafkaReplication)
+		cluster.Get("/connect", handler.GetKafkaConnect)
+		cluster.Get("/connect/connectors/:connector", handler.GetKafkaConnector)
+		cluster.Put("/connect/connectors/:connector/pause", handler.PauseKafkaConnector)
+		cluster.Put("/connect/connectors/:connector/resume", handler.ResumeKafkaConnector)
+		cluster.Post("/connect/connectors/:connector/restart", handler.RestartKafkaConnector)
+		cluster.Post("/connect/connectors/:connector/tasks/:task/restart", handler.RestartKafkaConnectorTask)
+		cluster.Put("/connect/connectors/:connector/config", handler.UpdateKafkaConnectorConfig)
+	}
+
+	// Endpoints of a single named PostgreSQL database
+	postgresql := api.Group("/postgresql/:database")
+	{
+		postgresql.Get("/sessions", handler.ListPostgreSQLSessions)
+		postgresql.Post("/sessions/:pid/cancel", handler.CancelPostgreSQLSession)
+		postgresql.Post("/sessions/:pid/terminate", handler.TerminatePostgreSQLSession)
+		postgresql.Get("/statements/top", handler.GetPostgreSQLTopStatements)
+	}
+
 	// Connection control endpoints
 	connections := api.Group("/connections")
 	{
@@ -53,4 +117,5 @@ func SetupRoutes(app *fiber.App, handler *Handler, wsManager *WebSocketManager)
 
 	// WebSocket endpoint
 	api.Get("/ws/metrics", WebSocketUpgrade, websocket.New(wsManager.HandleWebSocket))
+	api.Get("/ws/kafka/clusters/:cluster/tail", handler.KafkaTailUpgrade, websocket.New(handler.TailKafkaTopics))
 }
@@ -2,10 +2,13 @@
 package http
 
 import (
+	"context"
 	"log"
 	"sync"
 	"time"
 
+	"github.com/Danos/backend/internal/domain"
+	"github.com/Danos/backend/internal/infrastructure/kafka"
 	"github.com/Danos/backend/internal/usecase"
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
@@ -132,3 +135,67 @@ func (wsm *WebSocketManager) Stop() {
 
 	log.Println("WebSocket manager stopped")
 }
+
+// ==================== Kafka Topic Tail ====================
+
+// KafkaTailUpgrade validates a tail subscription before the websocket upgrade
+// so that bad requests get a plain HTTP error. The "topics" query is a comma
+// separated list, "key" and "value" are regular expression filters and
+// "rate" caps the messages sent per second.
+func (h *Handler) KafkaTailUpgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.ErrUpgradeRequired
+	}
+
+	cluster, err := h.kafkaCluster(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	var req domain.KafkaTailRequest
+	if err := c.QueryParser(&req); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, "Invalid query: "+err.Error())
+	}
+	req.Topics = splitQuery(c.Query("topics"))
+	if len(req.Topics) == 0 {
+		return errorResponse(c, fiber.StatusBadRequest, "topics is required")
+	}
+	if req.MaxPerSec < 0 {
+		return errorResponse(c, fiber.StatusBadRequest, "rate must not be negative")
+	}
+
+	c.Locals("kafkaCluster", cluster)
+	c.Locals("kafkaTail", req)
+	return c.Next()
+}
+
+// TailKafkaTopics streams new messages of the requested topics as
+// KafkaTailEvent frames until the client disconnects
+func (h *Handler) TailKafkaTopics(c *websocket.Conn) {
+	cluster := c.Locals("kafkaCluster").(*kafka.ClusterManager)
+	req := c.Locals("kafkaTail").(domain.KafkaTailRequest)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer c.Close()
+
+	// The client sends nothing, reading only detects the disconnect
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := c.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	err := cluster.TailTopics(ctx, req, func(event domain.KafkaTailEvent) error {
+		return c.WriteJSON(event)
+	})
+	if err != nil {
+		log.Printf("Kafka tail ended: %v", err)
+		if writeErr := c.WriteJSON(domain.KafkaTailEvent{Type: "error", Error: err.Error()}); writeErr != nil {
+			log.Printf("Error sending tail error: %v", writeErr)
+		}
+	}
+}
@@ -27,16 +27,69 @@ type RedisMonitoring struct {
 
 // ==================== Kafka Configuration ====================
 type KafkaConfig struct {
+	Clusters []KafkaClusterConfig `yaml:"clusters" json:"clusters"`
+
+	// Single-cluster layout, loaded as a cluster named "default" when no
+	// clusters are listed
+	Brokers    []string        `yaml:"brokers,omitempty" json:"brokers,omitempty"`
+	Security   KafkaSecurity   `yaml:"security,omitempty" json:"security,omitempty"`
+	Monitoring KafkaMonitoring `yaml:"monitoring,omitempty" json:"monitoring,omitempty"`
+}
+
+type KafkaClusterConfig struct {
+	Name       string          `yaml:"name" json:"name"`
 	Brokers    []string        `yaml:"brokers" json:"brokers"`
 	Security   KafkaSecurity   `yaml:"security" json:"security"`
 	Monitoring KafkaMonitoring `yaml:"monitoring" json:"monitoring"`
+	Canary     KafkaCanary     `yaml:"canary" json:"canary"`
+
+	SchemaRegistry KafkaSchemaRegistry `yaml:"schema_registry" json:"schema_registry"`
+
+	// MirrorMaker 2 flows replicating into this cluster
+	Replication []KafkaReplicationFlow `yaml:"replication" json:"replication"`
+
+	// Kafka Connect cluster writing to or reading from this cluster
+	Connect KafkaConnect `yaml:"connect" json:"connect"`
 }
 
 type KafkaSecurity struct {
-	Protocol      string `yaml:"protocol" json:"protocol"`             // PLAINTEXT, SSL, SASL_PLAINTEXT, SASL_SSL
-	SASLMechanism string `yaml:"sasl_mechanism" json:"sasl_mechanism"` // PLAIN, SCRAM-SHA-256, SCRAM-SHA-512
-	Username      string `yaml:"username" json:"username"`
-	Password      string `yaml:"password" json:"password"`
+	Protocol      string            `yaml:"protocol" json:"protocol"`             // PLAINTEXT, SSL, SASL_PLAINTEXT, SASL_SSL
+	SASLMechanism string            `yaml:"sasl_mechanism" json:"sasl_mechanism"` // PLAIN, SCRAM-SHA-256, SCRAM-SHA-512, OAUTHBEARER, GSSAPI
+	Username      string            `yaml:"username" json:"username"`
+	Password      string            `yaml:"password" json:"password"`
+	TLS           KafkaTLS          `yaml:"tls" json:"tls"`
+	OAuth         KafkaOAuth        `yaml:"oauth" json:"oauth"`
+	Kerberos      KafkaKerberos     `yaml:"kerberos" json:"kerberos"`
+	Properties    map[string]string `yaml:"properties" json:"properties"` // extra librdkafka properties, applied last
+}
+
+// KafkaTLS holds certificates either as file paths or inline PEM. Inline PEM
+// wins when both are set.
+type KafkaTLS struct {
+	CALocation         string `yaml:"ca_location" json:"ca_location"`
+	CAPEM              string `yaml:"ca_pem" json:"ca_pem"`
+	CertLocation       string `yaml:"cert_location" json:"cert_location"`
+	CertPEM            string `yaml:"cert_pem" json:"cert_pem"`
+	KeyLocation        string `yaml:"key_location" json:"key_location"`
+	KeyPEM             string `yaml:"key_pem" json:"key_pem"`
+	KeyPassword        string `yaml:"key_password" json:"key_password"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" json:"insecure_skip_verify"`
+}
+
+// KafkaOAuth configures OAUTHBEARER with the OIDC client credentials flow
+type KafkaOAuth struct {
+	TokenEndpointURL string            `yaml:"token_endpoint_url" json:"token_endpoint_url"`
+	ClientID         string            `yaml:"client_id" json:"client_id"`
+	ClientSecret     string            `yaml:"client_secret" json:"client_secret"`
+	Scope            string            `yaml:"scope" json:"scope"`
+	Extensions       map[string]string `yaml:"extensions" json:"extensions"`
+}
+
+// KafkaKerberos configures GSSAPI with a keytab
+type KafkaKerberos struct {
+	ServiceName string `yaml:"service_name" json:"service_name"` // defaults to kafka
+	Principal   string `yaml:"principal" json:"principal"`       // user@REALM
+	Keytab      string `yaml:"keytab" json:"keytab"`
 }
 
 type KafkaMonitoring struct {
@@ -44,6 +97,81 @@ type KafkaMonitoring struct {
 	Topics         []string `yaml:"topics" json:"topics"`
 	ConsumerGroups []string `yaml:"consumer_groups" json:"consumer_groups"`
 	Metrics        []string `yaml:"metrics" json:"metrics"`
+
+	// Consumer group rebalance tracking
+	RebalanceStuckAfter  int `yaml:"rebalance_stuck_after" json:"rebalance_stuck_after"`   // seconds in PreparingRebalance or CompletingRebalance, defaults to 60
+	RebalanceStormCount  int `yaml:"rebalance_storm_count" json:"rebalance_storm_count"`   // rebalances within the window that make a storm, defaults to 5
+	RebalanceStormWindow int `yaml:"rebalance_storm_window" json:"rebalance_storm_window"` // seconds, defaults to 600
+}
+
+// KafkaCanary configures the end-to-end probe that produces to and consumes
+// from every partition of a dedicated topic
+type KafkaCanary struct {
+	Enabled           bool   `yaml:"enabled" json:"enabled"`
+	Topic             string `yaml:"topic" json:"topic"`                           // defaults to danos-canary
+	Interval          int    `yaml:"interval" json:"interval"`                     // seconds, defaults to 30
+	Timeout           int    `yaml:"timeout" json:"timeout"`                       // seconds, defaults to 10
+	ReplicationFactor int    `yaml:"replication_factor" json:"replication_factor"` // used when creating the topic, 0 uses the broker default
+}
+
+// KafkaSchemaRegistry points at a Confluent compatible schema registry used
+// to decode messages. Decoding is off when URL is empty.
+type KafkaSchemaRegistry struct {
+	URL         string   `yaml:"url" json:"url"`
+	Username    string   `yaml:"username" json:"username"` // basic auth, API key on Confluent Cloud
+	Password    string   `yaml:"password" json:"password"`
+	BearerToken string   `yaml:"bearer_token" json:"bearer_token"`
+	TLS         KafkaTLS `yaml:"tls" json:"tls"`
+	Timeout     int      `yaml:"timeout" json:"timeout"` // seconds, defaults to 5
+}
+
+// KafkaConnect points at the REST API of a Kafka Connect cluster. Requests go
+// to the first worker URL that answers. Monitoring is off when URLs is empty.
+type KafkaConnect struct {
+	URLs        []string `yaml:"urls" json:"urls"`
+	Username    string   `yaml:"username" json:"username"` // basic auth
+	Password    string   `yaml:"password" json:"password"`
+	BearerToken string   `yaml:"bearer_token" json:"bearer_token"`
+	TLS         KafkaTLS `yaml:"tls" json:"tls"`
+	Timeout     int      `yaml:"timeout" json:"timeout"` // seconds, defaults to 5
+}
+
+// KafkaReplicationFlow describes a MirrorMaker 2 flow from another configured
+// cluster into this one. Names follow the MirrorMaker 2 defaults unless set.
+type KafkaReplicationFlow struct {
+	SourceCluster    string                 `yaml:"source_cluster" json:"source_cluster"`
+	SourceAlias      string                 `yaml:"source_alias" json:"source_alias"`             // MirrorMaker alias of the source, defaults to source_cluster
+	TargetAlias      string                 `yaml:"target_alias" json:"target_alias"`             // MirrorMaker alias of this cluster, defaults to its name
+	Topics           []KafkaReplicatedTopic `yaml:"topics" json:"topics"`                         // empty means every <source_alias>.* topic of this cluster
+	ConsumerGroups   []string               `yaml:"consumer_groups" json:"consumer_groups"`       // groups whose offset checkpoints are checked
+	CheckpointsTopic string                 `yaml:"checkpoints_topic" json:"checkpoints_topic"`   // on this cluster, defaults to <source_alias>.checkpoints.internal
+	HeartbeatsTopic  string                 `yaml:"heartbeats_topic" json:"heartbeats_topic"`     // on this cluster, defaults to heartbeats
+	OffsetSyncsTopic string                 `yaml:"offset_syncs_topic" json:"offset_syncs_topic"` // on the source cluster, defaults to mm2-offset-syncs.<target_alias>.internal
+	MaxCheckpointAge int                    `yaml:"max_checkpoint_age" json:"max_checkpoint_age"` // seconds before checkpoints and heartbeats are stale, defaults to 180
+}
+
+type KafkaReplicatedTopic struct {
+	Source string `yaml:"source" json:"source"`
+	Target string `yaml:"target" json:"target"` // defaults to <source_alias>.<source>
+}
+
+// ==================== Kafka Topic Specs ====================
+
+// KafkaTopicsConfig declares the desired topics of each cluster (topics.yaml)
+type KafkaTopicsConfig struct {
+	Clusters []KafkaClusterTopics `yaml:"clusters" json:"clusters"`
+}
+
+type KafkaClusterTopics struct {
+	Cluster string           `yaml:"cluster" json:"cluster"`
+	Topics  []KafkaTopicSpec `yaml:"topics" json:"topics"`
+}
+
+type KafkaTopicSpec struct {
+	Name              string            `yaml:"name" json:"name"`
+	Partitions        int               `yaml:"partitions" json:"partitions"`
+	ReplicationFactor int               `yaml:"replication_factor" json:"replication_factor"` // 0 uses the broker default
+	Configs           map[string]string `yaml:"configs" json:"configs"`
 }
 
 // ==================== PostgreSQL Configuration ====================
@@ -0,0 +1,550 @@
+// ==================== internal/domain/kafka_admin.go ====================
+package domain
+
+import "time"
+
+// ==================== Kafka Partition Reassignment ====================
+type KafkaReassignmentRequest struct {
+	Topics       []string `json:"topics"`       // empty means every topic
+	Brokers      []int32  `json:"brokers"`      // target brokers, empty means all live brokers
+	Decommission []int32  `json:"decommission"` // brokers to move every replica off
+}
+
+type KafkaReassignmentPlan struct {
+	Brokers      []int32              `json:"brokers"`
+	Partitions   []KafkaPartitionMove `json:"partitions"` // only partitions that change
+	ReplicaMoves int                  `json:"replica_moves"`
+	LeaderMoves  int                  `json:"leader_moves"`
+	BrokerLoad   map[int32]int        `json:"broker_load"` // replicas per broker after the plan
+}
+
+type KafkaPartitionMove struct {
+	Topic           string  `json:"topic"`
+	Partition       int32   `json:"partition"`
+	CurrentReplicas []int32 `json:"current_replicas"`
+	TargetReplicas  []int32 `json:"target_replicas"`
+}
+
+type KafkaReassignmentExecuteRequest struct {
+	Plan                KafkaReassignmentPlan `json:"plan"`
+	ThrottleBytesPerSec int64                 `json:"throttle_bytes_per_sec"` // 0 disables throttling
+}
+
+type KafkaReassignmentStatus struct {
+	State               string                      `json:"state"` // running, completed, failed
+	StartedAt           time.Time                   `json:"started_at"`
+	CompletedAt         *time.Time                  `json:"completed_at,omitempty"`
+	ThrottleBytesPerSec int64                       `json:"throttle_bytes_per_sec"`
+	TotalPartitions     int                         `json:"total_partitions"`
+	DonePartitions      int                         `json:"done_partitions"`
+	Partitions          []KafkaReassignmentProgress `json:"partitions"`
+	Error               string                      `json:"error,omitempty"`
+}
+
+type KafkaReassignmentProgress struct {
+	Topic            string  `json:"topic"`
+	Partition        int32   `json:"partition"`
+	TargetReplicas   []int32 `json:"target_replicas"`
+	Replicas         []int32 `json:"replicas"`
+	AddingReplicas   []int32 `json:"adding_replicas"`
+	RemovingReplicas []int32 `json:"removing_replicas"`
+	Done             bool    `json:"done"`
+}
+
+type KafkaLeaderElectionRequest struct {
+	Topics []string `json:"topics"` // empty means every topic
+}
+
+type KafkaLeaderElectionResult struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ==================== Kafka ACLs ====================
+type KafkaACL struct {
+	ResourceType   string `json:"resource_type"` // TOPIC, GROUP, CLUSTER
+	ResourceName   string `json:"resource_name"`
+	PatternType    string `json:"pattern_type"` // LITERAL, PREFIXED
+	Principal      string `json:"principal"`    // e.g. User:alice
+	Host           string `json:"host"`         // * for any host
+	Operation      string `json:"operation"`    // READ, WRITE, DESCRIBE, ALL, ...
+	PermissionType string `json:"permission_type"`
+}
+
+// KafkaACLFilter matches ACL bindings. Empty fields match anything.
+type KafkaACLFilter struct {
+	ResourceType   string `json:"resource_type" query:"resource_type"`
+	ResourceName   string `json:"resource_name" query:"resource_name"`
+	PatternType    string `json:"pattern_type" query:"pattern_type"` // LITERAL, PREFIXED, MATCH
+	Principal      string `json:"principal" query:"principal"`
+	Host           string `json:"host" query:"host"`
+	Operation      string `json:"operation" query:"operation"`
+	PermissionType string `json:"permission_type" query:"permission"`
+}
+
+type KafkaCreateACLsRequest struct {
+	ACLs []KafkaACL `json:"acls"`
+}
+
+type KafkaEffectivePermission struct {
+	ResourceType string   `json:"resource_type"`
+	ResourceName string   `json:"resource_name"`
+	PatternType  string   `json:"pattern_type"`
+	Allowed      []string `json:"allowed"` // operations granted, including implied ones
+	Denied       []string `json:"denied"`  // operations denied, deny wins over allow
+}
+
+type KafkaPrincipalPermissions struct {
+	Principal   string                     `json:"principal"`
+	Host        string                     `json:"host"`
+	Permissions []KafkaEffectivePermission `json:"permissions"`
+}
+
+// ==================== Kafka Consumer Group Operations ====================
+
+// KafkaDeleteGroupRequest must repeat the group ID in Confirm
+type KafkaDeleteGroupRequest struct {
+	Confirm string `json:"confirm"`
+}
+
+// KafkaDeleteOffsetsRequest deletes a group's committed offsets for a topic.
+// Confirm must repeat the group ID.
+type KafkaDeleteOffsetsRequest struct {
+	Topic      string  `json:"topic"`
+	Partitions []int32 `json:"partitions"` // empty means every partition
+	Confirm    string  `json:"confirm"`
+}
+
+// ==================== Kafka Topic Plan ====================
+
+// KafkaTopicPlan is the difference between topics.yaml and a live cluster
+type KafkaTopicPlan struct {
+	Cluster            string                   `json:"cluster"`
+	Creates            []KafkaTopicSpec         `json:"creates"`
+	PartitionIncreases []KafkaPartitionIncrease `json:"partition_increases"`
+	ConfigChanges      []KafkaTopicConfigChange `json:"config_changes"`
+	Unsafe             []KafkaTopicPlanIssue    `json:"unsafe"`     // never applied
+	Undeclared         []string                 `json:"undeclared"` // live topics missing from topics.yaml, never deleted
+}
+
+type KafkaPartitionIncrease struct {
+	Topic string `json:"topic"`
+	From  int    `json:"from"`
+	To    int    `json:"to"`
+}
+
+type KafkaTopicConfigChange struct {
+	Topic string `json:"topic"`
+	Key   string `json:"key"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type KafkaTopicPlanIssue struct {
+	Topic  string `json:"topic"`
+	Reason string `json:"reason"`
+}
+
+// KafkaTopicApplyResult reports each change executed by an apply
+type KafkaTopicApplyResult struct {
+	Plan    *KafkaTopicPlan    `json:"plan"`
+	Actions []KafkaTopicAction `json:"actions"`
+}
+
+type KafkaTopicAction struct {
+	Topic  string `json:"topic"`
+	Action string `json:"action"` // create, add_partitions, alter_configs
+	Error  string `json:"error,omitempty"`
+}
+
+// ==================== Kafka Client Quotas ====================
+
+// KafkaQuotaEntity identifies who a quota applies to. DefaultUser and
+// DefaultClientID select the default entity instead of a named one.
+type KafkaQuotaEntity struct {
+	User            string `json:"user,omitempty" query:"user"`
+	DefaultUser     bool   `json:"default_user,omitempty" query:"default_user"`
+	ClientID        string `json:"client_id,omitempty" query:"client_id"`
+	DefaultClientID bool   `json:"default_client_id,omitempty" query:"default_client_id"`
+}
+
+// KafkaClientQuota holds the quotas set on an entity; unset quotas are nil
+type KafkaClientQuota struct {
+	Entity            KafkaQuotaEntity `json:"entity"`
+	ProducerByteRate  *float64         `json:"producer_byte_rate,omitempty"` // bytes/sec per broker
+	ConsumerByteRate  *float64         `json:"consumer_byte_rate,omitempty"` // bytes/sec per broker
+	RequestPercentage *float64         `json:"request_percentage,omitempty"` // of one request handler thread
+}
+
+// KafkaAlterQuotaRequest sets the non-nil quotas of an entity and removes
+// the quotas listed in Remove
+type KafkaAlterQuotaRequest struct {
+	Entity            KafkaQuotaEntity `json:"entity"`
+	ProducerByteRate  *float64         `json:"producer_byte_rate"`
+	ConsumerByteRate  *float64         `json:"consumer_byte_rate"`
+	RequestPercentage *float64         `json:"request_percentage"`
+	Remove            []string         `json:"remove"` // quota keys, e.g. producer_byte_rate
+}
+
+// ==================== Kafka Transactions ====================
+type KafkaTransaction struct {
+	TransactionalID string                 `json:"transactional_id"`
+	Coordinator     int32                  `json:"coordinator"`
+	State           string                 `json:"state"` // Empty, Ongoing, PrepareCommit, PrepareAbort, CompleteCommit, CompleteAbort, Dead, PrepareEpochFence
+	ProducerID      int64                  `json:"producer_id"`
+	ProducerEpoch   int16                  `json:"producer_epoch"`
+	TimeoutMs       int32                  `json:"timeout_ms"`
+	StartTime       *time.Time             `json:"start_time,omitempty"` // nil when no transaction is open
+	OpenSeconds     float64                `json:"open_seconds"`
+	Partitions      []KafkaTopicAssignment `json:"partitions"`
+	Error           string                 `json:"error,omitempty"`
+}
+
+type KafkaPartitionProducers struct {
+	Topic            string               `json:"topic"`
+	Partition        int32                `json:"partition"`
+	Leader           int32                `json:"leader"`
+	HighWatermark    int64                `json:"high_watermark"`
+	LastStableOffset int64                `json:"last_stable_offset"` // read_committed consumers stop here
+	Uncommitted      int64                `json:"uncommitted"`        // messages between the last stable offset and the high watermark
+	Producers        []KafkaProducerState `json:"producers"`
+	Error            string               `json:"error,omitempty"`
+}
+
+type KafkaProducerState struct {
+	ProducerID       int64     `json:"producer_id"`
+	ProducerEpoch    int16     `json:"producer_epoch"`
+	LastSequence     int32     `json:"last_sequence"`
+	LastTimestamp    time.Time `json:"last_timestamp"`
+	CoordinatorEpoch int32     `json:"coordinator_epoch"`
+	TxnStartOffset   *int64    `json:"txn_start_offset"` // nil when no transaction is open
+	TransactionalID  string    `json:"transactional_id,omitempty"`
+	BlocksLSO        bool      `json:"blocks_lso"` // its open transaction holds back the last stable offset
+	Hanging          bool      `json:"hanging"`
+	HangingReason    string    `json:"hanging_reason,omitempty"`
+}
+
+// ==================== Kafka Messages ====================
+
+// KafkaMessage is a consumed message as shown by browse, search and tail.
+// Keys and values written with a schema registry serializer are decoded to
+// JSON and carry their schema; other valid UTF-8 is sent as is and binary
+// data as base64.
+type KafkaMessage struct {
+	Topic         string              `json:"topic"`
+	Partition     int32               `json:"partition"`
+	Offset        int64               `json:"offset"`
+	Timestamp     *time.Time          `json:"timestamp,omitempty"`
+	Key           string              `json:"key"`
+	KeyEncoding   string              `json:"key_encoding"` // utf8, base64 or json (decoded with KeySchema)
+	KeySchema     *KafkaMessageSchema `json:"key_schema,omitempty"`
+	Value         string              `json:"value"`
+	ValueEncoding string              `json:"value_encoding"` // utf8, base64 or json (decoded with ValueSchema)
+	ValueSchema   *KafkaMessageSchema `json:"value_schema,omitempty"`
+	Truncated     bool                `json:"truncated"`
+	Headers       map[string]string   `json:"headers,omitempty"`
+	DecodeError   string              `json:"decode_error,omitempty"` // set when a registry payload could not be decoded
+}
+
+type KafkaMessageSchema struct {
+	ID      int    `json:"id"`
+	Type    string `json:"type"` // AVRO, PROTOBUF or JSON
+	Subject string `json:"subject,omitempty"`
+	Version int    `json:"version,omitempty"`
+}
+
+// KafkaBrowseRequest selects the messages of a topic to show. Without an
+// offset or timestamp the latest messages are returned, newest first.
+type KafkaBrowseRequest struct {
+	Partition int32 `query:"partition"` // -1 means every partition
+	Offset    int64 `query:"offset"`    // -1 means latest; otherwise read forward from here
+	Timestamp int64 `query:"timestamp"` // unix milliseconds, read forward from here when set
+	Limit     int   `query:"limit"`
+}
+
+// KafkaSearchRequest scans recent messages of a topic for keys and values
+// matching regular expressions. Decoded keys and values are matched.
+type KafkaSearchRequest struct {
+	Partitions []int32    `json:"partitions"` // empty means every partition
+	Key        string     `json:"key"`
+	Value      string     `json:"value"`
+	Since      *time.Time `json:"since"`    // defaults to the last max_scan messages of each partition
+	Until      *time.Time `json:"until"`    // defaults to the time of the request
+	MaxScan    int        `json:"max_scan"` // messages scanned per partition
+	Limit      int        `json:"limit"`    // matches returned
+}
+
+type KafkaSearchResult struct {
+	Messages []KafkaMessage `json:"messages"`
+	Scanned  int64          `json:"scanned"`
+	Partial  bool           `json:"partial"` // the scan stopped at the limit or the timeout
+}
+
+// ==================== Kafka Schema Registry ====================
+
+type KafkaSchema struct {
+	Subject    string                 `json:"subject"`
+	Version    int                    `json:"version"`
+	ID         int                    `json:"id"`
+	Type       string                 `json:"type"` // AVRO, PROTOBUF or JSON
+	Schema     string                 `json:"schema"`
+	References []KafkaSchemaReference `json:"references"`
+}
+
+type KafkaSchemaReference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+type KafkaSchemaCompatibility struct {
+	Subject   string `json:"subject,omitempty"` // empty for the global setting
+	Level     string `json:"level"`             // BACKWARD, FORWARD, FULL, NONE and their _TRANSITIVE forms
+	Inherited bool   `json:"inherited"`         // the subject has no own setting and uses the global one
+}
+
+// ==================== Kafka Topic Tail ====================
+
+// KafkaTailRequest selects what a live tail streams. Filters are regular
+// expressions matched against the decoded key and value.
+type KafkaTailRequest struct {
+	Topics      []string `json:"topics"`
+	KeyFilter   string   `json:"key_filter,omitempty" query:"key"`
+	ValueFilter string   `json:"value_filter,omitempty" query:"value"`
+	MaxPerSec   int      `json:"max_per_sec" query:"rate"` // 0 uses the default cap
+}
+
+// KafkaTailEvent is one frame of a live tail: a message, the number of
+// messages dropped by the rate cap, or the error that ended the tail
+type KafkaTailEvent struct {
+	Type    string        `json:"type"` // message, dropped, error
+	Message *KafkaMessage `json:"message,omitempty"`
+	Dropped int64         `json:"dropped,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// ==================== Kafka Jobs ====================
+
+// KafkaJob is a long running background operation such as a schema
+// inference or a replay. Result holds the outcome, or the running totals
+// while the job is running.
+type KafkaJob struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"` // schema_inference, replay
+	Cluster    string      `json:"cluster"`
+	Topic      string      `json:"topic"`
+	Status     string      `json:"status"`   // running, completed, failed, cancelled
+	Progress   float64     `json:"progress"` // 0 to 1
+	StartedAt  time.Time   `json:"started_at"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Result     interface{} `json:"result,omitempty"`
+}
+
+// ==================== Kafka Schema Inference ====================
+
+type KafkaSchemaInferenceRequest struct {
+	SamplesPerPartition int `json:"samples_per_partition"` // most recent messages read per partition
+}
+
+// KafkaInferredSchema describes the JSON values sampled from a topic. Shapes
+// are the sets of field paths and types of single messages; messages whose
+// shape differs from the most common one are listed as outliers.
+type KafkaInferredSchema struct {
+	Topic              string                 `json:"topic"`
+	Sampled            int64                  `json:"sampled"`
+	NonJSON            int64                  `json:"non_json"` // values that could not be read as JSON
+	JSONSchema         map[string]interface{} `json:"json_schema"`
+	Root               *KafkaInferredField    `json:"root"`
+	Shapes             int                    `json:"shapes"`
+	DominantShapeShare float64                `json:"dominant_shape_share"` // fraction of JSON values with the most common shape
+	Mismatches         int64                  `json:"mismatches"`
+	Outliers           []KafkaShapeOutlier    `json:"outliers"` // capped, Mismatches has the full count
+}
+
+type KafkaInferredField struct {
+	Path        string               `json:"path"`
+	Types       map[string]int64     `json:"types"` // occurrences per JSON type
+	Occurrences int64                `json:"occurrences"`
+	Presence    float64              `json:"presence"` // fraction of parent objects containing the field
+	Optional    bool                 `json:"optional"`
+	Nullable    bool                 `json:"nullable"`
+	Examples    []string             `json:"examples,omitempty"`
+	Fields      []KafkaInferredField `json:"fields,omitempty"`
+	Items       *KafkaInferredField  `json:"items,omitempty"`
+}
+
+type KafkaShapeOutlier struct {
+	Partition      int32    `json:"partition"`
+	Offset         int64    `json:"offset"`
+	Missing        []string `json:"missing,omitempty"`
+	Extra          []string `json:"extra,omitempty"`
+	TypeMismatches []string `json:"type_mismatches,omitempty"`
+}
+
+// ==================== Kafka Replay ====================
+
+// KafkaReplayRequest copies a range of a source topic to a destination topic,
+// for example to reprocess a dead-letter topic. Each partition is read from
+// the start offset or time up to the end offset or time, defaulting to its
+// first offset and to its end when the replay starts. Filters are regular
+// expressions matched against the decoded key and value; the raw bytes are
+// copied.
+type KafkaReplayRequest struct {
+	DestinationCluster string                   `json:"destination_cluster"` // defaults to the source cluster
+	DestinationTopic   string                   `json:"destination_topic"`
+	Partitions         []int32                  `json:"partitions"` // empty means every partition
+	StartOffset        *int64                   `json:"start_offset"`
+	EndOffset          *int64                   `json:"end_offset"` // exclusive
+	StartTime          *time.Time               `json:"start_time"`
+	EndTime            *time.Time               `json:"end_time"`
+	Key                string                   `json:"key"`
+	Value              string                   `json:"value"`
+	Headers            KafkaReplayHeaderRewrite `json:"headers"`
+	PreservePartitions bool                     `json:"preserve_partitions"` // otherwise partitioned by key
+	PreserveTimestamps bool                     `json:"preserve_timestamps"` // otherwise stamped when produced
+	MaxPerSec          int                      `json:"max_per_sec"`         // 0 means unlimited
+}
+
+// KafkaReplayHeaderRewrite changes the headers of copied messages. Removals
+// are applied first, then Set replaces every header of the same key.
+type KafkaReplayHeaderRewrite struct {
+	Remove    []string          `json:"remove"`
+	Set       map[string]string `json:"set"`
+	AddOrigin bool              `json:"add_origin"` // adds danos-replay-origin with the source cluster, topic, partition and offset
+}
+
+type KafkaReplayResult struct {
+	SourceCluster      string `json:"source_cluster"`
+	SourceTopic        string `json:"source_topic"`
+	DestinationCluster string `json:"destination_cluster"`
+	DestinationTopic   string `json:"destination_topic"`
+	Total              int64  `json:"total"` // offsets in the selected ranges
+	Scanned            int64  `json:"scanned"`
+	Filtered           int64  `json:"filtered"` // messages not matching the filters
+	Produced           int64  `json:"produced"`
+	Failed             int64  `json:"failed"`
+	LastError          string `json:"last_error,omitempty"`
+}
+
+// ==================== Kafka Replication ====================
+
+// KafkaReplicationStatus is the state of one MirrorMaker 2 flow into a cluster
+type KafkaReplicationStatus struct {
+	SourceCluster  string                          `json:"source_cluster"`
+	TargetCluster  string                          `json:"target_cluster"`
+	SourceAlias    string                          `json:"source_alias"`
+	Status         string                          `json:"status"` // ok, warning (stale or missing internal topics), error
+	Error          string                          `json:"error,omitempty"`
+	Topics         []KafkaReplicatedTopicLag       `json:"topics"`
+	ConsumerGroups []KafkaReplicatedGroup          `json:"consumer_groups"`
+	InternalTopics []KafkaReplicationInternalTopic `json:"internal_topics"`
+}
+
+type KafkaReplicatedTopicLag struct {
+	SourceTopic   string                        `json:"source_topic"`
+	TargetTopic   string                        `json:"target_topic"`
+	LagMessages   int64                         `json:"lag_messages"`
+	MaxLagSeconds float64                       `json:"max_lag_seconds"`
+	Partitions    []KafkaReplicatedPartitionLag `json:"partitions"`
+	Error         string                        `json:"error,omitempty"`
+}
+
+// KafkaReplicatedPartitionLag compares the ends of a source partition and its
+// replica. ReplicatedUpTo is the target end translated to a source offset
+// with the latest offset sync; without a sync offsets are assumed equal.
+type KafkaReplicatedPartitionLag struct {
+	Partition           int32   `json:"partition"`
+	SourceHighWatermark int64   `json:"source_high_watermark"`
+	TargetHighWatermark int64   `json:"target_high_watermark"`
+	ReplicatedUpTo      int64   `json:"replicated_up_to"`
+	Translated          bool    `json:"translated"`
+	LagMessages         int64   `json:"lag_messages"`
+	LagSeconds          float64 `json:"lag_seconds"` // age of the oldest message not yet replicated
+}
+
+type KafkaReplicatedGroup struct {
+	Group                string                          `json:"group"`
+	LastCheckpointAt     *time.Time                      `json:"last_checkpoint_at,omitempty"`
+	CheckpointAgeSeconds *float64                        `json:"checkpoint_age_seconds,omitempty"`
+	Stale                bool                            `json:"stale"`
+	Partitions           []KafkaReplicatedGroupPartition `json:"partitions"`
+}
+
+// KafkaReplicatedGroupPartition is the latest checkpoint of a group on one
+// partition. Committed offsets are -1 when the group has none.
+type KafkaReplicatedGroupPartition struct {
+	SourceTopic          string    `json:"source_topic"`
+	TargetTopic          string    `json:"target_topic"`
+	Partition            int32     `json:"partition"`
+	SourceCommitted      int64     `json:"source_committed"`
+	CheckpointUpstream   int64     `json:"checkpoint_upstream"`
+	CheckpointDownstream int64     `json:"checkpoint_downstream"`
+	TargetCommitted      int64     `json:"target_committed"`
+	CheckpointLag        int64     `json:"checkpoint_lag"` // source commits not yet checkpointed
+	CheckpointAt         time.Time `json:"checkpoint_at"`
+}
+
+type KafkaReplicationInternalTopic struct {
+	Role         string     `json:"role"` // checkpoints, heartbeats, offset_syncs
+	Cluster      string     `json:"cluster"`
+	Topic        string     `json:"topic"`
+	Found        bool       `json:"found"`
+	LastRecordAt *time.Time `json:"last_record_at,omitempty"`
+	AgeSeconds   *float64   `json:"age_seconds,omitempty"`
+	Stale        bool       `json:"stale"` // offset syncs are only written while data flows and are never stale
+	Error        string     `json:"error,omitempty"`
+}
+
+// ==================== Kafka Connect ====================
+
+// KafkaConnectCluster is a Kafka Connect cluster with its connectors, the
+// work assigned to each worker and the installed plugins
+type KafkaConnectCluster struct {
+	Version          string               `json:"version"`
+	Commit           string               `json:"commit"`
+	KafkaClusterID   string               `json:"kafka_cluster_id"`
+	Connectors       []KafkaConnector     `json:"connectors"`
+	Workers          []KafkaConnectWorker `json:"workers"`
+	Plugins          []KafkaConnectPlugin `json:"plugins"`
+	FailedConnectors int                  `json:"failed_connectors"`
+	FailedTasks      int                  `json:"failed_tasks"`
+}
+
+type KafkaConnector struct {
+	Name     string             `json:"name"`
+	Type     string             `json:"type"`  // source or sink
+	State    string             `json:"state"` // RUNNING, PAUSED, STOPPED, FAILED, UNASSIGNED or RESTARTING
+	WorkerID string             `json:"worker_id"`
+	Trace    string             `json:"trace,omitempty"` // stack trace of a failed connector
+	Config   map[string]string  `json:"config"`
+	Tasks    []KafkaConnectTask `json:"tasks"`
+}
+
+type KafkaConnectTask struct {
+	ID       int    `json:"id"`
+	State    string `json:"state"`
+	WorkerID string `json:"worker_id"`
+	Trace    string `json:"trace,omitempty"` // stack trace of a failed task
+}
+
+type KafkaConnectWorker struct {
+	WorkerID    string   `json:"worker_id"`
+	Connectors  []string `json:"connectors"`
+	Tasks       []string `json:"tasks"` // <connector>-<task id>
+	FailedTasks int      `json:"failed_tasks"`
+}
+
+type KafkaConnectPlugin struct {
+	Class   string `json:"class"`
+	Type    string `json:"type"` // source, sink, converter, transformation...
+	Version string `json:"version"`
+}
+
+// KafkaConnectRestartRequest selects what a connector restart covers. Without
+// include_tasks only the connector instance restarts.
+type KafkaConnectRestartRequest struct {
+	IncludeTasks bool `json:"include_tasks"`
+	OnlyFailed   bool `json:"only_failed"`
+}
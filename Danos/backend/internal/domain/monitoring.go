@@ -83,13 +83,18 @@ type RedisKeyspace struct {
 
 // ==================== Kafka Metrics ====================
 type KafkaMetrics struct {
-	BrokerID          int                    `json:"broker_id"`
-	Host              string                 `json:"host"`
-	Port              int                    `json:"port"`
-	Status            string                 `json:"status"` // online, offline, warning
-	Version           string                 `json:"version"`
+	Cluster   KafkaClusterMetrics  `json:"cluster"`
+	Brokers   []KafkaBrokerMetrics `json:"brokers"`
+	Timestamp time.Time            `json:"timestamp"`
+}
+
+type KafkaClusterMetrics struct {
 	ClusterID         string                 `json:"cluster_id"`
-	ControllerID      int                    `json:"controller_id"`
+	ControllerID      int                    `json:"controller_id"` // -1 when unknown
+	Status            string                 `json:"status"`        // online, warning
+	BrokersOnline     int                    `json:"brokers_online"`
+	BrokersOffline    int                    `json:"brokers_offline"` // brokers still holding replicas
+	BrokersFenced     int                    `json:"brokers_fenced"`  // KRaft brokers fetching metadata but not serving
 	Topics            []KafkaTopicMetrics    `json:"topics"`
 	ConsumerGroups    []KafkaConsumerMetrics `json:"consumer_groups"`
 	TotalPartitions   int                    `json:"total_partitions"`
@@ -100,39 +105,212 @@ type KafkaMetrics struct {
 	BytesInPerSec     float64                `json:"bytes_in_per_sec"`
 	BytesOutPerSec    float64                `json:"bytes_out_per_sec"`
 	MessagesInPerSec  float64                `json:"messages_in_per_sec"`
-	Timestamp         time.Time              `json:"timestamp"`
+	Canary            *KafkaCanaryMetrics    `json:"canary,omitempty"` // nil when the canary is disabled
+	Mode              string                 `json:"mode"`             // kraft, zookeeper, empty when unknown
+	Quorum            *KafkaQuorum           `json:"quorum,omitempty"` // KRaft only
 }
 
-type KafkaTopicMetrics struct {
-	Name              string  `json:"name"`
+type KafkaBrokerMetrics struct {
+	BrokerID          int                `json:"broker_id"`
+	Host              string             `json:"host"`
+	Port              int                `json:"port"`
+	Rack              string             `json:"rack,omitempty"`
+	Status            string             `json:"status"` // online, degraded (canary failing), fenced, offline
+	Version           string             `json:"version"`
+	IsController      bool               `json:"is_controller"`
+	Leaders           int                `json:"leaders"`
+	Replicas          int                `json:"replicas"`
+	OutOfSyncReplicas int                `json:"out_of_sync_replicas"`
+	LeaderSkew        float64            `json:"leader_skew"`         // percentage above/below the mean
+	BytesInPerSec     float64            `json:"bytes_in_per_sec"`    // partitions it leads, monitored topics only
+	MessagesInPerSec  float64            `json:"messages_in_per_sec"` // partitions it leads, monitored topics only
+	Canary            *KafkaCanaryBroker `json:"canary,omitempty"`    // canary partitions it leads
+	MetadataLag       *int64             `json:"metadata_lag"`        // KRaft metadata records behind, nil when unknown
+}
+
+// KafkaQuorum is the KRaft controller quorum as described by its leader, the
+// active controller. Brokers follow the metadata log as observers.
+type KafkaQuorum struct {
+	LeaderID      int32                `json:"leader_id"` // -1 when unknown
+	LeaderEpoch   int32                `json:"leader_epoch"`
+	HighWatermark int64                `json:"high_watermark"`
+	Voters        []KafkaQuorumReplica `json:"voters"`
+	Observers     []KafkaQuorumReplica `json:"observers"`
+}
+
+// KafkaQuorumReplica is a voter or observer of the metadata log. Times are the
+// leader's clock and unknown for the leader itself.
+type KafkaQuorumReplica struct {
+	ReplicaID        int32      `json:"replica_id"`
+	Role             string     `json:"role"`   // leader, follower, observer
+	Status           string     `json:"status"` // ok, lagging (not caught up recently), unreachable (not fetching)
+	LogEndOffset     int64      `json:"log_end_offset"`
+	Lag              int64      `json:"lag"` // records behind the high watermark, -1 when unknown
+	LastFetch        *time.Time `json:"last_fetch,omitempty"`
+	LastCaughtUp     *time.Time `json:"last_caught_up,omitempty"`
+	LastFetchSeconds *float64   `json:"last_fetch_seconds,omitempty"`
+}
+
+type KafkaCanaryMetrics struct {
+	Topic      string                 `json:"topic"`
+	LastRound  *time.Time             `json:"last_round,omitempty"`
+	Partitions []KafkaCanaryPartition `json:"partitions"`
+	Error      string                 `json:"error,omitempty"` // probe-wide failure, e.g. topic missing
+}
+
+type KafkaCanaryPartition struct {
+	Partition           int32      `json:"partition"`
+	Leader              int32      `json:"leader"` // at the last round
+	ProduceLatencyMs    float64    `json:"produce_latency_ms"`
+	EndToEndLatencyMs   float64    `json:"end_to_end_latency_ms"`
+	Attempts            int64      `json:"attempts"`
+	Failures            int64      `json:"failures"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+}
+
+type KafkaCanaryBroker struct {
 	Partitions        int     `json:"partitions"`
-	ReplicationFactor int     `json:"replication_factor"`
-	ISRCount          int     `json:"isr_count"` // In-Sync Replicas
-	MessagesPerSec    float64 `json:"messages_per_sec"`
-	BytesInPerSec     float64 `json:"bytes_in_per_sec"`
-	BytesOutPerSec    float64 `json:"bytes_out_per_sec"`
-	TotalSize         int64   `json:"total_size"` // bytes
-	RetentionMs       int64   `json:"retention_ms"`
-	SegmentBytes      int64   `json:"segment_bytes"`
+	Failing           int     `json:"failing"`
+	ProduceLatencyMs  float64 `json:"produce_latency_ms"`    // worst partition
+	EndToEndLatencyMs float64 `json:"end_to_end_latency_ms"` // worst partition
+}
+
+type KafkaTopicMetrics struct {
+	Name              string               `json:"name"`
+	Partitions        int                  `json:"partitions"`
+	ReplicationFactor int                  `json:"replication_factor"`
+	ISRCount          int                  `json:"isr_count"` // In-Sync Replicas of the least replicated partition
+	MessagesPerSec    float64              `json:"messages_per_sec"`
+	BytesInPerSec     float64              `json:"bytes_in_per_sec"`  // estimated from log-dir sizes
+	BytesOutPerSec    float64              `json:"bytes_out_per_sec"` // estimated from log-dir sizes
+	AvgMessageBytes   float64              `json:"avg_message_bytes"`
+	TotalSize         int64                `json:"total_size"`      // bytes, all replicas
+	RetentionMs       int64                `json:"retention_ms"`    // -1 when unlimited
+	RetentionBytes    int64                `json:"retention_bytes"` // per partition, -1 when unlimited
+	SegmentBytes      int64                `json:"segment_bytes"`
+	PartitionRates    []KafkaPartitionRate `json:"partition_rates,omitempty"`
+}
+
+type KafkaPartitionRate struct {
+	Partition      int32   `json:"partition"`
+	Leader         int32   `json:"leader"`
+	HighWatermark  int64   `json:"high_watermark"`
+	MessagesPerSec float64 `json:"messages_per_sec"`
+	BytesInPerSec  float64 `json:"bytes_in_per_sec"`
+	BytesOutPerSec float64 `json:"bytes_out_per_sec"`
+}
+
+type KafkaPartitionDetail struct {
+	Topic             string          `json:"topic"`
+	Partition         int32           `json:"partition"`
+	Leader            int32           `json:"leader"` // -1 when offline
+	PreferredLeader   int32           `json:"preferred_leader"`
+	IsPreferredLeader bool            `json:"is_preferred_leader"`
+	Replicas          []int32         `json:"replicas"`
+	ISR               []int32         `json:"isr"`
+	OfflineReplicas   []int32         `json:"offline_replicas"`
+	UnderReplicated   bool            `json:"under_replicated"`
+	Offline           bool            `json:"offline"`
+	LowWatermark      int64           `json:"low_watermark"`
+	HighWatermark     int64           `json:"high_watermark"`
+	ReplicaSizes      map[int32]int64 `json:"replica_sizes,omitempty"` // bytes, by broker
+}
+
+type KafkaBrokerPlacement struct {
+	BrokerID          int32   `json:"broker_id"`
+	Host              string  `json:"host"`
+	Online            bool    `json:"online"`
+	Leaders           int     `json:"leaders"`
+	PreferredLeaders  int     `json:"preferred_leaders"` // partitions this broker should lead
+	Replicas          int     `json:"replicas"`
+	OutOfSyncReplicas int     `json:"out_of_sync_replicas"`
+	LeaderSkew        float64 `json:"leader_skew"`  // percentage above/below the mean
+	ReplicaSkew       float64 `json:"replica_skew"` // percentage above/below the mean
+}
+
+type KafkaBrokerDisk struct {
+	BrokerID int32                 `json:"broker_id"`
+	Host     string                `json:"host"`
+	Size     int64                 `json:"size"` // bytes, all log dirs
+	Replicas int                   `json:"replicas"`
+	LogDirs  []KafkaLogDirUsage    `json:"log_dirs"`
+	Topics   []KafkaTopicDiskUsage `json:"topics"` // largest first
+	Error    string                `json:"error,omitempty"`
+}
+
+type KafkaLogDirUsage struct {
+	Dir        string `json:"dir"`
+	Size       int64  `json:"size"`        // bytes
+	FutureSize int64  `json:"future_size"` // bytes of replicas being moved into this dir
+	Replicas   int    `json:"replicas"`
+	Error      string `json:"error,omitempty"`
+}
+
+type KafkaTopicDiskUsage struct {
+	Topic    string `json:"topic"`
+	Size     int64  `json:"size"` // bytes
+	Replicas int    `json:"replicas"`
+}
+
+type KafkaTopicRetentionUsage struct {
+	Topic                string  `json:"topic"`
+	RetentionBytes       int64   `json:"retention_bytes"` // per partition
+	RetentionMs          int64   `json:"retention_ms"`
+	SegmentBytes         int64   `json:"segment_bytes"`
+	TotalSize            int64   `json:"total_size"` // bytes, all replicas
+	LargestPartition     int32   `json:"largest_partition"`
+	LargestPartitionSize int64   `json:"largest_partition_size"` // bytes, largest replica
+	UsagePercent         float64 `json:"usage_percent"`          // of retention.bytes
+}
+
+type KafkaPlacementView struct {
+	Brokers           []KafkaBrokerPlacement `json:"brokers"`
+	TotalPartitions   int                    `json:"total_partitions"`
+	UnderReplicated   int                    `json:"under_replicated_partitions"`
+	OfflinePartitions int                    `json:"offline_partitions"`
+	NonPreferred      int                    `json:"non_preferred_leaders"`
 }
 
 type KafkaConsumerMetrics struct {
-	GroupID     string           `json:"group_id"`
-	State       string           `json:"state"` // Stable, Dead, Empty, PreparingRebalance, CompletingRebalance
-	Members     int              `json:"members"`
-	Lag         int64            `json:"lag"`
-	TopicLags   []KafkaTopicLag  `json:"topic_lags"`
-	Coordinator KafkaCoordinator `json:"coordinator"`
+	GroupID            string             `json:"group_id"`
+	State              string             `json:"state"` // Stable, Dead, Empty, PreparingRebalance, CompletingRebalance
+	Members            int                `json:"members"`
+	Lag                int64              `json:"lag"`
+	LagSeconds         float64            `json:"lag_seconds"`           // worst partition
+	ConsumeRate        float64            `json:"consume_rate"`          // messages/sec
+	ProduceRate        float64            `json:"produce_rate"`          // messages/sec
+	TimeToDrainSeconds *float64           `json:"time_to_drain_seconds"` // nil when lag is not shrinking
+	TopicLags          []KafkaTopicLag    `json:"topic_lags"`
+	Coordinator        KafkaCoordinator   `json:"coordinator"`
+	Protocol           string             `json:"protocol"`              // classic or consumer
+	PartitionAssignor  string             `json:"partition_assignor"`    // e.g. range, roundrobin, cooperative-sticky
+	ConsumeBytesPerSec float64            `json:"consume_bytes_per_sec"` // estimated from log-dir sizes
+	ClientIDs          []string           `json:"client_ids"`
+	Quotas             []KafkaClientQuota `json:"quotas"` // client-id quotas that apply to the members
+
+	// Rebalance tracking across polls
+	StateSince         *time.Time `json:"state_since,omitempty"` // first poll that saw the current state
+	RebalancesInWindow int        `json:"rebalances_in_window"`  // within the configured storm window
+	RebalanceStorm     bool       `json:"rebalance_storm"`
+	RebalanceStuck     bool       `json:"rebalance_stuck"` // rebalancing for longer than the configured limit
 }
 
 type KafkaTopicLag struct {
-	Topic         string `json:"topic"`
-	Partition     int32  `json:"partition"`
-	CurrentOffset int64  `json:"current_offset"`
-	LogEndOffset  int64  `json:"log_end_offset"`
-	Lag           int64  `json:"lag"`
-	ConsumerID    string `json:"consumer_id,omitempty"`
-	Host          string `json:"host,omitempty"`
+	Topic              string   `json:"topic"`
+	Partition          int32    `json:"partition"`
+	CurrentOffset      int64    `json:"current_offset"`
+	LogStartOffset     int64    `json:"log_start_offset"`
+	LogEndOffset       int64    `json:"log_end_offset"`
+	Lag                int64    `json:"lag"`
+	LagSeconds         float64  `json:"lag_seconds"`                   // age of the oldest unconsumed message
+	OffsetOutOfRange   bool     `json:"offset_out_of_range,omitempty"` // committed offset below the log start, messages expired unconsumed
+	ConsumeRate        float64  `json:"consume_rate"`                  // messages/sec
+	ProduceRate        float64  `json:"produce_rate"`                  // messages/sec
+	TimeToDrainSeconds *float64 `json:"time_to_drain_seconds"`         // nil when lag is not shrinking
+	ConsumerID         string   `json:"consumer_id,omitempty"`
+	Host               string   `json:"host,omitempty"`
 }
 
 type KafkaCoordinator struct {
@@ -141,6 +319,46 @@ type KafkaCoordinator struct {
 	Port int    `json:"port"`
 }
 
+type KafkaConsumerGroupDetail struct {
+	GroupID           string             `json:"group_id"`
+	State             string             `json:"state"`
+	Protocol          string             `json:"protocol"` // classic or consumer
+	PartitionAssignor string             `json:"partition_assignor"`
+	Simple            bool               `json:"simple"` // offsets committed without group membership
+	Coordinator       KafkaCoordinator   `json:"coordinator"`
+	Members           []KafkaGroupMember `json:"members"`
+	Lag               int64              `json:"lag"`
+	TopicLags         []KafkaTopicLag    `json:"topic_lags"`
+}
+
+type KafkaGroupMember struct {
+	ConsumerID      string                 `json:"consumer_id"`
+	GroupInstanceID string                 `json:"group_instance_id,omitempty"` // static membership
+	ClientID        string                 `json:"client_id"`
+	Host            string                 `json:"host"`
+	Assignment      []KafkaTopicAssignment `json:"assignment"`
+}
+
+type KafkaTopicAssignment struct {
+	Topic      string  `json:"topic"`
+	Partitions []int32 `json:"partitions"`
+}
+
+// KafkaGroupEvent is a change of a consumer group seen between two polls
+type KafkaGroupEvent struct {
+	GroupID         string                 `json:"group_id"`
+	Type            string                 `json:"type"` // state_changed, member_joined, member_left, assignment_changed, rebalance_stuck, rebalance_storm
+	Time            time.Time              `json:"time"`
+	State           string                 `json:"state"`
+	PreviousState   string                 `json:"previous_state,omitempty"`
+	ConsumerID      string                 `json:"consumer_id,omitempty"`
+	GroupInstanceID string                 `json:"group_instance_id,omitempty"`
+	ClientID        string                 `json:"client_id,omitempty"`
+	Host            string                 `json:"host,omitempty"`
+	Assignment      []KafkaTopicAssignment `json:"assignment,omitempty"`
+	Rebalances      int                    `json:"rebalances,omitempty"` // rebalances within the storm window
+}
+
 // ==================== PostgreSQL Metrics ====================
 type PostgreSQLMetrics struct {
 	Name               string            `json:"name"`
@@ -161,6 +379,7 @@ type PostgreSQLMetrics struct {
 	CacheHitRatio      float64           `json:"cache_hit_ratio"` // percentage
 	TransactionsPerSec float64           `json:"transactions_per_sec"`
 	QueriesPerSec      float64           `json:"queries_per_sec"`
+	QueriesPerSecFrom  string            `json:"queries_per_sec_from,omitempty"` // pg_stat_statements, or transactions as a lower bound
 	TuplesReturned     int64             `json:"tuples_returned"`
 	TuplesFetched      int64             `json:"tuples_fetched"`
 	TuplesInserted     int64             `json:"tuples_inserted"`
@@ -184,6 +403,11 @@ type PostgreSQLMetrics struct {
 	UptimeSeconds      int64             `json:"uptime_seconds"`
 	TableCount         int               `json:"table_count"`
 	SizeBytes          int64             `json:"size_bytes"`
+
+	// Cumulative counters of the current database the rates derive from
+	Commits    int64 `json:"commits"`
+	Rollbacks  int64 `json:"rollbacks"`
+	Statements int64 `json:"statements"` // pg_stat_statements calls, 0 without the extension
 }
 
 type DatabaseInfo struct {
@@ -224,6 +448,74 @@ type IndexStatistics struct {
 	TuplesFetch int64  `json:"tuples_fetch"`
 }
 
+// PostgreSQLSession is a client session of pg_stat_activity. Ages are in
+// seconds and missing when the session has no transaction or query.
+type PostgreSQLSession struct {
+	PID              int        `json:"pid"`
+	User             string     `json:"user"`
+	Application      string     `json:"application"`
+	ClientAddress    string     `json:"client_address"` // empty for Unix sockets
+	State            string     `json:"state"`          // active, idle, idle in transaction...
+	WaitEventType    string     `json:"wait_event_type,omitempty"`
+	WaitEvent        string     `json:"wait_event,omitempty"`
+	BackendStart     time.Time  `json:"backend_start"`
+	TransactionStart *time.Time `json:"transaction_start,omitempty"`
+	TransactionAge   *float64   `json:"transaction_age,omitempty"`
+	QueryStart       *time.Time `json:"query_start,omitempty"`
+	QueryAge         *float64   `json:"query_age,omitempty"`
+	StateAge         *float64   `json:"state_age,omitempty"`
+	Query            string     `json:"query"` // current query, or the last one of idle sessions
+	QueryTruncated   bool       `json:"query_truncated"`
+}
+
+// PostgreSQLSessionFilter narrows the listed sessions. MinDuration applies
+// to the time spent in the current state.
+type PostgreSQLSessionFilter struct {
+	State       string  `query:"state"`
+	MinDuration float64 `query:"min_duration"` // seconds
+}
+
+// PostgreSQLSignalRequest confirms a cancel or terminate by repeating the PID
+type PostgreSQLSignalRequest struct {
+	Confirm int `json:"confirm"`
+}
+
+// PostgreSQLStatement is a normalized statement of pg_stat_statements with
+// its counters over a window, or since the last statistics reset. Times are
+// in milliseconds.
+type PostgreSQLStatement struct {
+	QueryID           int64   `json:"query_id,string"`
+	User              string  `json:"user"`
+	Query             string  `json:"query"`
+	Calls             int64   `json:"calls"`
+	TotalTime         float64 `json:"total_time"`
+	MeanTime          float64 `json:"mean_time"`
+	Rows              int64   `json:"rows"`
+	SharedBlocksRead  int64   `json:"shared_blocks_read"`
+	SharedBlocksHit   int64   `json:"shared_blocks_hit"`
+	TempBlocksRead    int64   `json:"temp_blocks_read"`
+	TempBlocksWritten int64   `json:"temp_blocks_written"`
+}
+
+// PostgreSQLTopStatementsRequest selects the ranking of top statements
+type PostgreSQLTopStatementsRequest struct {
+	Sort   string `query:"sort"`   // total_time (default), mean_time, calls, rows, shared_blocks_read or temp_blocks
+	Limit  int    `query:"limit"`  // defaults to 20
+	Window int    `query:"window"` // minutes, defaults to 60
+}
+
+// PostgreSQLTopStatements ranks the statements by their activity since a
+// snapshot taken about a window ago. Cumulative means no snapshot was old
+// enough and the counters run since the last statistics reset.
+type PostgreSQLTopStatements struct {
+	Database   string                `json:"database"`
+	Sort       string                `json:"sort"`
+	Since      *time.Time            `json:"since,omitempty"`
+	Until      time.Time             `json:"until"`
+	Cumulative bool                  `json:"cumulative"`
+	Statements []PostgreSQLStatement `json:"statements"`
+}
+
 // ==================== MySQL Metrics ====================
 type MySQLMetrics struct {
 	Name                 string            `json:"name"`
@@ -304,12 +596,12 @@ type MySQLTableStats struct {
 
 // ==================== Aggregated Response ====================
 type MetricsResponse struct {
-	Redis      []RedisMetrics      `json:"redis"`
-	Kafka      []KafkaMetrics      `json:"kafka"`
-	PostgreSQL []PostgreSQLMetrics `json:"postgresql"`
-	MySQL      []MySQLMetrics      `json:"mysql"`
-	Summary    MetricsSummary      `json:"summary"`
-	Timestamp  time.Time           `json:"timestamp"`
+	Redis      []RedisMetrics          `json:"redis"`
+	Kafka      map[string]KafkaMetrics `json:"kafka"` // keyed by cluster name
+	PostgreSQL []PostgreSQLMetrics     `json:"postgresql"`
+	MySQL      []MySQLMetrics          `json:"mysql"`
+	Summary    MetricsSummary          `json:"summary"`
+	Timestamp  time.Time               `json:"timestamp"`
 }
 
 type MetricsSummary struct {
@@ -25,21 +25,26 @@ redis:
 	// DefaultKafkaConfig is the default Kafka configuration template
 	DefaultKafkaConfig = `# Kafka Configuration
 kafka:
-  brokers:
-    - "localhost:9092"
-  security:
-    protocol: "PLAINTEXT"
-    sasl_mechanism: "PLAIN"
-    username: ""
-    password: ""
-  monitoring:
-    interval: 30
-    topics: []
-    consumer_groups: []
-    metrics:
-      - broker_status
-      - topic_partitions
-      - consumer_lag
+  clusters:
+    - name: "default"
+      brokers:
+        - "localhost:9092"
+      security:
+        protocol: "PLAINTEXT"
+        sasl_mechanism: "PLAIN"
+        username: ""
+        password: ""
+        tls:
+          insecure_skip_verify: false
+        properties: {}
+      monitoring:
+        interval: 30
+        topics: []
+        consumer_groups: []
+        metrics:
+          - broker_status
+          - topic_partitions
+          - consumer_lag
 `
 
 	// DefaultPostgreSQLConfig is the default PostgreSQL configuration template
@@ -16,6 +16,7 @@ type ConfigLoader struct {
 	configPath      string
 	redisConfig     *domain.RedisConfig
 	kafkaConfig     *domain.KafkaConfig
+	topicsConfig    *domain.KafkaTopicsConfig
 	postgresConfig  *domain.PostgreSQLConfig
 	mysqlConfig     *domain.MySQLConfig
 	changeCallbacks []func()
@@ -49,6 +50,9 @@ func (c *ConfigLoader) LoadAll() error {
 	if err := c.LoadKafka(); err != nil {
 		return fmt.Errorf("failed to load kafka config: %w", err)
 	}
+	if err := c.LoadTopics(); err != nil {
+		return fmt.Errorf("failed to load topics config: %w", err)
+	}
 	if err := c.LoadPostgreSQL(); err != nil {
 		return fmt.Errorf("failed to load postgresql config: %w", err)
 	}
@@ -110,6 +114,9 @@ func (c *ConfigLoader) LoadKafka() error {
 	if err := yaml.Unmarshal(data, &wrapper); err != nil {
 		return err
 	}
+	if err := normalizeKafkaConfig(&wrapper.Kafka); err != nil {
+		return err
+	}
 
 	c.mu.Lock()
 	c.kafkaConfig = &wrapper.Kafka
@@ -118,6 +125,100 @@ func (c *ConfigLoader) LoadKafka() error {
 	return nil
 }
 
+// normalizeKafkaConfig turns the single-cluster layout into a cluster named
+// "default" and checks that cluster names are set and unique
+func normalizeKafkaConfig(config *domain.KafkaConfig) error {
+	if len(config.Clusters) == 0 && len(config.Brokers) > 0 {
+		config.Clusters = []domain.KafkaClusterConfig{{
+			Name:       "default",
+			Brokers:    config.Brokers,
+			Security:   config.Security,
+			Monitoring: config.Monitoring,
+		}}
+	}
+
+	names := make(map[string]bool, len(config.Clusters))
+	for _, cluster := range config.Clusters {
+		if cluster.Name == "" {
+			return fmt.Errorf("kafka cluster name is required")
+		}
+		if names[cluster.Name] {
+			return fmt.Errorf("duplicate kafka cluster name: %s", cluster.Name)
+		}
+		names[cluster.Name] = true
+	}
+
+	return nil
+}
+
+// LoadTopics loads the declared Kafka topics, creates default if not exists
+func (c *ConfigLoader) LoadTopics() error {
+	path := filepath.Join(c.configPath, "topics.yaml")
+
+	// Check if file exists, if not create default
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := c.createDefaultTopicsConfig(path); err != nil {
+			return err
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var wrapper struct {
+		Topics domain.KafkaTopicsConfig `yaml:"topics"`
+	}
+	if err := yaml.Unmarshal(data, &wrapper); err != nil {
+		return err
+	}
+	if err := validateTopicsConfig(&wrapper.Topics); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.topicsConfig = &wrapper.Topics
+	c.mu.Unlock()
+
+	return nil
+}
+
+// validateTopicsConfig checks that every topic has a name and partitions and
+// is declared once per cluster
+func validateTopicsConfig(config *domain.KafkaTopicsConfig) error {
+	clusters := make(map[string]bool, len(config.Clusters))
+	for _, cluster := range config.Clusters {
+		if cluster.Cluster == "" {
+			return fmt.Errorf("topics cluster name is required")
+		}
+		if clusters[cluster.Cluster] {
+			return fmt.Errorf("duplicate topics cluster: %s", cluster.Cluster)
+		}
+		clusters[cluster.Cluster] = true
+
+		names := make(map[string]bool, len(cluster.Topics))
+		for _, topic := range cluster.Topics {
+			if topic.Name == "" {
+				return fmt.Errorf("topic name is required in cluster %s", cluster.Cluster)
+			}
+			if names[topic.Name] {
+				return fmt.Errorf("duplicate topic %s in cluster %s", topic.Name, cluster.Cluster)
+			}
+			names[topic.Name] = true
+
+			if topic.Partitions <= 0 {
+				return fmt.Errorf("topic %s in cluster %s needs at least one partition", topic.Name, cluster.Cluster)
+			}
+			if topic.ReplicationFactor < 0 {
+				return fmt.Errorf("topic %s in cluster %s has a negative replication factor", topic.Name, cluster.Cluster)
+			}
+		}
+	}
+
+	return nil
+}
+
 // LoadPostgreSQL loads PostgreSQL configuration, creates default if not exists
 func (c *ConfigLoader) LoadPostgreSQL() error {
 	path := filepath.Join(c.configPath, "postgresql.yaml")
@@ -217,24 +318,96 @@ redis:
 func (c *ConfigLoader) createDefaultKafkaConfig(path string) error {
 	defaultConfig := `# Kafka Configuration
 kafka:
-  brokers:
-    - "localhost:9092"
+  # Multi-cluster support; a top-level brokers list is still read as cluster "default"
+  clusters:
+    - name: "default"
+      brokers:
+        - "localhost:9092"
+
+      security:
+        protocol: "PLAINTEXT"  # PLAINTEXT, SSL, SASL_PLAINTEXT, SASL_SSL
+        sasl_mechanism: "PLAIN"  # PLAIN, SCRAM-SHA-256, SCRAM-SHA-512, OAUTHBEARER, GSSAPI
+        username: ""
+        password: ""
+        # SSL and SASL_SSL verify the broker certificates. Earlier versions skipped
+        # verification, so set ca_location for a private CA, or set
+        # insecure_skip_verify: true to keep the old behaviour.
+        tls:
+          ca_location: ""  # Empty uses the system CA bundle; ca_pem takes inline PEM
+          cert_location: ""  # Client certificate for mTLS; cert_pem takes inline PEM
+          key_location: ""  # Client key for mTLS; key_pem takes inline PEM
+          key_password: ""  # Encrypted PKCS#8 keys only; convert legacy keys with openssl pkcs8 -topk8 -v2 aes-256-cbc
+          insecure_skip_verify: false
+        # oauth:  # OAUTHBEARER, client credentials flow
+        #   token_endpoint_url: ""
+        #   client_id: ""
+        #   client_secret: ""
+        #   scope: ""
+        # kerberos:  # GSSAPI; log dirs, reassignment, quotas and transactions are unavailable
+        #   service_name: "kafka"
+        #   principal: "monitoring@EXAMPLE.COM"
+        #   keytab: "/etc/security/monitoring.keytab"
+        properties: {}  # Extra librdkafka properties, override the settings above
 
-  security:
-    protocol: "PLAINTEXT"  # PLAINTEXT, SSL, SASL_PLAINTEXT, SASL_SSL
-    sasl_mechanism: "PLAIN"  # PLAIN, SCRAM-SHA-256, SCRAM-SHA-512
-    username: ""
-    password: ""
+      monitoring:
+        interval: 30  # seconds
+        topics: []  # Empty means monitor all topics
+        consumer_groups: []  # Empty means monitor all consumer groups
+        metrics:
+          - broker_status
+          - topic_partitions
+          - consumer_lag
+          - message_rate
+        rebalance_stuck_after: 60  # seconds a group may spend rebalancing before an event
+        rebalance_storm_count: 5  # rebalances within the window that make a storm
+        rebalance_storm_window: 600  # seconds
+
+      canary:  # End-to-end probe, one message per partition of a dedicated topic
+        enabled: false
+        topic: "danos-canary"  # Created with one partition per broker
+        interval: 30  # seconds
+        timeout: 10  # seconds, a message not read back in time is a failure
+        replication_factor: 0  # 0 uses the broker default
+
+      # schema_registry:  # Decodes Avro, Protobuf and JSON Schema messages
+      #   url: "http://localhost:8081"
+      #   username: ""  # Basic auth, or an API key
+      #   password: ""
+      #   bearer_token: ""
+      #   timeout: 5  # seconds
+
+      # replication:  # MirrorMaker 2 flows into this cluster from other configured clusters
+      #   - source_cluster: "dr-source"
+      #     topics:  # Empty means every <source_alias>.* topic of this cluster
+      #       - source: "orders"  # Replica defaults to <source_alias>.orders
+      #     consumer_groups: ["orders-service"]  # Groups whose offset checkpoints are checked
+      #     max_checkpoint_age: 180  # seconds before checkpoints and heartbeats are stale
+
+      # connect:  # Kafka Connect cluster, connectors and tasks are listed and controlled
+      #   urls: ["http://localhost:8083"]  # Worker REST URLs, tried in order
+      #   username: ""  # Basic auth
+      #   password: ""
+      #   bearer_token: ""
+      #   timeout: 5  # seconds
+`
+	return os.WriteFile(path, []byte(defaultConfig), 0644)
+}
 
-  monitoring:
-    interval: 30  # seconds
-    topics: []  # Empty means monitor all topics
-    consumer_groups: []  # Empty means monitor all consumer groups
-    metrics:
-      - broker_status
-      - topic_partitions
-      - consumer_lag
-      - message_rate
+func (c *ConfigLoader) createDefaultTopicsConfig(path string) error {
+	defaultConfig := `# Kafka Topic Specs
+# Declared topics per cluster. Review changes with GET /api/v1/kafka/clusters/<cluster>/topics/plan
+# and execute creates, partition increases and config changes with POST .../topics/apply.
+topics:
+  clusters: []
+  # clusters:
+  #   - cluster: "default"
+  #     topics:
+  #       - name: "orders"
+  #         partitions: 12
+  #         replication_factor: 3  # 0 uses the broker default
+  #         configs:
+  #           retention.ms: "604800000"
+  #           cleanup.policy: "delete"
 `
 	return os.WriteFile(path, []byte(defaultConfig), 0644)
 }
@@ -263,7 +436,7 @@ postgresql:
       monitoring:
         enabled: true
         interval: 30  # seconds
-        track_activity: true
+        track_activity: true  # Enables the session viewer with cancel and terminate
         log_slow_queries: true
         slow_query_threshold: 1000  # milliseconds
 
@@ -370,6 +543,28 @@ func (c *ConfigLoader) GetKafka() *domain.KafkaConfig {
 	return c.kafkaConfig
 }
 
+func (c *ConfigLoader) GetTopics() *domain.KafkaTopicsConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.topicsConfig
+}
+
+// GetTopicSpecs returns the topics declared for a cluster
+func (c *ConfigLoader) GetTopicSpecs(cluster string) []domain.KafkaTopicSpec {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.topicsConfig == nil {
+		return nil
+	}
+	for _, clusterTopics := range c.topicsConfig.Clusters {
+		if clusterTopics.Cluster == cluster {
+			return clusterTopics.Topics
+		}
+	}
+	return nil
+}
+
 func (c *ConfigLoader) GetPostgreSQL() *domain.PostgreSQLConfig {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
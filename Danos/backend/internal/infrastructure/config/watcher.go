@@ -76,6 +76,15 @@ func (w *ConfigWatcher) handleConfigChange(filename string) {
 		}
 		log.Println("Kafka config reloaded successfully")
 
+	case "topics.yaml":
+		if err := w.configLoader.LoadTopics(); err != nil {
+			log.Printf("Failed to reload topics config: %v", err)
+			return
+		}
+		log.Println("Topics config reloaded successfully")
+		// Topic specs are only read on plan and apply, no reconnect needed
+		return
+
 	case "postgresql.yaml":
 		if err := w.configLoader.LoadPostgreSQL(); err != nil {
 			log.Printf("Failed to reload postgresql config: %v", err)
@@ -0,0 +1,386 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// ListACLs returns the ACL bindings matching filter
+func (m *ClusterManager) ListACLs(filter domain.KafkaACLFilter) ([]domain.KafkaACL, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+
+	bindingFilter, err := toACLBindingFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.describeACLs(bindingFilter)
+}
+
+// CreateACLs creates the given ACL bindings. Missing pattern types default to
+// LITERAL and missing hosts to any host.
+func (m *ClusterManager) CreateACLs(acls []domain.KafkaACL) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return fmt.Errorf("kafka admin client not connected")
+	}
+
+	bindings := make(kafka.ACLBindings, 0, len(acls))
+	for i, acl := range acls {
+		binding, err := toACLBinding(acl)
+		if err != nil {
+			return fmt.Errorf("acl %d: %w", i, err)
+		}
+		bindings = append(bindings, binding)
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+	defer cancel()
+
+	results, err := m.adminClient.CreateACLs(ctx, bindings)
+	if err != nil {
+		return fmt.Errorf("failed to create acls: %w", err)
+	}
+
+	for i, result := range results {
+		if result.Error.Code() != kafka.ErrNoError {
+			return fmt.Errorf("failed to create acl %d: %v", i, result.Error)
+		}
+	}
+
+	log.Printf("Created %d Kafka ACLs", len(bindings))
+	return nil
+}
+
+// DeleteACLs deletes every ACL binding matching filter and returns the
+// deleted bindings
+func (m *ClusterManager) DeleteACLs(filter domain.KafkaACLFilter) ([]domain.KafkaACL, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+
+	bindingFilter, err := toACLBindingFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+	defer cancel()
+
+	results, err := m.adminClient.DeleteACLs(ctx, kafka.ACLBindingFilters{bindingFilter})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete acls: %w", err)
+	}
+
+	deleted := make([]domain.KafkaACL, 0)
+	for _, result := range results {
+		if result.Error.Code() != kafka.ErrNoError {
+			return nil, fmt.Errorf("failed to delete acls: %v", result.Error)
+		}
+		for _, binding := range result.ACLBindings {
+			deleted = append(deleted, fromACLBinding(binding))
+		}
+	}
+
+	log.Printf("Deleted %d Kafka ACLs", len(deleted))
+	return deleted, nil
+}
+
+// GetEffectivePermissions resolves what principal may do from host, per
+// resource pattern. Bindings for the wildcard principal User:* count too.
+func (m *ClusterManager) GetEffectivePermissions(principal, host string) (*domain.KafkaPrincipalPermissions, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+
+	if host == "" {
+		host = "*"
+	}
+
+	principals := []string{principal}
+	if wildcard := principalType(principal) + ":*"; wildcard != principal {
+		principals = append(principals, wildcard)
+	}
+
+	acls := make([]domain.KafkaACL, 0)
+	for _, p := range principals {
+		matched, err := m.describeACLs(kafka.ACLBindingFilter{
+			Type:                kafka.ResourceAny,
+			ResourcePatternType: kafka.ResourcePatternTypeAny,
+			Principal:           p,
+			Operation:           kafka.ACLOperationAny,
+			PermissionType:      kafka.ACLPermissionTypeAny,
+		})
+		if err != nil {
+			return nil, err
+		}
+		acls = append(acls, matched...)
+	}
+
+	return &domain.KafkaPrincipalPermissions{
+		Principal:   principal,
+		Host:        host,
+		Permissions: effectivePermissions(acls, host),
+	}, nil
+}
+
+func (m *ClusterManager) describeACLs(filter kafka.ACLBindingFilter) ([]domain.KafkaACL, error) {
+	ctx, cancel := context.WithTimeout(m.ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := m.adminClient.DescribeACLs(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe acls: %w", err)
+	}
+	if result.Error.Code() != kafka.ErrNoError {
+		return nil, fmt.Errorf("failed to describe acls: %v", result.Error)
+	}
+
+	acls := make([]domain.KafkaACL, 0, len(result.ACLBindings))
+	for _, binding := range result.ACLBindings {
+		acls = append(acls, fromACLBinding(binding))
+	}
+	return acls, nil
+}
+
+// Operations implied by an ALLOW on another operation, as in Kafka's authorizer
+var impliedOperations = map[string][]string{
+	"READ":          {"DESCRIBE"},
+	"WRITE":         {"DESCRIBE"},
+	"DELETE":        {"DESCRIBE"},
+	"ALTER":         {"DESCRIBE"},
+	"ALTER_CONFIGS": {"DESCRIBE_CONFIGS"},
+}
+
+// Operations that can be granted on each resource type, expanded from ALL
+var resourceOperations = map[string][]string{
+	"TOPIC":   {"READ", "WRITE", "CREATE", "DELETE", "ALTER", "DESCRIBE", "DESCRIBE_CONFIGS", "ALTER_CONFIGS"},
+	"GROUP":   {"READ", "DELETE", "DESCRIBE"},
+	"CLUSTER": {"CREATE", "ALTER", "DESCRIBE", "CLUSTER_ACTION", "DESCRIBE_CONFIGS", "ALTER_CONFIGS", "IDEMPOTENT_WRITE"},
+}
+
+// aclResource is the resource pattern of a binding
+type aclResource struct {
+	resourceType, name, pattern string
+}
+
+// covers reports whether a pattern matches every resource of another one of
+// the same type: a literal wildcard, the same literal name, or a prefix of
+// the other name or prefix
+func (r aclResource) covers(other aclResource) bool {
+	if r.resourceType != other.resourceType {
+		return false
+	}
+	switch r.pattern {
+	case "LITERAL":
+		return r.name == "*" || (other.pattern == "LITERAL" && r.name == other.name)
+	case "PREFIXED":
+		return other.name != "*" && strings.HasPrefix(other.name, r.name)
+	}
+	return false
+}
+
+// effectivePermissions groups bindings by resource pattern and resolves
+// allowed and denied operations. Deny always wins over allow, including a
+// deny on a wildcard or prefix matching the resource.
+func effectivePermissions(acls []domain.KafkaACL, host string) []domain.KafkaEffectivePermission {
+	allowed := make(map[aclResource]map[string]bool)
+	denied := make(map[aclResource]map[string]bool)
+
+	for _, acl := range acls {
+		if acl.Host != "*" && acl.Host != host {
+			continue
+		}
+
+		key := aclResource{acl.ResourceType, acl.ResourceName, acl.PatternType}
+		operations := []string{acl.Operation}
+		if acl.Operation == "ALL" {
+			operations = append([]string(nil), resourceOperations[acl.ResourceType]...)
+		}
+
+		target := allowed
+		if acl.PermissionType == "DENY" {
+			target = denied
+		} else {
+			for _, op := range operations {
+				operations = append(operations, impliedOperations[op]...)
+			}
+		}
+
+		if target[key] == nil {
+			target[key] = make(map[string]bool)
+		}
+		for _, op := range operations {
+			target[key][op] = true
+		}
+	}
+
+	keys := make(map[aclResource]bool)
+	for key := range allowed {
+		keys[key] = true
+	}
+	for key := range denied {
+		keys[key] = true
+	}
+
+	permissions := make([]domain.KafkaEffectivePermission, 0, len(keys))
+	for key := range keys {
+		permission := domain.KafkaEffectivePermission{
+			ResourceType: key.resourceType,
+			ResourceName: key.name,
+			PatternType:  key.pattern,
+			Allowed:      make([]string, 0),
+			Denied:       make([]string, 0),
+		}
+		// Denies of every pattern covering this one apply
+		deniedOps := make(map[string]bool)
+		for denyKey, ops := range denied {
+			if denyKey.covers(key) {
+				for op := range ops {
+					deniedOps[op] = true
+				}
+			}
+		}
+		for op := range allowed[key] {
+			if !deniedOps[op] {
+				permission.Allowed = append(permission.Allowed, op)
+			}
+		}
+		for op := range deniedOps {
+			permission.Denied = append(permission.Denied, op)
+		}
+		sort.Strings(permission.Allowed)
+		sort.Strings(permission.Denied)
+		permissions = append(permissions, permission)
+	}
+
+	sort.Slice(permissions, func(i, j int) bool {
+		if permissions[i].ResourceType != permissions[j].ResourceType {
+			return permissions[i].ResourceType < permissions[j].ResourceType
+		}
+		if permissions[i].ResourceName != permissions[j].ResourceName {
+			return permissions[i].ResourceName < permissions[j].ResourceName
+		}
+		return permissions[i].PatternType < permissions[j].PatternType
+	})
+
+	return permissions
+}
+
+// principalType returns the type part of a principal such as User:alice
+func principalType(principal string) string {
+	if i := strings.Index(principal, ":"); i > 0 {
+		return principal[:i]
+	}
+	return "User"
+}
+
+func toACLBinding(acl domain.KafkaACL) (kafka.ACLBinding, error) {
+	if acl.ResourceName == "" || acl.Principal == "" {
+		return kafka.ACLBinding{}, fmt.Errorf("resource_name and principal are required")
+	}
+
+	binding, err := toACLBindingFilter(domain.KafkaACLFilter{
+		ResourceType:   acl.ResourceType,
+		ResourceName:   acl.ResourceName,
+		PatternType:    defaultString(acl.PatternType, "LITERAL"),
+		Principal:      acl.Principal,
+		Host:           defaultString(acl.Host, "*"),
+		Operation:      acl.Operation,
+		PermissionType: acl.PermissionType,
+	})
+	if err != nil {
+		return kafka.ACLBinding{}, err
+	}
+
+	// Filters accept wildcards that are not valid on a binding
+	if binding.Type == kafka.ResourceAny ||
+		(binding.ResourcePatternType != kafka.ResourcePatternTypeLiteral && binding.ResourcePatternType != kafka.ResourcePatternTypePrefixed) ||
+		binding.Operation == kafka.ACLOperationAny ||
+		binding.PermissionType == kafka.ACLPermissionTypeAny {
+		return kafka.ACLBinding{}, fmt.Errorf("resource_type, operation and permission_type are required and pattern_type must be LITERAL or PREFIXED")
+	}
+
+	return binding, nil
+}
+
+func toACLBindingFilter(filter domain.KafkaACLFilter) (kafka.ACLBindingFilter, error) {
+	resourceType := strings.ToUpper(defaultString(filter.ResourceType, "ANY"))
+	if resourceType == "CLUSTER" {
+		// librdkafka calls Kafka's cluster resource BROKER
+		resourceType = "BROKER"
+	}
+	rt, err := kafka.ResourceTypeFromString(resourceType)
+	if err != nil {
+		return kafka.ACLBindingFilter{}, fmt.Errorf("invalid resource_type %q", filter.ResourceType)
+	}
+
+	pattern, err := kafka.ResourcePatternTypeFromString(defaultString(filter.PatternType, "ANY"))
+	if err != nil {
+		return kafka.ACLBindingFilter{}, fmt.Errorf("invalid pattern_type %q", filter.PatternType)
+	}
+
+	operation, err := kafka.ACLOperationFromString(defaultString(filter.Operation, "ANY"))
+	if err != nil {
+		return kafka.ACLBindingFilter{}, fmt.Errorf("invalid operation %q", filter.Operation)
+	}
+
+	permission, err := kafka.ACLPermissionTypeFromString(defaultString(filter.PermissionType, "ANY"))
+	if err != nil {
+		return kafka.ACLBindingFilter{}, fmt.Errorf("invalid permission_type %q", filter.PermissionType)
+	}
+
+	return kafka.ACLBindingFilter{
+		Type:                rt,
+		Name:                filter.ResourceName,
+		ResourcePatternType: pattern,
+		Principal:           filter.Principal,
+		Host:                filter.Host,
+		Operation:           operation,
+		PermissionType:      permission,
+	}, nil
+}
+
+func fromACLBinding(binding kafka.ACLBinding) domain.KafkaACL {
+	resourceType := binding.Type.String()
+	if binding.Type == kafka.ResourceBroker {
+		resourceType = "CLUSTER"
+	}
+
+	return domain.KafkaACL{
+		ResourceType:   resourceType,
+		ResourceName:   binding.Name,
+		PatternType:    binding.ResourcePatternType.String(),
+		Principal:      binding.Principal,
+		Host:           binding.Host,
+		Operation:      binding.Operation.String(),
+		PermissionType: binding.PermissionType.String(),
+	}
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,122 @@
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Danos/backend/internal/domain"
+)
+
+func TestEffectivePermissions(t *testing.T) {
+	acl := func(resourceType, name, pattern, operation, permission string) domain.KafkaACL {
+		return domain.KafkaACL{
+			ResourceType:   resourceType,
+			ResourceName:   name,
+			PatternType:    pattern,
+			Principal:      "User:alice",
+			Host:           "*",
+			Operation:      operation,
+			PermissionType: permission,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		acls     []domain.KafkaACL
+		resource aclResource
+		allowed  []string
+		denied   []string
+	}{
+		{
+			name: "deny on the same literal",
+			acls: []domain.KafkaACL{
+				acl("TOPIC", "orders", "LITERAL", "READ", "ALLOW"),
+				acl("TOPIC", "orders", "LITERAL", "READ", "DENY"),
+			},
+			resource: aclResource{"TOPIC", "orders", "LITERAL"},
+			allowed:  []string{"DESCRIBE"},
+			denied:   []string{"READ"},
+		},
+		{
+			name: "deny on the literal wildcard",
+			acls: []domain.KafkaACL{
+				acl("TOPIC", "orders", "LITERAL", "WRITE", "ALLOW"),
+				acl("TOPIC", "*", "LITERAL", "WRITE", "DENY"),
+			},
+			resource: aclResource{"TOPIC", "orders", "LITERAL"},
+			allowed:  []string{"DESCRIBE"},
+			denied:   []string{"WRITE"},
+		},
+		{
+			name: "deny on a matching prefix",
+			acls: []domain.KafkaACL{
+				acl("GROUP", "payments-worker", "LITERAL", "READ", "ALLOW"),
+				acl("GROUP", "payments-", "PREFIXED", "ALL", "DENY"),
+			},
+			resource: aclResource{"GROUP", "payments-worker", "LITERAL"},
+			allowed:  []string{},
+			denied:   []string{"DELETE", "DESCRIBE", "READ"},
+		},
+		{
+			name: "deny on a shorter prefix of a prefixed allow",
+			acls: []domain.KafkaACL{
+				acl("TOPIC", "orders-eu-", "PREFIXED", "READ", "ALLOW"),
+				acl("TOPIC", "orders-", "PREFIXED", "READ", "DENY"),
+			},
+			resource: aclResource{"TOPIC", "orders-eu-", "PREFIXED"},
+			allowed:  []string{"DESCRIBE"},
+			denied:   []string{"READ"},
+		},
+		{
+			name: "deny on a prefix not matching",
+			acls: []domain.KafkaACL{
+				acl("TOPIC", "orders", "LITERAL", "READ", "ALLOW"),
+				acl("TOPIC", "payments", "PREFIXED", "READ", "DENY"),
+			},
+			resource: aclResource{"TOPIC", "orders", "LITERAL"},
+			allowed:  []string{"DESCRIBE", "READ"},
+			denied:   []string{},
+		},
+		{
+			name: "deny on a literal narrower than a prefixed allow",
+			acls: []domain.KafkaACL{
+				acl("TOPIC", "orders-", "PREFIXED", "READ", "ALLOW"),
+				acl("TOPIC", "orders-eu", "LITERAL", "READ", "DENY"),
+			},
+			resource: aclResource{"TOPIC", "orders-", "PREFIXED"},
+			allowed:  []string{"DESCRIBE", "READ"},
+			denied:   []string{},
+		},
+		{
+			name: "deny on another resource type",
+			acls: []domain.KafkaACL{
+				acl("TOPIC", "orders", "LITERAL", "READ", "ALLOW"),
+				acl("GROUP", "*", "LITERAL", "READ", "DENY"),
+			},
+			resource: aclResource{"TOPIC", "orders", "LITERAL"},
+			allowed:  []string{"DESCRIBE", "READ"},
+			denied:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var found *domain.KafkaEffectivePermission
+			permissions := effectivePermissions(tt.acls, "10.0.0.1")
+			for i, p := range permissions {
+				if p.ResourceType == tt.resource.resourceType && p.ResourceName == tt.resource.name && p.PatternType == tt.resource.pattern {
+					found = &permissions[i]
+				}
+			}
+			if found == nil {
+				t.Fatalf("no permission for %+v in %+v", tt.resource, permissions)
+			}
+			if !reflect.DeepEqual(found.Allowed, tt.allowed) {
+				t.Errorf("allowed = %v, want %v", found.Allowed, tt.allowed)
+			}
+			if !reflect.DeepEqual(found.Denied, tt.denied) {
+				t.Errorf("denied = %v, want %v", found.Denied, tt.denied)
+			}
+		})
+	}
+}
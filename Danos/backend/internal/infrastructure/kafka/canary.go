@@ -0,0 +1,429 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+const (
+	defaultCanaryTopic    = "danos-canary"
+	defaultCanaryInterval = 30 * time.Second
+	defaultCanaryTimeout  = 10 * time.Second
+)
+
+// canaryMessage is the payload produced to each canary partition
+type canaryMessage struct {
+	Cluster   string `json:"cluster"`
+	Round     int64  `json:"round"`
+	Partition int32  `json:"partition"`
+	SentAt    int64  `json:"sent_at"` // unix nanoseconds
+}
+
+// canaryProbe owns the clients of a running canary. It is only used from the
+// canary goroutine.
+type canaryProbe struct {
+	topic    string
+	timeout  time.Duration
+	producer *kafka.Producer
+	consumer *kafka.Consumer
+	assigned map[int32]bool
+	round    int64
+}
+
+// startCanary starts the canary goroutine when enabled. It stops when the
+// cluster manager is closed.
+func (m *ClusterManager) startCanary() {
+	canary := m.config.Canary
+	if !canary.Enabled {
+		return
+	}
+
+	probe := &canaryProbe{
+		topic:    defaultString(canary.Topic, defaultCanaryTopic),
+		timeout:  defaultCanaryTimeout,
+		assigned: make(map[int32]bool),
+	}
+	if canary.Timeout > 0 {
+		probe.timeout = time.Duration(canary.Timeout) * time.Second
+	}
+	interval := defaultCanaryInterval
+	if canary.Interval > 0 {
+		interval = time.Duration(canary.Interval) * time.Second
+	}
+
+	producerConfig := m.buildKafkaConfig()
+	producerConfig["acks"] = "all"
+	producerConfig["linger.ms"] = 0
+	producerConfig["message.timeout.ms"] = int(probe.timeout.Milliseconds())
+
+	consumerConfig := m.readerConfig("canary")
+
+	producer, err := kafka.NewProducer(&producerConfig)
+	if err != nil {
+		m.setCanaryError(probe.topic, fmt.Errorf("failed to create canary producer: %w", err))
+		return
+	}
+	consumer, err := kafka.NewConsumer(&consumerConfig)
+	if err != nil {
+		producer.Close()
+		m.setCanaryError(probe.topic, fmt.Errorf("failed to create canary consumer: %w", err))
+		return
+	}
+	probe.producer = producer
+	probe.consumer = consumer
+
+	m.canaryMu.Lock()
+	m.canary = &domain.KafkaCanaryMetrics{Topic: probe.topic, Partitions: make([]domain.KafkaCanaryPartition, 0)}
+	m.canaryMu.Unlock()
+
+	go m.runCanary(probe, interval)
+	log.Printf("Kafka canary started on %s/%s every %s", m.name, probe.topic, interval)
+}
+
+func (m *ClusterManager) runCanary(probe *canaryProbe, interval time.Duration) {
+	defer probe.producer.Close()
+	defer probe.consumer.Close()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.canaryRound(probe); err != nil {
+			log.Printf("Kafka canary on %s: %v", m.name, err)
+			m.setCanaryError(probe.topic, err)
+		}
+
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// canaryRound produces one message to every canary partition and waits for
+// the consumer to read them back
+func (m *ClusterManager) canaryRound(probe *canaryProbe) error {
+	topic, err := m.ensureCanaryTopic(probe)
+	if err != nil {
+		return err
+	}
+	if err := probe.assign(topic); err != nil {
+		return err
+	}
+
+	probe.round++
+	started := time.Now()
+	deadline := started.Add(probe.timeout)
+
+	results := make(map[int32]*canaryResult, len(topic.Partitions))
+	deliveries := make(chan kafka.Event, len(topic.Partitions))
+	pending := 0
+
+	for _, partition := range topic.Partitions {
+		result := &canaryResult{leader: partition.Leader}
+		results[partition.ID] = result
+		if partition.Leader < 0 {
+			result.err = "partition has no leader"
+			continue
+		}
+
+		payload, _ := json.Marshal(canaryMessage{
+			Cluster:   m.name,
+			Round:     probe.round,
+			Partition: partition.ID,
+			SentAt:    time.Now().UnixNano(),
+		})
+		result.sentAt = time.Now()
+		err := probe.producer.Produce(&kafka.Message{
+			TopicPartition: kafka.TopicPartition{Topic: &probe.topic, Partition: partition.ID},
+			Value:          payload,
+		}, deliveries)
+		if err != nil {
+			result.err = fmt.Sprintf("produce failed: %v", err)
+			continue
+		}
+		pending++
+	}
+
+	// Delivery reports give the produce latency
+	for pending > 0 && time.Now().Before(deadline) {
+		select {
+		case event := <-deliveries:
+			msg, ok := event.(*kafka.Message)
+			if !ok {
+				continue
+			}
+			pending--
+			result := results[msg.TopicPartition.Partition]
+			if msg.TopicPartition.Error != nil {
+				result.err = fmt.Sprintf("produce failed: %v", msg.TopicPartition.Error)
+				continue
+			}
+			result.produced = time.Since(result.sentAt)
+			result.delivered = true
+		case <-time.After(time.Until(deadline)):
+		}
+	}
+
+	// Reading the messages back gives the end-to-end latency
+	waiting := 0
+	for _, result := range results {
+		if result.delivered {
+			waiting++
+		}
+	}
+	for waiting > 0 && time.Now().Before(deadline) {
+		event := probe.consumer.Poll(100)
+		msg, ok := event.(*kafka.Message)
+		if !ok {
+			continue
+		}
+
+		var payload canaryMessage
+		if err := json.Unmarshal(msg.Value, &payload); err != nil || payload.Round != probe.round || payload.Cluster != m.name {
+			continue // message from an earlier round or another writer
+		}
+		result, ok := results[msg.TopicPartition.Partition]
+		if !ok || !result.delivered || result.consumed {
+			continue
+		}
+		result.endToEnd = time.Since(time.Unix(0, payload.SentAt))
+		result.consumed = true
+		waiting--
+	}
+
+	m.recordCanaryRound(probe.topic, started, results)
+	return nil
+}
+
+// canaryResult is the outcome of one round on one partition
+type canaryResult struct {
+	leader    int32
+	sentAt    time.Time
+	produced  time.Duration
+	endToEnd  time.Duration
+	delivered bool
+	consumed  bool
+	err       string
+}
+
+// ensureCanaryTopic returns the canary topic metadata. The topic is created
+// with one partition per broker, and grown when brokers are added, so that
+// every broker leads a canary partition once leadership is balanced.
+func (m *ClusterManager) ensureCanaryTopic(probe *canaryProbe) (*kafka.TopicMetadata, error) {
+	metadata, err := probe.producer.GetMetadata(&probe.topic, false, 3000)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get canary topic metadata: %w", err)
+	}
+
+	topic, exists := metadata.Topics[probe.topic]
+	if exists && topic.Error.Code() != kafka.ErrNoError && topic.Error.Code() != kafka.ErrUnknownTopicOrPart {
+		return nil, fmt.Errorf("canary topic has error: %v", topic.Error)
+	}
+	if exists && topic.Error.Code() == kafka.ErrNoError && len(topic.Partitions) >= len(metadata.Brokers) {
+		sort.Slice(topic.Partitions, func(i, j int) bool {
+			return topic.Partitions[i].ID < topic.Partitions[j].ID
+		})
+		return &topic, nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+	defer cancel()
+
+	var results []kafka.TopicResult
+	if exists && topic.Error.Code() == kafka.ErrNoError {
+		results, err = m.adminClient.CreatePartitions(ctx, []kafka.PartitionsSpecification{{
+			Topic:      probe.topic,
+			IncreaseTo: len(metadata.Brokers),
+		}})
+	} else {
+		replicationFactor := m.config.Canary.ReplicationFactor
+		if replicationFactor <= 0 {
+			replicationFactor = -1 // broker default
+		}
+		results, err = m.adminClient.CreateTopics(ctx, []kafka.TopicSpecification{{
+			Topic:             probe.topic,
+			NumPartitions:     len(metadata.Brokers),
+			ReplicationFactor: replicationFactor,
+		}})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare canary topic: %w", err)
+	}
+	for _, result := range results {
+		if code := result.Error.Code(); code != kafka.ErrNoError && code != kafka.ErrTopicAlreadyExists {
+			return nil, fmt.Errorf("failed to prepare canary topic: %v", result.Error)
+		}
+	}
+
+	log.Printf("Kafka canary topic %s on %s now has %d partitions", probe.topic, m.name, len(metadata.Brokers))
+	return nil, fmt.Errorf("canary topic %s was just prepared, probing starts next round", probe.topic)
+}
+
+// assign points the consumer at the end of every canary partition. It waits
+// until the end offsets are resolved so the next round's messages are read.
+func (p *canaryProbe) assign(topic *kafka.TopicMetadata) error {
+	changed := len(topic.Partitions) != len(p.assigned)
+	for _, partition := range topic.Partitions {
+		if !p.assigned[partition.ID] {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	partitions := make([]kafka.TopicPartition, 0, len(topic.Partitions))
+	for _, partition := range topic.Partitions {
+		partitions = append(partitions, kafka.TopicPartition{Topic: &p.topic, Partition: partition.ID, Offset: kafka.OffsetEnd})
+	}
+	if err := p.consumer.Assign(partitions); err != nil {
+		return fmt.Errorf("failed to assign canary partitions: %w", err)
+	}
+
+	deadline := time.Now().Add(p.timeout)
+	for time.Now().Before(deadline) {
+		p.consumer.Poll(100)
+		positions, err := p.consumer.Position(partitions)
+		if err != nil {
+			continue
+		}
+		resolved := true
+		for _, position := range positions {
+			if position.Offset < 0 {
+				resolved = false
+			}
+		}
+		if resolved {
+			p.assigned = make(map[int32]bool, len(partitions))
+			for _, partition := range partitions {
+				p.assigned[partition.Partition] = true
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("timed out waiting for canary partition offsets")
+}
+
+func (m *ClusterManager) recordCanaryRound(topic string, at time.Time, results map[int32]*canaryResult) {
+	m.canaryMu.Lock()
+	defer m.canaryMu.Unlock()
+
+	previous := make(map[int32]domain.KafkaCanaryPartition)
+	if m.canary != nil {
+		for _, partition := range m.canary.Partitions {
+			previous[partition.Partition] = partition
+		}
+	}
+
+	canary := &domain.KafkaCanaryMetrics{
+		Topic:      topic,
+		LastRound:  &at,
+		Partitions: make([]domain.KafkaCanaryPartition, 0, len(results)),
+	}
+	for id, result := range results {
+		partition := previous[id]
+		partition.Partition = id
+		partition.Leader = result.leader
+		partition.Attempts++
+
+		switch {
+		case result.err != "":
+			partition.LastError = result.err
+		case !result.delivered:
+			partition.LastError = "produce timed out"
+		case !result.consumed:
+			partition.LastError = "message not consumed before timeout"
+		default:
+			partition.LastError = ""
+		}
+
+		if partition.LastError != "" {
+			partition.Failures++
+			partition.ConsecutiveFailures++
+		} else {
+			now := time.Now()
+			partition.ConsecutiveFailures = 0
+			partition.LastSuccess = &now
+			partition.ProduceLatencyMs = float64(result.produced.Microseconds()) / 1000
+			partition.EndToEndLatencyMs = float64(result.endToEnd.Microseconds()) / 1000
+		}
+
+		canary.Partitions = append(canary.Partitions, partition)
+	}
+	sort.Slice(canary.Partitions, func(i, j int) bool {
+		return canary.Partitions[i].Partition < canary.Partitions[j].Partition
+	})
+
+	m.canary = canary
+}
+
+func (m *ClusterManager) setCanaryError(topic string, err error) {
+	m.canaryMu.Lock()
+	defer m.canaryMu.Unlock()
+
+	if m.canary == nil {
+		m.canary = &domain.KafkaCanaryMetrics{Topic: topic, Partitions: make([]domain.KafkaCanaryPartition, 0)}
+	}
+	m.canary.Error = err.Error()
+}
+
+// canarySnapshot returns a copy of the latest canary results, nil when the
+// canary is disabled
+func (m *ClusterManager) canarySnapshot() *domain.KafkaCanaryMetrics {
+	m.canaryMu.Lock()
+	defer m.canaryMu.Unlock()
+
+	if m.canary == nil {
+		return nil
+	}
+	snapshot := *m.canary
+	snapshot.Partitions = append([]domain.KafkaCanaryPartition(nil), m.canary.Partitions...)
+	return &snapshot
+}
+
+// canaryBrokers aggregates canary results per partition leader
+func canaryBrokers(canary *domain.KafkaCanaryMetrics) map[int32]*domain.KafkaCanaryBroker {
+	brokers := make(map[int32]*domain.KafkaCanaryBroker)
+	if canary == nil {
+		return brokers
+	}
+
+	for _, partition := range canary.Partitions {
+		if partition.Leader < 0 {
+			continue
+		}
+		broker, ok := brokers[partition.Leader]
+		if !ok {
+			broker = &domain.KafkaCanaryBroker{}
+			brokers[partition.Leader] = broker
+		}
+		broker.Partitions++
+		if partition.ConsecutiveFailures > 0 {
+			broker.Failing++
+		}
+		if partition.ProduceLatencyMs > broker.ProduceLatencyMs {
+			broker.ProduceLatencyMs = partition.ProduceLatencyMs
+		}
+		if partition.EndToEndLatencyMs > broker.EndToEndLatencyMs {
+			broker.EndToEndLatencyMs = partition.EndToEndLatencyMs
+		}
+	}
+	return brokers
+}
@@ -10,28 +10,71 @@ import (
 
 	"github.com/Danos/backend/internal/domain"
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kgo"
 )
 
-type KafkaManager struct {
+// ClusterManager holds the clients of a single named Kafka cluster
+type ClusterManager struct {
 	mu          sync.RWMutex
+	name        string
 	adminClient *kafka.AdminClient
+	extAdmin    *kadm.Client    // Admin APIs not exposed by librdkafka
+	extClient   *kgo.Client     // client of extAdmin, for requests kadm does not wrap
+	extAdminErr error           // why extAdmin could not be created, e.g. GSSAPI
+	registry    *schemaRegistry // nil when no schema registry is configured
+	connect     *connectClient  // nil when no Kafka Connect cluster is configured
 	consumer    *kafka.Consumer
-	config      *domain.KafkaConfig
+	config      *domain.KafkaClusterConfig
 	ctx         context.Context
 	cancelFunc  context.CancelFunc
 	connected   bool // Track connection state
+
+	// Offset samples kept between polls for rate calculation
+	samplesMu        sync.Mutex
+	lagSamples       map[string]offsetSample
+	watermarkSamples map[string]watermarkSample
+
+	// Serializes use of the monitoring consumer for message fetches
+	fetchMu sync.Mutex
+
+	// Guessed Kafka version per broker, looked up once per broker, and the
+	// cluster mode, looked up once per connection
+	versionsMu     sync.Mutex
+	brokerVersions map[int32]string
+	mode           string // kraft or zookeeper, empty until known
+
+	// Last partition reassignment started from this manager, and the config
+	// changes that remove its replication throttle while it runs
+	reassignMu       sync.Mutex
+	reassignment     *domain.KafkaReassignmentStatus
+	reassignThrottle []kafka.ConfigResource
+
+	// Latest end-to-end canary results, nil when the canary is disabled
+	canaryMu sync.Mutex
+	canary   *domain.KafkaCanaryMetrics
+
+	// Consumer groups as seen by the previous poll and the changes since
+	groupsMu    sync.Mutex
+	groupStates map[string]*groupState
+	groupEvents []domain.KafkaGroupEvent
 }
 
-func NewKafkaManager() *KafkaManager {
+func newClusterManager(name string) *ClusterManager {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &KafkaManager{
-		ctx:        ctx,
-		cancelFunc: cancel,
-		connected:  false,
+	return &ClusterManager{
+		name:             name,
+		ctx:              ctx,
+		cancelFunc:       cancel,
+		connected:        false,
+		lagSamples:       make(map[string]offsetSample),
+		watermarkSamples: make(map[string]watermarkSample),
+		brokerVersions:   make(map[int32]string),
+		groupStates:      make(map[string]*groupState),
 	}
 }
 
-func (m *KafkaManager) Initialize(config *domain.KafkaConfig) error {
+func (m *ClusterManager) Initialize(config *domain.KafkaClusterConfig) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -67,7 +110,8 @@ func (m *KafkaManager) Initialize(config *domain.KafkaConfig) error {
 	// Only create consumer if connection test passed
 	consumerConfig := m.buildKafkaConfig()
 	consumerConfig["group.id"] = "monitoring-consumer"
-	consumerConfig["auto.offset.reset"] = "latest"
+	// Timestamp lookups of expired positions read the oldest message left
+	consumerConfig["auto.offset.reset"] = "earliest"
 	consumerConfig["enable.auto.commit"] = false // Disable auto commit
 
 	consumer, err := kafka.NewConsumer(&consumerConfig)
@@ -76,16 +120,47 @@ func (m *KafkaManager) Initialize(config *domain.KafkaConfig) error {
 		return fmt.Errorf("failed to create kafka consumer: %w", err)
 	}
 
+	// The extended admin client is optional, features relying on it degrade
+	extAdmin, extClient, extAdminErr := newExtAdminClient(config)
+	if extAdminErr != nil {
+		log.Printf("Extended Kafka admin client unavailable: %v", extAdminErr)
+	}
+
+	// Messages are shown undecoded without a registry
+	registry, err := newSchemaRegistry(config.SchemaRegistry)
+	if err != nil {
+		log.Printf("Schema registry unavailable: %v", err)
+	}
+
+	// Connect endpoints report the missing client
+	connect, err := newConnectClient(config.Connect, nil)
+	if err != nil {
+		log.Printf("Kafka Connect unavailable: %v", err)
+	}
+
 	// Set as connected only after everything succeeds
 	m.adminClient = adminClient
+	m.extAdmin = extAdmin
+	m.extClient = extClient
+	m.extAdminErr = extAdminErr
+	m.registry = registry
+	m.connect = connect
 	m.consumer = consumer
 	m.connected = true
 
-	log.Println("Connected to Kafka cluster using Confluent client")
+	// Brokers may have been upgraded while disconnected
+	m.versionsMu.Lock()
+	m.brokerVersions = make(map[int32]string)
+	m.mode = ""
+	m.versionsMu.Unlock()
+
+	m.startCanary()
+
+	log.Printf("Connected to Kafka cluster %s using Confluent client", m.name)
 	return nil
 }
 
-func (m *KafkaManager) buildKafkaConfig() kafka.ConfigMap {
+func (m *ClusterManager) buildKafkaConfig() kafka.ConfigMap {
 	kafkaConfig := kafka.ConfigMap{
 		"bootstrap.servers": strings.Join(m.config.Brokers, ","),
 
@@ -99,11 +174,10 @@ func (m *KafkaManager) buildKafkaConfig() kafka.ConfigMap {
 		"reconnect.backoff.max.ms":           1000, // Max 1 second backoff
 
 		// Disable features that cause retries
-		"api.version.request":                 false, // Don't request API version
-		"broker.version.fallback":             "2.0.0",
-		"log.connection.close":                false, // Don't log connection closes
-		"socket.keepalive.enable":             false, // Disable keepalive
-		"enable.ssl.certificate.verification": false, // Skip SSL verification
+		"api.version.request":     true, // Required by DescribeCluster
+		"broker.version.fallback": "2.0.0",
+		"log.connection.close":    false, // Don't log connection closes
+		"socket.keepalive.enable": false, // Disable keepalive
 
 		// Reduce retries to minimum
 		"message.send.max.retries": 0,
@@ -117,38 +191,12 @@ func (m *KafkaManager) buildKafkaConfig() kafka.ConfigMap {
 	}
 
 	// Add security configuration
-	if m.config.Security.Protocol == "SASL_SSL" {
-		kafkaConfig["security.protocol"] = "SASL_SSL"
-		kafkaConfig["sasl.mechanism"] = m.config.Security.SASLMechanism
-		kafkaConfig["sasl.username"] = m.config.Security.Username
-		kafkaConfig["sasl.password"] = m.config.Security.Password
-		kafkaConfig["ssl.ca.location"] = "/etc/ssl/certs/ca-certificates.crt"
-	} else if m.config.Security.Protocol == "SASL_PLAINTEXT" {
-		kafkaConfig["security.protocol"] = "SASL_PLAINTEXT"
-		kafkaConfig["sasl.mechanism"] = m.config.Security.SASLMechanism
-		kafkaConfig["sasl.username"] = m.config.Security.Username
-		kafkaConfig["sasl.password"] = m.config.Security.Password
-	} else if m.config.Security.Protocol == "SSL" {
-		kafkaConfig["security.protocol"] = "SSL"
-		kafkaConfig["ssl.ca.location"] = "/etc/ssl/certs/ca-certificates.crt"
-	}
+	applySecurityConfig(kafkaConfig, m.config.Security)
 
 	return kafkaConfig
 }
 
-func (m *KafkaManager) Reconnect(config *domain.KafkaConfig) error {
-	// Close existing connections first
-	m.Close()
-
-	// Wait a bit for cleanup
-	time.Sleep(100 * time.Millisecond)
-
-	// Try to reconnect
-	log.Println("Reconnecting Kafka clients...")
-	return m.Initialize(config)
-}
-
-func (m *KafkaManager) GetMetrics() ([]domain.KafkaMetrics, error) {
+func (m *ClusterManager) GetMetrics() (*domain.KafkaMetrics, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -156,47 +204,104 @@ func (m *KafkaManager) GetMetrics() ([]domain.KafkaMetrics, error) {
 		return nil, fmt.Errorf("kafka client not connected")
 	}
 
-	metrics := make([]domain.KafkaMetrics, 0)
-
 	// Get cluster metadata with timeout
-	metadata, err := m.adminClient.GetMetadata(nil, false, 3000)
+	metadata, err := m.adminClient.GetMetadata(nil, true, 3000)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cluster metadata: %w", err)
 	}
 
-	// Create metrics for each broker
-	for _, broker := range metadata.Brokers {
-		metric := domain.KafkaMetrics{
-			BrokerID:  int(broker.ID),
-			Host:      broker.Host,
-			Status:    "online",
-			Timestamp: time.Now(),
-		}
+	cluster := m.describeCluster(metadata)
 
-		// Get topics metrics
-		topicMetrics, totalPartitions := m.getTopicsMetrics(metadata)
-		metric.Topics = topicMetrics
-		metric.TotalPartitions = totalPartitions
+	metrics := &domain.KafkaMetrics{
+		Cluster: domain.KafkaClusterMetrics{
+			ControllerID: -1,
+			Status:       "online",
+		},
+		Timestamp: time.Now(),
+	}
+	if cluster.ClusterID != nil {
+		metrics.Cluster.ClusterID = *cluster.ClusterID
+	}
+	if cluster.Controller != nil {
+		metrics.Cluster.ControllerID = cluster.Controller.ID
+		metrics.Cluster.ActiveControllers = 1
+	}
+
+	// Get topics metrics
+	topicMetrics, totalPartitions := m.getTopicsMetrics(metadata)
+	metrics.Cluster.Topics = topicMetrics
+	metrics.Cluster.TotalTopics = len(topicMetrics)
+	metrics.Cluster.TotalPartitions = totalPartitions
 
-		// Get consumer groups metrics
-		consumerMetrics, err := m.getConsumerGroupsMetrics()
+	// Get consumer groups metrics
+	consumerMetrics, err := m.getConsumerGroupsMetrics()
+	if err != nil {
+		log.Printf("Error getting consumer groups metrics: %v", err)
+	} else {
+		metrics.Cluster.ConsumerGroups = consumerMetrics
+	}
+
+	// Cluster-level throughput, with the quotas of each group's clients
+	applyConsumerThroughput(metrics.Cluster.Topics, metrics.Cluster.ConsumerGroups)
+	m.applyGroupQuotas(metrics.Cluster.ConsumerGroups)
+	for _, topic := range metrics.Cluster.Topics {
+		metrics.Cluster.MessagesInPerSec += topic.MessagesPerSec
+		metrics.Cluster.BytesInPerSec += topic.BytesInPerSec
+		metrics.Cluster.BytesOutPerSec += topic.BytesOutPerSec
+	}
+
+	// Under-replicated and offline partitions across all topics
+	placement := buildPlacementView(metadata)
+	metrics.Cluster.UnderReplicated = placement.UnderReplicated
+	metrics.Cluster.OfflinePartitions = placement.OfflinePartitions
+
+	// An online broker only counts as healthy when canary data flows through it
+	metrics.Cluster.Canary = m.canarySnapshot()
+
+	versions := m.getBrokerVersions(cluster.Nodes)
+	metrics.Brokers = buildBrokerMetrics(cluster, placement, metrics.Cluster.Topics, versions, canaryBrokers(metrics.Cluster.Canary))
+	degraded := false
+
+	// In KRaft mode brokers report an arbitrary broker as the controller, the
+	// active controller is the leader of the metadata quorum
+	metrics.Cluster.Mode = m.clusterMode()
+	if metrics.Cluster.Mode == "kraft" {
+		quorum, err := m.describeQuorum()
 		if err != nil {
-			log.Printf("Error getting consumer groups metrics: %v", err)
+			log.Printf("Error describing kafka quorum: %v", err)
 		} else {
-			metric.ConsumerGroups = consumerMetrics
+			metrics.Cluster.Quorum = quorum
+			metrics.Cluster.ControllerID = int(quorum.LeaderID)
+			metrics.Cluster.ActiveControllers = 0
+			if quorum.LeaderID >= 0 {
+				metrics.Cluster.ActiveControllers = 1
+			}
+			metrics.Brokers = applyQuorum(metrics.Brokers, quorum)
+			degraded = !quorumHealthy(quorum)
 		}
+	}
 
-		// Count under-replicated and offline partitions
-		metric.UnderReplicated = m.countUnderReplicatedPartitions(metadata)
-		metric.OfflinePartitions = m.countOfflinePartitions(metadata)
+	for _, broker := range metrics.Brokers {
+		switch broker.Status {
+		case "offline":
+			metrics.Cluster.BrokersOffline++
+		case "fenced":
+			metrics.Cluster.BrokersFenced++
+		default:
+			metrics.Cluster.BrokersOnline++
+		}
+		degraded = degraded || broker.Status == "degraded"
+	}
 
-		metrics = append(metrics, metric)
+	if metrics.Cluster.BrokersOffline > 0 || metrics.Cluster.BrokersFenced > 0 || metrics.Cluster.UnderReplicated > 0 ||
+		metrics.Cluster.OfflinePartitions > 0 || metrics.Cluster.ActiveControllers == 0 || degraded {
+		metrics.Cluster.Status = "warning"
 	}
 
 	return metrics, nil
 }
 
-func (m *KafkaManager) getTopicsMetrics(metadata *kafka.Metadata) ([]domain.KafkaTopicMetrics, int) {
+func (m *ClusterManager) getTopicsMetrics(metadata *kafka.Metadata) ([]domain.KafkaTopicMetrics, int) {
 	metrics := make([]domain.KafkaTopicMetrics, 0)
 	totalPartitions := 0
 
@@ -224,18 +329,33 @@ func (m *KafkaManager) getTopicsMetrics(metadata *kafka.Metadata) ([]domain.Kafk
 			Name:              topicName,
 			Partitions:        partitionCount,
 			ReplicationFactor: replicationFactor,
-			MessagesPerSec:    0,
-			BytesInPerSec:     0,
-			BytesOutPerSec:    0,
+			ISRCount:          minISR(topic),
 		}
 
 		metrics = append(metrics, metric)
 	}
 
+	// Retention settings from topic configs
+	names := make([]string, 0, len(metrics))
+	for _, metric := range metrics {
+		names = append(names, metric.Name)
+	}
+	retention := m.getTopicRetention(names)
+	for i := range metrics {
+		if config, ok := retention[metrics[i].Name]; ok {
+			metrics[i].RetentionMs = config.retentionMs
+			metrics[i].RetentionBytes = config.retentionBytes
+			metrics[i].SegmentBytes = config.segmentBytes
+		}
+	}
+
+	// Message and byte rates from watermark sampling, sizes from log dirs
+	m.applyThroughput(metrics, metadata)
+
 	return metrics, totalPartitions
 }
 
-func (m *KafkaManager) getConsumerGroupsMetrics() ([]domain.KafkaConsumerMetrics, error) {
+func (m *ClusterManager) getConsumerGroupsMetrics() ([]domain.KafkaConsumerMetrics, error) {
 	metrics := make([]domain.KafkaConsumerMetrics, 0)
 
 	// List consumer groups with short timeout
@@ -247,11 +367,17 @@ func (m *KafkaManager) getConsumerGroupsMetrics() ([]domain.KafkaConsumerMetrics
 		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
 	}
 
+	now := time.Now()
+	seen := make(map[string]bool)
+	seenSamples := make(map[string]bool)
+	descriptions := make([]*domain.KafkaConsumerGroupDetail, 0)
+
 	for _, group := range result.Valid {
 		// Only monitor configured consumer groups
 		if !m.shouldMonitorGroup(group.GroupID) {
 			continue
 		}
+		seen[group.GroupID] = true
 
 		// Describe consumer group
 		groupDesc, err := m.describeConsumerGroup(group.GroupID)
@@ -260,24 +386,60 @@ func (m *KafkaManager) getConsumerGroupsMetrics() ([]domain.KafkaConsumerMetrics
 			continue
 		}
 
-		// Get consumer lag
-		topicLags, totalLag := m.getConsumerLag(group.GroupID)
+		// Get consumer lag, its age is looked up for all groups at once below
+		topicLags, totalLag := m.getConsumerOffsetLag(group.GroupID, now)
+		for _, topicLag := range topicLags {
+			seenSamples[lagSampleKey(group.GroupID, topicLag.Topic, topicLag.Partition)] = true
+		}
+
+		assignOwners(topicLags, groupDesc.Members)
 
 		metric := domain.KafkaConsumerMetrics{
-			GroupID:   group.GroupID,
-			State:     groupDesc.State,
-			Members:   groupDesc.Members,
-			Lag:       totalLag,
-			TopicLags: topicLags,
+			GroupID:           group.GroupID,
+			State:             groupDesc.State,
+			Members:           len(groupDesc.Members),
+			Lag:               totalLag,
+			TopicLags:         topicLags,
+			Coordinator:       groupDesc.Coordinator,
+			Protocol:          groupDesc.Protocol,
+			PartitionAssignor: groupDesc.PartitionAssignor,
+			ClientIDs:         memberClientIDs(groupDesc.Members),
+			Quotas:            make([]domain.KafkaClientQuota, 0),
 		}
 
+		for _, topicLag := range topicLags {
+			metric.ConsumeRate += topicLag.ConsumeRate
+			metric.ProduceRate += topicLag.ProduceRate
+		}
+		metric.TimeToDrainSeconds = timeToDrain(totalLag, metric.ConsumeRate, metric.ProduceRate)
+
 		metrics = append(metrics, metric)
+		descriptions = append(descriptions, groupDesc)
+	}
+
+	// Lag in seconds is the age of the oldest unconsumed message, read for
+	// every group in a single fetch
+	positions := make([]kafka.TopicPartition, 0)
+	for i := range metrics {
+		positions = append(positions, lagPositions(metrics[i].TopicLags)...)
+	}
+	timestamps := m.fetchTimestamps(positions, 2*time.Second)
+
+	for i := range metrics {
+		applyLagSeconds(metrics[i].TopicLags, timestamps, now)
+		for _, topicLag := range metrics[i].TopicLags {
+			metrics[i].LagSeconds = max(metrics[i].LagSeconds, topicLag.LagSeconds)
+		}
+		m.trackGroup(descriptions[i], &metrics[i], now)
 	}
 
+	m.forgetGroups(seen)
+	m.forgetLagSamples(seenSamples)
+
 	return metrics, nil
 }
 
-func (m *KafkaManager) describeConsumerGroup(groupID string) (*ConsumerGroupDescription, error) {
+func (m *ClusterManager) describeConsumerGroup(groupID string) (*domain.KafkaConsumerGroupDetail, error) {
 	ctx, cancel := context.WithTimeout(m.ctx, 3*time.Second)
 	defer cancel()
 
@@ -291,21 +453,48 @@ func (m *KafkaManager) describeConsumerGroup(groupID string) (*ConsumerGroupDesc
 	}
 
 	desc := res.ConsumerGroupDescriptions[0]
+	if desc.Error.Code() != kafka.ErrNoError {
+		return nil, fmt.Errorf("consumer group %s has error: %v", groupID, desc.Error)
+	}
+
+	detail := &domain.KafkaConsumerGroupDetail{
+		GroupID:           desc.GroupID,
+		State:             desc.State.String(),
+		Protocol:          strings.ToLower(desc.Type.String()),
+		PartitionAssignor: desc.PartitionAssignor,
+		Simple:            desc.IsSimpleConsumerGroup,
+		Coordinator: domain.KafkaCoordinator{
+			ID:   desc.Coordinator.ID,
+			Host: desc.Coordinator.Host,
+			Port: desc.Coordinator.Port,
+		},
+		Members: make([]domain.KafkaGroupMember, 0, len(desc.Members)),
+	}
+
+	for _, member := range desc.Members {
+		detail.Members = append(detail.Members, domain.KafkaGroupMember{
+			ConsumerID:      member.ConsumerID,
+			GroupInstanceID: member.GroupInstanceID,
+			ClientID:        member.ClientID,
+			Host:            member.Host,
+			Assignment:      topicAssignments(member.Assignment.TopicPartitions),
+		})
+	}
 
-	return &ConsumerGroupDescription{
-		GroupID: desc.GroupID,
-		State:   desc.State.String(),
-		Members: len(desc.Members),
-	}, nil
+	return detail, nil
 }
 
-type ConsumerGroupDescription struct {
-	GroupID string
-	State   string
-	Members int
+// getConsumerLag returns the lag of a group, with the age of its oldest
+// unconsumed messages
+func (m *ClusterManager) getConsumerLag(groupID string) ([]domain.KafkaTopicLag, int64) {
+	now := time.Now()
+	lags, totalLag := m.getConsumerOffsetLag(groupID, now)
+	applyLagSeconds(lags, m.fetchTimestamps(lagPositions(lags), 2*time.Second), now)
+	return lags, totalLag
 }
 
-func (m *KafkaManager) getConsumerLag(groupID string) ([]domain.KafkaTopicLag, int64) {
+// getConsumerOffsetLag returns the lag of a group in messages
+func (m *ClusterManager) getConsumerOffsetLag(groupID string, now time.Time) ([]domain.KafkaTopicLag, int64) {
 	lags := make([]domain.KafkaTopicLag, 0)
 	var totalLag int64
 
@@ -348,18 +537,25 @@ func (m *KafkaManager) getConsumerLag(groupID string) ([]domain.KafkaTopicLag, i
 			continue
 		}
 
-		_ = low
-
 		committedOffset := int64(partition.Offset)
 		lag := high - committedOffset
 		if lag < 0 {
 			lag = 0
 		}
 
+		consumeRate, produceRate := m.updateLagRates(groupID, *partition.Topic, partition.Partition, committedOffset, high, now)
+
 		topicLag := domain.KafkaTopicLag{
-			Topic:     *partition.Topic,
-			Partition: partition.Partition,
-			Lag:       lag,
+			Topic:              *partition.Topic,
+			Partition:          partition.Partition,
+			CurrentOffset:      committedOffset,
+			LogStartOffset:     low,
+			LogEndOffset:       high,
+			OffsetOutOfRange:   committedOffset >= 0 && committedOffset < low,
+			Lag:                lag,
+			ConsumeRate:        consumeRate,
+			ProduceRate:        produceRate,
+			TimeToDrainSeconds: timeToDrain(lag, consumeRate, produceRate),
 		}
 
 		lags = append(lags, topicLag)
@@ -369,50 +565,25 @@ func (m *KafkaManager) getConsumerLag(groupID string) ([]domain.KafkaTopicLag, i
 	return lags, totalLag
 }
 
-func (m *KafkaManager) countUnderReplicatedPartitions(metadata *kafka.Metadata) int {
-	count := 0
-	for _, topic := range metadata.Topics {
-		for _, partition := range topic.Partitions {
-			if len(partition.Replicas) > len(partition.Isrs) {
-				count++
-			}
-		}
-	}
-	return count
-}
-
-func (m *KafkaManager) countOfflinePartitions(metadata *kafka.Metadata) int {
-	count := 0
-	for _, topic := range metadata.Topics {
-		for _, partition := range topic.Partitions {
-			if partition.Leader == -1 {
-				count++
-			}
+func (m *ClusterManager) shouldMonitorTopic(topic string) bool {
+	configured := false
+	for _, t := range m.config.Monitoring.Topics {
+		if t == topic {
+			configured = true
+			break
 		}
 	}
-	return count
-}
 
-func (m *KafkaManager) shouldMonitorTopic(topic string) bool {
-	// Skip internal topics
+	// Internal topics are only monitored when listed explicitly
 	if strings.HasPrefix(topic, "__") {
-		return false
+		return configured
 	}
 
 	// If no topics configured, monitor all
-	if len(m.config.Monitoring.Topics) == 0 {
-		return true
-	}
-
-	for _, t := range m.config.Monitoring.Topics {
-		if t == topic {
-			return true
-		}
-	}
-	return false
+	return len(m.config.Monitoring.Topics) == 0 || configured
 }
 
-func (m *KafkaManager) shouldMonitorGroup(group string) bool {
+func (m *ClusterManager) shouldMonitorGroup(group string) bool {
 	// If no groups configured, monitor all
 	if len(m.config.Monitoring.ConsumerGroups) == 0 {
 		return true
@@ -426,13 +597,33 @@ func (m *KafkaManager) shouldMonitorGroup(group string) bool {
 	return false
 }
 
-func (m *KafkaManager) IsConnected() bool {
+func (m *ClusterManager) IsConnected() bool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	return m.connected && m.adminClient != nil
 }
 
-func (m *KafkaManager) GetConnectionInfo() []string {
+// ExtendedAdminError reports why the extended admin client is unavailable on
+// a connected cluster, nil when it is available
+func (m *ClusterManager) ExtendedAdminError() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if !m.connected || m.extAdmin != nil {
+		return nil
+	}
+	return m.extAdminErr
+}
+
+// extAdminUnavailable is the error of a feature that needs the extended admin
+// client. mu must be held.
+func (m *ClusterManager) extAdminUnavailable(feature string) error {
+	if m.extAdminErr != nil {
+		return fmt.Errorf("%s requires the extended kafka admin client, which is unavailable: %w", feature, m.extAdminErr)
+	}
+	return fmt.Errorf("%s requires the extended kafka admin client", feature)
+}
+
+func (m *ClusterManager) GetConnectionInfo() []string {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -442,12 +633,17 @@ func (m *KafkaManager) GetConnectionInfo() []string {
 	return m.config.Brokers
 }
 
-func (m *KafkaManager) Close() error {
+func (m *ClusterManager) Close() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	m.connected = false
 
+	// Brokers must not stay throttled for a reassignment nobody tracks
+	if m.adminClient != nil {
+		m.releaseReplicationThrottle(context.Background())
+	}
+
 	if m.cancelFunc != nil {
 		m.cancelFunc()
 	}
@@ -458,6 +654,13 @@ func (m *KafkaManager) Close() error {
 		log.Println("Kafka admin client closed")
 	}
 
+	if m.extAdmin != nil {
+		m.extAdmin.Close()
+		m.extAdmin = nil
+		m.extClient = nil
+	}
+	m.extAdminErr = nil
+
 	if m.consumer != nil {
 		if err := m.consumer.Close(); err != nil {
 			log.Printf("Error closing Kafka consumer: %v", err)
@@ -470,7 +673,7 @@ func (m *KafkaManager) Close() error {
 }
 
 // GetClusterID returns the Kafka cluster ID
-func (m *KafkaManager) GetClusterID() (string, error) {
+func (m *ClusterManager) GetClusterID() (string, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -478,16 +681,22 @@ func (m *KafkaManager) GetClusterID() (string, error) {
 		return "", fmt.Errorf("kafka admin client not connected")
 	}
 
-	metadata, err := m.adminClient.GetMetadata(nil, false, 3000)
+	ctx, cancel := context.WithTimeout(m.ctx, 3*time.Second)
+	defer cancel()
+
+	result, err := m.adminClient.DescribeCluster(ctx)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to describe cluster: %w", err)
+	}
+	if result.ClusterID == nil {
+		return "", fmt.Errorf("cluster ID not available")
 	}
 
-	return metadata.OriginatingBroker.Host, nil
+	return *result.ClusterID, nil
 }
 
 // CreateTopic creates a new Kafka topic
-func (m *KafkaManager) CreateTopic(topicName string, numPartitions int, replicationFactor int) error {
+func (m *ClusterManager) CreateTopic(topicName string, numPartitions int, replicationFactor int) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -523,7 +732,7 @@ func (m *KafkaManager) CreateTopic(topicName string, numPartitions int, replicat
 }
 
 // DeleteTopic deletes a Kafka topic
-func (m *KafkaManager) DeleteTopic(topicName string) error {
+func (m *ClusterManager) DeleteTopic(topicName string) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -553,7 +762,7 @@ func (m *KafkaManager) DeleteTopic(topicName string) error {
 }
 
 // GetTopicConfig retrieves configuration for a specific topic
-func (m *KafkaManager) GetTopicConfig(topicName string) (map[string]string, error) {
+func (m *ClusterManager) GetTopicConfig(topicName string) (map[string]string, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -0,0 +1,143 @@
+package kafka
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+// describeCluster returns the cluster ID, controller and live brokers. When
+// the broker does not support DescribeCluster the brokers come from metadata
+// and the cluster ID and controller are left empty.
+func (m *ClusterManager) describeCluster(metadata *kafka.Metadata) kafka.DescribeClusterResult {
+	ctx, cancel := context.WithTimeout(m.ctx, 3*time.Second)
+	defer cancel()
+
+	result, err := m.adminClient.DescribeCluster(ctx)
+	if err == nil {
+		return result
+	}
+	log.Printf("Error describing kafka cluster: %v", err)
+
+	result = kafka.DescribeClusterResult{Nodes: make([]kafka.Node, 0, len(metadata.Brokers))}
+	for _, broker := range metadata.Brokers {
+		result.Nodes = append(result.Nodes, kafka.Node{
+			ID:   int(broker.ID),
+			Host: broker.Host,
+			Port: broker.Port,
+		})
+	}
+	return result
+}
+
+// getBrokerVersions returns the guessed Kafka version of each broker. Versions
+// are cached and only looked up for brokers not seen before.
+func (m *ClusterManager) getBrokerVersions(nodes []kafka.Node) map[int32]string {
+	m.versionsMu.Lock()
+	defer m.versionsMu.Unlock()
+
+	missing := false
+	for _, node := range nodes {
+		if _, ok := m.brokerVersions[int32(node.ID)]; !ok {
+			missing = true
+			break
+		}
+	}
+
+	if missing && m.extAdmin != nil {
+		ctx, cancel := context.WithTimeout(m.ctx, 3*time.Second)
+		defer cancel()
+
+		versions, err := m.extAdmin.ApiVersions(ctx)
+		if err != nil {
+			log.Printf("Error getting broker api versions: %v", err)
+		}
+		versions.Each(func(v kadm.BrokerApiVersions) {
+			if v.Err == nil {
+				m.brokerVersions[v.NodeID] = v.VersionGuess()
+			}
+		})
+	}
+
+	versions := make(map[int32]string, len(m.brokerVersions))
+	for id, version := range m.brokerVersions {
+		versions[id] = version
+	}
+	return versions
+}
+
+// buildBrokerMetrics combines live brokers, replica placement and the leader
+// throughput of monitored topics into one entry per broker. Brokers that are
+// down but still hold replicas are listed as offline, brokers leading failing
+// canary partitions as degraded.
+func buildBrokerMetrics(cluster kafka.DescribeClusterResult, placement *domain.KafkaPlacementView, topics []domain.KafkaTopicMetrics, versions map[int32]string, canary map[int32]*domain.KafkaCanaryBroker) []domain.KafkaBrokerMetrics {
+	controllerID := -1
+	if cluster.Controller != nil {
+		controllerID = cluster.Controller.ID
+	}
+
+	brokers := make(map[int32]*domain.KafkaBrokerMetrics, len(cluster.Nodes))
+	for _, node := range cluster.Nodes {
+		broker := &domain.KafkaBrokerMetrics{
+			BrokerID:     node.ID,
+			Host:         node.Host,
+			Port:         node.Port,
+			Status:       "online",
+			Version:      versions[int32(node.ID)],
+			IsController: node.ID == controllerID,
+		}
+		if node.Rack != nil {
+			broker.Rack = *node.Rack
+		}
+		brokers[int32(node.ID)] = broker
+	}
+
+	for _, p := range placement.Brokers {
+		broker, ok := brokers[p.BrokerID]
+		if !ok {
+			broker = &domain.KafkaBrokerMetrics{
+				BrokerID: int(p.BrokerID),
+				Host:     p.Host,
+				Status:   "offline",
+			}
+			brokers[p.BrokerID] = broker
+		}
+		broker.Leaders = p.Leaders
+		broker.Replicas = p.Replicas
+		broker.OutOfSyncReplicas = p.OutOfSyncReplicas
+		broker.LeaderSkew = p.LeaderSkew
+	}
+
+	for _, topic := range topics {
+		for _, rate := range topic.PartitionRates {
+			if broker, ok := brokers[rate.Leader]; ok {
+				broker.MessagesInPerSec += rate.MessagesPerSec
+				broker.BytesInPerSec += rate.BytesInPerSec
+			}
+		}
+	}
+
+	for id, result := range canary {
+		if broker, ok := brokers[id]; ok {
+			broker.Canary = result
+			if result.Failing > 0 && broker.Status == "online" {
+				broker.Status = "degraded"
+			}
+		}
+	}
+
+	metrics := make([]domain.KafkaBrokerMetrics, 0, len(brokers))
+	for _, broker := range brokers {
+		metrics = append(metrics, *broker)
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].BrokerID < metrics[j].BrokerID
+	})
+
+	return metrics
+}
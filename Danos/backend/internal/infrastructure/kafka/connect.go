@@ -0,0 +1,398 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+)
+
+// connectClient is a client for the Kafka Connect REST API. Every worker
+// serves the whole cluster and forwards to the leader when needed, so a
+// request goes to the first worker that answers.
+type connectClient struct {
+	urls        []string
+	username    string
+	password    string
+	bearerToken string
+	client      *http.Client
+}
+
+// connectError is the error body of the Connect REST API
+type connectError struct {
+	Code    int    `json:"error_code"`
+	Message string `json:"message"`
+	Status  int    `json:"-"`
+}
+
+func (e *connectError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("kafka connect returned status %d", e.Status)
+	}
+	return fmt.Sprintf("kafka connect error %d: %s", e.Code, e.Message)
+}
+
+// connectorInfo and connectorStatus are the two halves of a connector as
+// returned by the Connect REST API
+type connectorInfo struct {
+	Name   string            `json:"name"`
+	Type   string            `json:"type"`
+	Config map[string]string `json:"config"`
+}
+
+type connectorStatus struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Connector struct {
+		State    string `json:"state"`
+		WorkerID string `json:"worker_id"`
+		Trace    string `json:"trace"`
+	} `json:"connector"`
+	Tasks []domain.KafkaConnectTask `json:"tasks"`
+}
+
+// newConnectClient creates a Connect client, or returns nil when no Connect
+// cluster is configured. A nil httpClient builds one from the config, with
+// its TLS settings applied to https URLs.
+func newConnectClient(config domain.KafkaConnect, httpClient *http.Client) (*connectClient, error) {
+	if len(config.URLs) == 0 {
+		return nil, nil
+	}
+
+	urls := make([]string, 0, len(config.URLs))
+	for _, u := range config.URLs {
+		if u = strings.TrimRight(strings.TrimSpace(u), "/"); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("kafka connect urls are empty")
+	}
+
+	if httpClient == nil {
+		timeout := 5 * time.Second
+		if config.Timeout > 0 {
+			timeout = time.Duration(config.Timeout) * time.Second
+		}
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		for _, u := range urls {
+			if strings.HasPrefix(strings.ToLower(u), "https://") {
+				tlsConfig, err := buildTLSConfig(config.TLS)
+				if err != nil {
+					return nil, fmt.Errorf("invalid kafka connect TLS config: %w", err)
+				}
+				transport.TLSClientConfig = tlsConfig
+				break
+			}
+		}
+		httpClient = &http.Client{Timeout: timeout, Transport: transport}
+	}
+
+	return &connectClient{
+		urls:        urls,
+		username:    config.Username,
+		password:    config.Password,
+		bearerToken: config.BearerToken,
+		client:      httpClient,
+	}, nil
+}
+
+// do calls a Connect endpoint on the first worker that answers and decodes
+// the JSON response into out, when out is not nil and there is a body.
+// Workers that cannot be reached are skipped, error responses are not.
+func (c *connectClient) do(method, path string, query url.Values, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	var lastErr error
+	for _, base := range c.urls {
+		endpoint := base + path
+		if len(query) > 0 {
+			endpoint += "?" + query.Encode()
+		}
+
+		req, err := http.NewRequest(method, endpoint, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		switch {
+		case c.bearerToken != "":
+			req.Header.Set("Authorization", "Bearer "+c.bearerToken)
+		case c.username != "":
+			req.SetBasicAuth(c.username, c.password)
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read kafka connect response: %w", err)
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			connErr := &connectError{Status: resp.StatusCode}
+			_ = json.Unmarshal(data, connErr)
+			return connErr
+		}
+
+		if out == nil || len(bytes.TrimSpace(data)) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("invalid kafka connect response: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("kafka connect request failed: %w", lastErr)
+}
+
+// connectorPath returns the path of a connector endpoint
+func connectorPath(name string, parts ...string) string {
+	path := "/connectors/" + url.PathEscape(name)
+	for _, part := range parts {
+		path += "/" + part
+	}
+	return path
+}
+
+// GetConnect describes the Connect cluster: connectors with their tasks and
+// failure traces, the work assigned to every worker and the installed plugins
+func (m *ClusterManager) GetConnect() (*domain.KafkaConnectCluster, error) {
+	if m.connect == nil {
+		return nil, fmt.Errorf("no kafka connect cluster configured for cluster %s", m.name)
+	}
+
+	var root struct {
+		Version        string `json:"version"`
+		Commit         string `json:"commit"`
+		KafkaClusterID string `json:"kafka_cluster_id"`
+	}
+	if err := m.connect.do(http.MethodGet, "/", nil, nil, &root); err != nil {
+		return nil, err
+	}
+
+	var expanded map[string]struct {
+		Info   connectorInfo   `json:"info"`
+		Status connectorStatus `json:"status"`
+	}
+	query := url.Values{"expand": {"info", "status"}}
+	if err := m.connect.do(http.MethodGet, "/connectors", query, nil, &expanded); err != nil {
+		return nil, err
+	}
+
+	// connectorsOnly=false also lists converters and transformations on
+	// Connect 3.2 and later, older workers ignore it
+	plugins := make([]domain.KafkaConnectPlugin, 0)
+	query = url.Values{"connectorsOnly": {"false"}}
+	if err := m.connect.do(http.MethodGet, "/connector-plugins", query, nil, &plugins); err != nil {
+		return nil, err
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Class < plugins[j].Class })
+
+	cluster := &domain.KafkaConnectCluster{
+		Version:        root.Version,
+		Commit:         root.Commit,
+		KafkaClusterID: root.KafkaClusterID,
+		Connectors:     make([]domain.KafkaConnector, 0, len(expanded)),
+		Plugins:        plugins,
+	}
+	for _, connector := range expanded {
+		cluster.Connectors = append(cluster.Connectors, buildConnector(connector.Info, connector.Status))
+	}
+	sort.Slice(cluster.Connectors, func(i, j int) bool { return cluster.Connectors[i].Name < cluster.Connectors[j].Name })
+
+	for _, connector := range cluster.Connectors {
+		if connector.State == "FAILED" {
+			cluster.FailedConnectors++
+		}
+		for _, task := range connector.Tasks {
+			if task.State == "FAILED" {
+				cluster.FailedTasks++
+			}
+		}
+	}
+	cluster.Workers = connectWorkers(cluster.Connectors)
+
+	return cluster, nil
+}
+
+// GetConnector returns a connector with its config and tasks
+func (m *ClusterManager) GetConnector(name string) (*domain.KafkaConnector, error) {
+	if m.connect == nil {
+		return nil, fmt.Errorf("no kafka connect cluster configured for cluster %s", m.name)
+	}
+
+	var info connectorInfo
+	if err := m.connect.do(http.MethodGet, connectorPath(name), nil, nil, &info); err != nil {
+		return nil, err
+	}
+	var status connectorStatus
+	if err := m.connect.do(http.MethodGet, connectorPath(name, "status"), nil, nil, &status); err != nil {
+		return nil, err
+	}
+
+	connector := buildConnector(info, status)
+	return &connector, nil
+}
+
+// PauseConnector suspends a connector and its tasks
+func (m *ClusterManager) PauseConnector(name string) error {
+	if m.connect == nil {
+		return fmt.Errorf("no kafka connect cluster configured for cluster %s", m.name)
+	}
+	return m.connect.do(http.MethodPut, connectorPath(name, "pause"), nil, nil, nil)
+}
+
+// ResumeConnector resumes a paused connector
+func (m *ClusterManager) ResumeConnector(name string) error {
+	if m.connect == nil {
+		return fmt.Errorf("no kafka connect cluster configured for cluster %s", m.name)
+	}
+	return m.connect.do(http.MethodPut, connectorPath(name, "resume"), nil, nil, nil)
+}
+
+// RestartConnector restarts a connector, and its tasks when requested
+func (m *ClusterManager) RestartConnector(name string, req domain.KafkaConnectRestartRequest) error {
+	if m.connect == nil {
+		return fmt.Errorf("no kafka connect cluster configured for cluster %s", m.name)
+	}
+
+	// Workers older than 3.0 ignore both parameters and restart only the
+	// connector instance
+	query := url.Values{
+		"includeTasks": {strconv.FormatBool(req.IncludeTasks)},
+		"onlyFailed":   {strconv.FormatBool(req.OnlyFailed)},
+	}
+	return m.connect.do(http.MethodPost, connectorPath(name, "restart"), query, nil, nil)
+}
+
+// RestartConnectorTask restarts a single task of a connector
+func (m *ClusterManager) RestartConnectorTask(name string, task int) error {
+	if m.connect == nil {
+		return fmt.Errorf("no kafka connect cluster configured for cluster %s", m.name)
+	}
+	return m.connect.do(http.MethodPost, connectorPath(name, "tasks", strconv.Itoa(task), "restart"), nil, nil, nil)
+}
+
+// UpdateConnectorConfig replaces the config of an existing connector. Connect
+// would create a missing connector instead, so its existence is checked first.
+func (m *ClusterManager) UpdateConnectorConfig(name string, config map[string]string) (*domain.KafkaConnector, error) {
+	if m.connect == nil {
+		return nil, fmt.Errorf("no kafka connect cluster configured for cluster %s", m.name)
+	}
+	if len(config) == 0 {
+		return nil, fmt.Errorf("config must not be empty")
+	}
+	if configName, ok := config["name"]; ok && configName != name {
+		return nil, fmt.Errorf("config name %s does not match connector %s", configName, name)
+	}
+	if config["connector.class"] == "" {
+		return nil, fmt.Errorf("connector.class is required")
+	}
+
+	var status connectorStatus
+	if err := m.connect.do(http.MethodGet, connectorPath(name, "status"), nil, nil, &status); err != nil {
+		var connErr *connectError
+		if errors.As(err, &connErr) && connErr.Status == http.StatusNotFound {
+			return nil, fmt.Errorf("connector %s not found", name)
+		}
+		return nil, err
+	}
+
+	var info connectorInfo
+	if err := m.connect.do(http.MethodPut, connectorPath(name, "config"), nil, config, &info); err != nil {
+		return nil, err
+	}
+
+	// Tasks are reconfigured asynchronously, the status is the one before
+	connector := buildConnector(info, status)
+	return &connector, nil
+}
+
+func buildConnector(info connectorInfo, status connectorStatus) domain.KafkaConnector {
+	connector := domain.KafkaConnector{
+		Name:     info.Name,
+		Type:     info.Type,
+		State:    status.Connector.State,
+		WorkerID: status.Connector.WorkerID,
+		Trace:    status.Connector.Trace,
+		Config:   info.Config,
+		Tasks:    status.Tasks,
+	}
+	if connector.Name == "" {
+		connector.Name = status.Name
+	}
+	if connector.Type == "" {
+		connector.Type = status.Type
+	}
+	if connector.Config == nil {
+		connector.Config = make(map[string]string)
+	}
+	if connector.Tasks == nil {
+		connector.Tasks = make([]domain.KafkaConnectTask, 0)
+	}
+	sort.Slice(connector.Tasks, func(i, j int) bool { return connector.Tasks[i].ID < connector.Tasks[j].ID })
+	return connector
+}
+
+// connectWorkers groups connectors and tasks by the worker running them
+func connectWorkers(connectors []domain.KafkaConnector) []domain.KafkaConnectWorker {
+	byID := make(map[string]*domain.KafkaConnectWorker)
+	worker := func(id string) *domain.KafkaConnectWorker {
+		w, ok := byID[id]
+		if !ok {
+			w = &domain.KafkaConnectWorker{WorkerID: id, Connectors: make([]string, 0), Tasks: make([]string, 0)}
+			byID[id] = w
+		}
+		return w
+	}
+
+	// Unassigned connectors and tasks have no worker
+	for _, connector := range connectors {
+		if connector.WorkerID != "" {
+			w := worker(connector.WorkerID)
+			w.Connectors = append(w.Connectors, connector.Name)
+		}
+		for _, task := range connector.Tasks {
+			if task.WorkerID == "" {
+				continue
+			}
+			w := worker(task.WorkerID)
+			w.Tasks = append(w.Tasks, fmt.Sprintf("%s-%d", connector.Name, task.ID))
+			if task.State == "FAILED" {
+				w.FailedTasks++
+			}
+		}
+	}
+
+	workers := make([]domain.KafkaConnectWorker, 0, len(byID))
+	for _, w := range byID {
+		workers = append(workers, *w)
+	}
+	sort.Slice(workers, func(i, j int) bool { return workers[i].WorkerID < workers[j].WorkerID })
+	return workers
+}
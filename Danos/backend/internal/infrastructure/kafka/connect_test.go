@@ -0,0 +1,149 @@
+package kafka
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Danos/backend/internal/domain"
+)
+
+// newTestConnect starts a fake Connect worker and returns a cluster whose
+// first Connect URL is unreachable, so every request fails over to the fake
+func newTestConnect(t *testing.T, handler http.HandlerFunc) *ClusterManager {
+	t.Helper()
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	up := httptest.NewServer(handler)
+	t.Cleanup(up.Close)
+
+	client, err := newConnectClient(domain.KafkaConnect{URLs: []string{down.URL, up.URL + "/"}}, up.Client())
+	if err != nil {
+		t.Fatalf("newConnectClient: %v", err)
+	}
+	return &ClusterManager{name: "test", connect: client}
+}
+
+func TestGetConnect(t *testing.T) {
+	m := newTestConnect(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`{"version":"3.7.0","commit":"abc","kafka_cluster_id":"cluster-1"}`))
+		case "/connectors":
+			if expand := r.URL.Query()["expand"]; !reflect.DeepEqual(expand, []string{"info", "status"}) {
+				t.Errorf("expand = %v", expand)
+			}
+			w.Write([]byte(`{
+				"sink": {
+					"info": {"name": "sink", "type": "sink", "config": {"connector.class": "FileSink"}},
+					"status": {"name": "sink", "connector": {"state": "RUNNING", "worker_id": "w1:8083"},
+						"tasks": [{"id": 1, "state": "FAILED", "worker_id": "w2:8083", "trace": "boom"},
+							{"id": 0, "state": "RUNNING", "worker_id": "w1:8083"}]}
+				},
+				"source": {
+					"info": {"name": "source", "type": "source", "config": {}},
+					"status": {"name": "source", "connector": {"state": "FAILED", "worker_id": "w2:8083"},
+						"tasks": [{"id": 0, "state": "UNASSIGNED", "worker_id": ""}]}
+				}
+			}`))
+		case "/connector-plugins":
+			w.Write([]byte(`[{"class": "b.Sink", "type": "sink"}, {"class": "a.Source", "type": "source"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	cluster, err := m.GetConnect()
+	if err != nil {
+		t.Fatalf("GetConnect: %v", err)
+	}
+
+	if cluster.Version != "3.7.0" || cluster.KafkaClusterID != "cluster-1" {
+		t.Errorf("root = %q %q", cluster.Version, cluster.KafkaClusterID)
+	}
+	if len(cluster.Connectors) != 2 || cluster.Connectors[0].Name != "sink" || cluster.Connectors[1].Name != "source" {
+		t.Fatalf("connectors = %+v", cluster.Connectors)
+	}
+	if tasks := cluster.Connectors[0].Tasks; tasks[0].ID != 0 || tasks[1].Trace != "boom" {
+		t.Errorf("sink tasks = %+v", tasks)
+	}
+	if cluster.FailedConnectors != 1 || cluster.FailedTasks != 1 {
+		t.Errorf("failed = %d connectors, %d tasks", cluster.FailedConnectors, cluster.FailedTasks)
+	}
+	if cluster.Plugins[0].Class != "a.Source" {
+		t.Errorf("plugins = %+v", cluster.Plugins)
+	}
+
+	workers := []domain.KafkaConnectWorker{
+		{WorkerID: "w1:8083", Connectors: []string{"sink"}, Tasks: []string{"sink-0"}},
+		{WorkerID: "w2:8083", Connectors: []string{"source"}, Tasks: []string{"sink-1"}, FailedTasks: 1},
+	}
+	if !reflect.DeepEqual(cluster.Workers, workers) {
+		t.Errorf("workers = %+v, want %+v", cluster.Workers, workers)
+	}
+}
+
+func TestConnectError(t *testing.T) {
+	m := newTestConnect(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/connectors/sink/pause" {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error_code": 409, "message": "rebalance in progress"}`))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	var connErr *connectError
+	err := m.PauseConnector("sink")
+	if !errors.As(err, &connErr) || connErr.Status != http.StatusConflict || connErr.Message != "rebalance in progress" {
+		t.Errorf("PauseConnector error = %v", err)
+	}
+
+	// Error responses are returned as is, not failed over
+	err = m.ResumeConnector("sink")
+	if !errors.As(err, &connErr) || connErr.Status != http.StatusInternalServerError {
+		t.Errorf("ResumeConnector error = %v", err)
+	}
+	if !strings.Contains(err.Error(), "status 500") {
+		t.Errorf("error without body = %q", err.Error())
+	}
+}
+
+func TestUpdateConnectorConfig(t *testing.T) {
+	updated := false
+	m := newTestConnect(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/connectors/sink/status":
+			w.Write([]byte(`{"name": "sink", "connector": {"state": "RUNNING", "worker_id": "w1:8083"}, "tasks": []}`))
+		case r.URL.Path == "/connectors/sink/config" && r.Method == http.MethodPut:
+			updated = true
+			w.Write([]byte(`{"name": "sink", "type": "sink", "config": {"connector.class": "FileSink", "topics": "b"}}`))
+		case r.Method == http.MethodPut:
+			t.Errorf("unexpected config update %s", r.URL.Path)
+			fallthrough
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code": 404, "message": "connector not found"}`))
+		}
+	})
+
+	if _, err := m.UpdateConnectorConfig("missing", map[string]string{"connector.class": "FileSink"}); err == nil ||
+		err.Error() != "connector missing not found" {
+		t.Errorf("unknown connector error = %v", err)
+	}
+	if _, err := m.UpdateConnectorConfig("sink", map[string]string{"name": "other", "connector.class": "FileSink"}); err == nil {
+		t.Error("expected an error for a mismatched name")
+	}
+
+	connector, err := m.UpdateConnectorConfig("sink", map[string]string{"connector.class": "FileSink", "topics": "b"})
+	if err != nil {
+		t.Fatalf("UpdateConnectorConfig: %v", err)
+	}
+	if !updated || connector.State != "RUNNING" || connector.Config["topics"] != "b" {
+		t.Errorf("connector = %+v", connector)
+	}
+}
@@ -0,0 +1,390 @@
+package kafka
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/linkedin/goavro/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	// Well-known types are imported by schemas without registry references
+	_ "google.golang.org/protobuf/types/known/anypb"
+	_ "google.golang.org/protobuf/types/known/durationpb"
+	_ "google.golang.org/protobuf/types/known/emptypb"
+	_ "google.golang.org/protobuf/types/known/structpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+	_ "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Keys and values longer than this are cut in message views
+const maxMessageValueBytes = 16 * 1024
+
+// Schema registry serializers prefix payloads with a zero magic byte and a
+// four byte big endian schema ID
+const (
+	registryMagicByte  = 0
+	registryHeaderSize = 5
+)
+
+// decodeMessage converts a consumed message for display, decoding schema
+// registry payloads when the cluster has a registry
+func (m *ClusterManager) decodeMessage(msg *kafka.Message) *domain.KafkaMessage {
+	message := &domain.KafkaMessage{
+		Topic:     *msg.TopicPartition.Topic,
+		Partition: msg.TopicPartition.Partition,
+		Offset:    int64(msg.TopicPartition.Offset),
+	}
+	if msg.TimestampType != kafka.TimestampNotAvailable {
+		timestamp := msg.Timestamp
+		message.Timestamp = &timestamp
+	}
+
+	var keyErr, valueErr error
+	var keyTruncated, valueTruncated bool
+	message.Key, message.KeyEncoding, message.KeySchema, keyTruncated, keyErr = m.decodePayload(message.Topic, msg.Key, true)
+	message.Value, message.ValueEncoding, message.ValueSchema, valueTruncated, valueErr = m.decodePayload(message.Topic, msg.Value, false)
+	message.Truncated = keyTruncated || valueTruncated
+
+	switch {
+	case keyErr != nil && valueErr != nil:
+		message.DecodeError = fmt.Sprintf("key: %v; value: %v", keyErr, valueErr)
+	case keyErr != nil:
+		message.DecodeError = "key: " + keyErr.Error()
+	case valueErr != nil:
+		message.DecodeError = "value: " + valueErr.Error()
+	}
+
+	if len(msg.Headers) > 0 {
+		message.Headers = make(map[string]string, len(msg.Headers))
+		for _, header := range msg.Headers {
+			message.Headers[header.Key] = string(header.Value)
+		}
+	}
+
+	return message
+}
+
+// decodePayload renders a key or value. Registry framed payloads are decoded
+// to JSON; when that fails the raw payload is returned with the error.
+func (m *ClusterManager) decodePayload(topic string, data []byte, isKey bool) (string, string, *domain.KafkaMessageSchema, bool, error) {
+	if m.registry == nil || len(data) < registryHeaderSize || data[0] != registryMagicByte {
+		text, encoding, truncated := renderRaw(data)
+		return text, encoding, nil, truncated, nil
+	}
+
+	id := int(binary.BigEndian.Uint32(data[1:registryHeaderSize]))
+	schema := m.registry.schema(id)
+	if schema.err != nil {
+		text, encoding, truncated := renderRaw(data)
+		return text, encoding, nil, truncated, fmt.Errorf("schema %d: %w", id, schema.err)
+	}
+
+	info := &domain.KafkaMessageSchema{ID: id, Type: schema.schemaType}
+	info.Subject, info.Version = schema.subjectVersion(topic, isKey)
+
+	decoded, err := schema.decode(data[registryHeaderSize:])
+	if err != nil {
+		text, encoding, truncated := renderRaw(data)
+		return text, encoding, nil, truncated, fmt.Errorf("%s schema %d: %w", strings.ToLower(schema.schemaType), id, err)
+	}
+
+	text, truncated := truncateText(string(decoded))
+	return text, "json", info, truncated, nil
+}
+
+// decode converts a payload without its registry header to JSON
+func (s *registrySchema) decode(payload []byte) ([]byte, error) {
+	switch s.schemaType {
+	case "AVRO":
+		native, _, err := s.avro.NativeFromBinary(payload)
+		if err != nil {
+			return nil, err
+		}
+		return s.avro.TextualFromNative(nil, native)
+	case "PROTOBUF":
+		indexes, rest, err := readMessageIndexes(payload)
+		if err != nil {
+			return nil, err
+		}
+		descriptor, err := protoMessageDescriptor(s.proto, indexes)
+		if err != nil {
+			return nil, err
+		}
+		message := dynamicpb.NewMessage(descriptor)
+		if err := proto.Unmarshal(rest, message); err != nil {
+			return nil, err
+		}
+		return protojson.Marshal(message)
+	case "JSON":
+		if !json.Valid(payload) {
+			return nil, fmt.Errorf("payload is not valid JSON")
+		}
+		return payload, nil
+	}
+	return nil, fmt.Errorf("unsupported schema type %s", s.schemaType)
+}
+
+// renderRaw returns valid UTF-8 as is and anything else as base64
+func renderRaw(data []byte) (string, string, bool) {
+	if utf8.Valid(data) {
+		text, truncated := truncateText(string(data))
+		return text, "utf8", truncated
+	}
+
+	truncated := len(data) > maxMessageValueBytes
+	if truncated {
+		data = data[:maxMessageValueBytes]
+	}
+	return base64.StdEncoding.EncodeToString(data), "base64", truncated
+}
+
+func truncateText(text string) (string, bool) {
+	if len(text) <= maxMessageValueBytes {
+		return text, false
+	}
+	// Cutting may split a rune
+	return strings.ToValidUTF8(text[:maxMessageValueBytes], ""), true
+}
+
+// avroCodec builds the codec of an Avro schema. Named types of referenced
+// schemas are inlined where first used, since goavro parses a single schema.
+func (r *schemaRegistry) avroCodec(resp registryResponse) (*goavro.Codec, error) {
+	if len(resp.References) == 0 {
+		return goavro.NewCodec(resp.Schema)
+	}
+
+	named := make(map[string]*avroNamedType)
+	if err := r.collectAvroReferences(resp.References, named, make(map[string]bool)); err != nil {
+		return nil, err
+	}
+
+	var schema interface{}
+	if err := json.Unmarshal([]byte(resp.Schema), &schema); err != nil {
+		return nil, fmt.Errorf("invalid avro schema: %w", err)
+	}
+	inlined, err := json.Marshal(inlineAvroNames(schema, named))
+	if err != nil {
+		return nil, err
+	}
+	return goavro.NewCodec(string(inlined))
+}
+
+// avroNamedType is a type defined by a referenced schema
+type avroNamedType struct {
+	definition interface{}
+	inlined    bool
+}
+
+// collectAvroReferences fetches referenced schemas, and theirs, keyed by the
+// full and short name of the type they define
+func (r *schemaRegistry) collectAvroReferences(refs []registryReference, named map[string]*avroNamedType, seen map[string]bool) error {
+	for _, ref := range refs {
+		key := ref.Subject + "/" + strconv.Itoa(ref.Version)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		resp, err := r.subjectVersion(ref.Subject, strconv.Itoa(ref.Version), nil)
+		if err != nil {
+			return fmt.Errorf("failed to fetch reference %s: %w", ref.Name, err)
+		}
+		if err := r.collectAvroReferences(resp.References, named, seen); err != nil {
+			return err
+		}
+
+		var schema interface{}
+		if err := json.Unmarshal([]byte(resp.Schema), &schema); err != nil {
+			return fmt.Errorf("invalid avro reference %s: %w", ref.Name, err)
+		}
+		definition, ok := schema.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := definition["name"].(string)
+		namespace, _ := definition["namespace"].(string)
+		if name == "" {
+			continue
+		}
+		namedType := &avroNamedType{definition: definition}
+		named[name] = namedType
+		if namespace != "" && !strings.Contains(name, ".") {
+			named[namespace+"."+name] = namedType
+		}
+	}
+	return nil
+}
+
+// inlineAvroNames replaces the first use of each referenced type name with
+// its definition. Later uses stay names, which Avro resolves.
+func inlineAvroNames(node interface{}, named map[string]*avroNamedType) interface{} {
+	switch v := node.(type) {
+	case string:
+		namedType, ok := named[v]
+		if !ok || namedType.inlined {
+			return v
+		}
+		namedType.inlined = true
+		return inlineAvroNames(namedType.definition, named)
+	case []interface{}:
+		for i := range v {
+			v[i] = inlineAvroNames(v[i], named)
+		}
+		return v
+	case map[string]interface{}:
+		for _, key := range []string{"type", "items", "values"} {
+			if child, ok := v[key]; ok {
+				v[key] = inlineAvroNames(child, named)
+			}
+		}
+		if fields, ok := v["fields"].([]interface{}); ok {
+			for _, field := range fields {
+				if f, ok := field.(map[string]interface{}); ok {
+					f["type"] = inlineAvroNames(f["type"], named)
+				}
+			}
+		}
+		return v
+	}
+	return node
+}
+
+// protoFile builds the descriptor of a Protobuf schema fetched in serialized
+// form, registering its references first
+func (r *schemaRegistry) protoFile(resp registryResponse) (protoreflect.FileDescriptor, error) {
+	files := new(protoregistry.Files)
+	if err := r.registerProtoReferences(resp.References, files); err != nil {
+		return nil, err
+	}
+
+	file, err := parseSerializedProto(resp.Schema)
+	if err != nil {
+		return nil, err
+	}
+	if file.GetName() == "" {
+		file.Name = proto.String(fmt.Sprintf("schema-%d.proto", resp.ID))
+	}
+
+	return protodesc.NewFile(file, protoResolver{files})
+}
+
+func (r *schemaRegistry) registerProtoReferences(refs []registryReference, files *protoregistry.Files) error {
+	query := url.Values{}
+	query.Set("format", "serialized")
+
+	for _, ref := range refs {
+		if _, err := (protoResolver{files}).FindFileByPath(ref.Name); err == nil {
+			continue
+		}
+
+		resp, err := r.subjectVersion(ref.Subject, strconv.Itoa(ref.Version), query)
+		if err != nil {
+			return fmt.Errorf("failed to fetch reference %s: %w", ref.Name, err)
+		}
+		if err := r.registerProtoReferences(resp.References, files); err != nil {
+			return err
+		}
+
+		file, err := parseSerializedProto(resp.Schema)
+		if err != nil {
+			return fmt.Errorf("invalid protobuf reference %s: %w", ref.Name, err)
+		}
+		file.Name = proto.String(ref.Name)
+
+		descriptor, err := protodesc.NewFile(file, protoResolver{files})
+		if err != nil {
+			return fmt.Errorf("invalid protobuf reference %s: %w", ref.Name, err)
+		}
+		if err := files.RegisterFile(descriptor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseSerializedProto(schema string) (*descriptorpb.FileDescriptorProto, error) {
+	raw, err := base64.StdEncoding.DecodeString(schema)
+	if err != nil {
+		return nil, fmt.Errorf("registry did not return a serialized protobuf schema: %w", err)
+	}
+	file := &descriptorpb.FileDescriptorProto{}
+	if err := proto.Unmarshal(raw, file); err != nil {
+		return nil, fmt.Errorf("invalid serialized protobuf schema: %w", err)
+	}
+	return file, nil
+}
+
+// protoResolver resolves imports from the fetched references, then from the
+// well-known types linked into the binary
+type protoResolver struct {
+	files *protoregistry.Files
+}
+
+func (r protoResolver) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	if file, err := r.files.FindFileByPath(path); err == nil {
+		return file, nil
+	}
+	return protoregistry.GlobalFiles.FindFileByPath(path)
+}
+
+func (r protoResolver) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	if descriptor, err := r.files.FindDescriptorByName(name); err == nil {
+		return descriptor, nil
+	}
+	return protoregistry.GlobalFiles.FindDescriptorByName(name)
+}
+
+// readMessageIndexes reads the zigzag varint encoded path to the message
+// type within the schema file. A zero count stands for the first message.
+func readMessageIndexes(payload []byte) ([]int, []byte, error) {
+	count, n := binary.Varint(payload)
+	if n <= 0 || count < 0 {
+		return nil, nil, fmt.Errorf("invalid protobuf message indexes")
+	}
+	payload = payload[n:]
+	if count == 0 {
+		return []int{0}, payload, nil
+	}
+	// Every index takes at least a byte, so a larger count is corrupt
+	if count > int64(len(payload)) {
+		return nil, nil, fmt.Errorf("invalid protobuf message indexes")
+	}
+
+	indexes := make([]int, 0, count)
+	for i := int64(0); i < count; i++ {
+		index, n := binary.Varint(payload)
+		if n <= 0 || index < 0 {
+			return nil, nil, fmt.Errorf("invalid protobuf message indexes")
+		}
+		indexes = append(indexes, int(index))
+		payload = payload[n:]
+	}
+	return indexes, payload, nil
+}
+
+func protoMessageDescriptor(file protoreflect.FileDescriptor, indexes []int) (protoreflect.MessageDescriptor, error) {
+	messages := file.Messages()
+	var descriptor protoreflect.MessageDescriptor
+	for _, index := range indexes {
+		if index >= messages.Len() {
+			return nil, fmt.Errorf("message index %d out of range", index)
+		}
+		descriptor = messages.Get(index)
+		messages = descriptor.Messages()
+	}
+	return descriptor, nil
+}
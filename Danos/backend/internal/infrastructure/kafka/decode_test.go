@@ -0,0 +1,56 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestReadMessageIndexes(t *testing.T) {
+	varints := func(values ...int64) []byte {
+		var buf []byte
+		for _, v := range values {
+			buf = binary.AppendVarint(buf, v)
+		}
+		return buf
+	}
+
+	tests := []struct {
+		name    string
+		payload []byte
+		indexes []int
+		rest    []byte
+		wantErr bool
+	}{
+		{name: "first message", payload: append(varints(0), 'x'), indexes: []int{0}, rest: []byte{'x'}},
+		{name: "nested message", payload: append(varints(2, 1, 3), 'x'), indexes: []int{1, 3}, rest: []byte{'x'}},
+		{name: "empty", payload: nil, wantErr: true},
+		{name: "negative count", payload: varints(-1), wantErr: true},
+		{name: "negative index", payload: varints(1, -2), wantErr: true},
+		{name: "truncated indexes", payload: varints(3, 1), wantErr: true},
+		{name: "hostile count", payload: varints(math.MaxInt64), wantErr: true},
+		{name: "count larger than payload", payload: append(varints(1<<40), 0, 0, 0), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexes, rest, err := readMessageIndexes(tt.payload)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got indexes %v", indexes)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(indexes, tt.indexes) {
+				t.Errorf("indexes = %v, want %v", indexes, tt.indexes)
+			}
+			if !reflect.DeepEqual(rest, tt.rest) {
+				t.Errorf("rest = %v, want %v", rest, tt.rest)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+// topicRetention holds the retention settings of a topic. Unlimited values
+// are -1, as in Kafka.
+type topicRetention struct {
+	retentionMs    int64
+	retentionBytes int64
+	segmentBytes   int64
+}
+
+// GetBrokerDisk returns the log dir usage of every broker, split by log dir
+// and topic
+func (m *ClusterManager) GetBrokerDisk() ([]domain.KafkaBrokerDisk, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+	if m.extAdmin == nil {
+		return nil, m.extAdminUnavailable("describing log dirs")
+	}
+
+	metadata, err := m.adminClient.GetMetadata(nil, true, 3000)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster metadata: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+	defer cancel()
+
+	// A nil topic set describes every partition
+	dirs, err := m.extAdmin.DescribeAllLogDirs(ctx, nil)
+	if err != nil {
+		log.Printf("Error describing log dirs: %v", err)
+	}
+
+	disks := make([]domain.KafkaBrokerDisk, 0, len(metadata.Brokers))
+	for _, broker := range metadata.Brokers {
+		disk := domain.KafkaBrokerDisk{
+			BrokerID: broker.ID,
+			Host:     broker.Host,
+			LogDirs:  make([]domain.KafkaLogDirUsage, 0),
+			Topics:   make([]domain.KafkaTopicDiskUsage, 0),
+		}
+
+		brokerDirs, ok := dirs[broker.ID]
+		if !ok {
+			disk.Error = "log dirs could not be described"
+			disks = append(disks, disk)
+			continue
+		}
+
+		topics := make(map[string]*domain.KafkaTopicDiskUsage)
+		brokerDirs.Each(func(dir kadm.DescribedLogDir) {
+			usage := domain.KafkaLogDirUsage{Dir: dir.Dir}
+			if dir.Err != nil {
+				usage.Error = dir.Err.Error()
+			}
+
+			dir.Topics.Each(func(p kadm.DescribedLogDirPartition) {
+				usage.Size += p.Size
+				usage.Replicas++
+				if p.IsFuture {
+					// Replica being moved between log dirs of this broker
+					usage.FutureSize += p.Size
+					return
+				}
+
+				topic, ok := topics[p.Topic]
+				if !ok {
+					topic = &domain.KafkaTopicDiskUsage{Topic: p.Topic}
+					topics[p.Topic] = topic
+				}
+				topic.Size += p.Size
+				topic.Replicas++
+			})
+
+			disk.Size += usage.Size
+			disk.Replicas += usage.Replicas
+			disk.LogDirs = append(disk.LogDirs, usage)
+		})
+
+		for _, topic := range topics {
+			disk.Topics = append(disk.Topics, *topic)
+		}
+		sort.Slice(disk.LogDirs, func(i, j int) bool {
+			return disk.LogDirs[i].Dir < disk.LogDirs[j].Dir
+		})
+		sort.Slice(disk.Topics, func(i, j int) bool {
+			if disk.Topics[i].Size != disk.Topics[j].Size {
+				return disk.Topics[i].Size > disk.Topics[j].Size
+			}
+			return disk.Topics[i].Topic < disk.Topics[j].Topic
+		})
+
+		disks = append(disks, disk)
+	}
+
+	sort.Slice(disks, func(i, j int) bool {
+		return disks[i].BrokerID < disks[j].BrokerID
+	})
+
+	return disks, nil
+}
+
+// GetTopicsNearRetention returns topics whose largest partition uses at least
+// threshold percent of retention.bytes. Topics without a size limit are
+// skipped. Kafka deletes whole segments only, so a partition may exceed the
+// limit by up to segment.bytes.
+func (m *ClusterManager) GetTopicsNearRetention(threshold float64) ([]domain.KafkaTopicRetentionUsage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+	if m.extAdmin == nil {
+		return nil, m.extAdminUnavailable("describing log dirs")
+	}
+
+	metadata, err := m.adminClient.GetMetadata(nil, true, 3000)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster metadata: %w", err)
+	}
+
+	names := make([]string, 0, len(metadata.Topics))
+	for name, topic := range metadata.Topics {
+		if topic.Error.Code() == kafka.ErrNoError {
+			names = append(names, name)
+		}
+	}
+	retention := m.getTopicRetention(names)
+
+	var topicsSet kadm.TopicsSet
+	for name, config := range retention {
+		if config.retentionBytes <= 0 {
+			continue
+		}
+		for _, partition := range metadata.Topics[name].Partitions {
+			topicsSet.Add(name, partition.ID)
+		}
+	}
+	sizes := m.getReplicaLogSizes(topicsSet)
+
+	usages := make([]domain.KafkaTopicRetentionUsage, 0)
+	for name, config := range retention {
+		if config.retentionBytes <= 0 {
+			continue
+		}
+
+		usage := domain.KafkaTopicRetentionUsage{
+			Topic:          name,
+			RetentionBytes: config.retentionBytes,
+			RetentionMs:    config.retentionMs,
+			SegmentBytes:   config.segmentBytes,
+		}
+		for _, partition := range metadata.Topics[name].Partitions {
+			for _, size := range sizes[partitionKey(name, partition.ID)] {
+				usage.TotalSize += size
+				if size > usage.LargestPartitionSize {
+					usage.LargestPartition = partition.ID
+					usage.LargestPartitionSize = size
+				}
+			}
+		}
+
+		usage.UsagePercent = float64(usage.LargestPartitionSize) / float64(usage.RetentionBytes) * 100
+		if usage.UsagePercent >= threshold {
+			usages = append(usages, usage)
+		}
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].UsagePercent != usages[j].UsagePercent {
+			return usages[i].UsagePercent > usages[j].UsagePercent
+		}
+		return usages[i].Topic < usages[j].Topic
+	})
+
+	return usages, nil
+}
+
+// getReplicaLogSizes returns the on-disk size of every replica of the given
+// partitions, keyed by partitionKey and broker. Replicas being moved between
+// log dirs and brokers that cannot be described are skipped.
+func (m *ClusterManager) getReplicaLogSizes(topics kadm.TopicsSet) map[string]map[int32]int64 {
+	sizes := make(map[string]map[int32]int64)
+	if m.extAdmin == nil || len(topics) == 0 {
+		return sizes
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 5*time.Second)
+	defer cancel()
+
+	dirs, err := m.extAdmin.DescribeAllLogDirs(ctx, topics)
+	if err != nil {
+		log.Printf("Error describing log dirs: %v", err)
+	}
+
+	dirs.Each(func(dir kadm.DescribedLogDir) {
+		dir.Topics.Each(func(p kadm.DescribedLogDirPartition) {
+			if p.IsFuture {
+				return
+			}
+			key := partitionKey(p.Topic, p.Partition)
+			if sizes[key] == nil {
+				sizes[key] = make(map[int32]int64)
+			}
+			sizes[key][p.Broker] = p.Size
+		})
+	})
+
+	return sizes
+}
+
+// getTopicRetention returns the retention settings of the given topics.
+// Topics that cannot be described are left out.
+func (m *ClusterManager) getTopicRetention(topics []string) map[string]topicRetention {
+	retention := make(map[string]topicRetention)
+	for topic, config := range m.describeTopicConfigs(topics) {
+		retention[topic] = topicRetention{
+			retentionMs:    configInt(config, "retention.ms"),
+			retentionBytes: configInt(config, "retention.bytes"),
+			segmentBytes:   configInt(config, "segment.bytes"),
+		}
+	}
+	return retention
+}
+
+// describeTopicConfigs describes the configs of the given topics in a single
+// request. Topics that cannot be described are left out.
+func (m *ClusterManager) describeTopicConfigs(topics []string) map[string]map[string]string {
+	configs := make(map[string]map[string]string)
+	if len(topics) == 0 {
+		return configs
+	}
+
+	resources := make([]kafka.ConfigResource, 0, len(topics))
+	for _, topic := range topics {
+		resources = append(resources, kafka.ConfigResource{Type: kafka.ResourceTopic, Name: topic})
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 5*time.Second)
+	defer cancel()
+
+	results, err := m.adminClient.DescribeConfigs(ctx, resources)
+	if err != nil {
+		log.Printf("Error describing topic configs: %v", err)
+		return configs
+	}
+
+	for _, result := range results {
+		if result.Error.Code() != kafka.ErrNoError {
+			continue
+		}
+		config := make(map[string]string, len(result.Config))
+		for name, entry := range result.Config {
+			config[name] = entry.Value
+		}
+		configs[result.Name] = config
+	}
+
+	return configs
+}
+
+// configInt parses a numeric config value, returning -1 when it is missing
+func configInt(config map[string]string, name string) int64 {
+	value, err := strconv.ParseInt(config[name], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return value
+}
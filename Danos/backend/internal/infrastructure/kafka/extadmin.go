@@ -0,0 +1,50 @@
+package kafka
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// newExtAdminClient creates a franz-go admin client for the admin APIs that
+// librdkafka does not expose, such as describing log dirs. The underlying
+// client is returned for raw requests that kadm does not wrap either.
+func newExtAdminClient(config *domain.KafkaClusterConfig) (*kadm.Client, *kgo.Client, error) {
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(config.Brokers...),
+		kgo.ClientID("danos-monitoring"),
+		kgo.DialTimeout(3 * time.Second),
+		kgo.RequestRetries(1),
+		kgo.RetryTimeout(3 * time.Second),
+	}
+
+	protocol := strings.ToUpper(config.Security.Protocol)
+
+	if protocol == "SSL" || protocol == "SASL_SSL" {
+		tlsConfig, err := buildTLSConfig(config.Security.TLS)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, kgo.DialTLSConfig(tlsConfig))
+	}
+
+	if protocol == "SASL_SSL" || protocol == "SASL_PLAINTEXT" {
+		mechanism, err := buildSASLMechanism(config.Security)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, kgo.SASL(mechanism))
+	}
+
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	admin := kadm.NewClient(client)
+	admin.SetTimeoutMillis(5000)
+
+	return admin, client, nil
+}
@@ -0,0 +1,222 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+)
+
+// Defaults of consumer group tracking
+const (
+	defaultRebalanceStuckAfter  = 60 * time.Second
+	defaultRebalanceStormCount  = 5
+	defaultRebalanceStormWindow = 10 * time.Minute
+	maxGroupEvents              = 1000
+	defaultGroupEventsLimit     = 100
+)
+
+// groupState is a consumer group as seen by the previous poll
+type groupState struct {
+	state      string
+	since      time.Time
+	members    map[string]domain.KafkaGroupMember // by consumer ID
+	rebalances []time.Time                        // within the storm window
+	stuck      bool                               // rebalance_stuck was emitted for the current state
+	storm      bool                               // rebalance_storm was emitted for the current storm
+}
+
+// trackGroup compares a group with the previous poll, records the changes as
+// events and fills the rebalance fields of its metrics. The first poll of a
+// group only records it.
+func (m *ClusterManager) trackGroup(desc *domain.KafkaConsumerGroupDetail, metric *domain.KafkaConsumerMetrics, now time.Time) {
+	stuckAfter, stormCount, stormWindow := m.rebalanceLimits()
+
+	members := make(map[string]domain.KafkaGroupMember, len(desc.Members))
+	for _, member := range desc.Members {
+		members[member.ConsumerID] = member
+	}
+
+	m.groupsMu.Lock()
+	defer m.groupsMu.Unlock()
+
+	prev, ok := m.groupStates[desc.GroupID]
+	if !ok {
+		prev = &groupState{state: desc.State, since: now, members: members}
+		m.groupStates[desc.GroupID] = prev
+	}
+
+	event := func(eventType string) domain.KafkaGroupEvent {
+		return domain.KafkaGroupEvent{GroupID: desc.GroupID, Type: eventType, Time: now, State: desc.State}
+	}
+
+	if ok {
+		// A rebalance is counted when the group enters it, or from membership
+		// changes when a poll missed the rebalancing state
+		wasRebalancing := isRebalancing(prev.state)
+		rebalanced := false
+
+		if prev.state != desc.State {
+			e := event("state_changed")
+			e.PreviousState = prev.state
+			m.addGroupEvent(e)
+
+			rebalanced = isRebalancing(desc.State) && !wasRebalancing
+			prev.state = desc.State
+			prev.since = now
+			prev.stuck = false
+		}
+
+		changed := false
+		for id, member := range members {
+			old, existed := prev.members[id]
+			switch {
+			case !existed:
+				m.addGroupEvent(memberEvent(event("member_joined"), member))
+				changed = true
+			case assignmentKey(old.Assignment) != assignmentKey(member.Assignment):
+				m.addGroupEvent(memberEvent(event("assignment_changed"), member))
+				changed = true
+			}
+		}
+		for id, old := range prev.members {
+			if _, exists := members[id]; !exists {
+				e := memberEvent(event("member_left"), old)
+				e.Assignment = nil
+				m.addGroupEvent(e)
+				changed = true
+			}
+		}
+		prev.members = members
+
+		if rebalanced || (changed && !wasRebalancing) {
+			prev.rebalances = append(prev.rebalances, now)
+		}
+	}
+
+	// Only rebalances within the storm window count
+	kept := prev.rebalances[:0]
+	for _, at := range prev.rebalances {
+		if now.Sub(at) <= stormWindow {
+			kept = append(kept, at)
+		}
+	}
+	prev.rebalances = kept
+
+	storm := len(prev.rebalances) >= stormCount
+	if storm && !prev.storm {
+		e := event("rebalance_storm")
+		e.Rebalances = len(prev.rebalances)
+		m.addGroupEvent(e)
+		log.Printf("Consumer group %s on %s rebalanced %d times within %s", desc.GroupID, m.name, len(prev.rebalances), stormWindow)
+	}
+	prev.storm = storm
+
+	stuck := isRebalancing(desc.State) && now.Sub(prev.since) >= stuckAfter
+	if stuck && !prev.stuck {
+		m.addGroupEvent(event("rebalance_stuck"))
+		log.Printf("Consumer group %s on %s has been in %s for %s", desc.GroupID, m.name, desc.State, now.Sub(prev.since).Round(time.Second))
+		prev.stuck = true
+	}
+
+	since := prev.since
+	metric.StateSince = &since
+	metric.RebalancesInWindow = len(prev.rebalances)
+	metric.RebalanceStorm = storm
+	metric.RebalanceStuck = stuck
+}
+
+// forgetGroups drops the tracked state of groups missing from a poll
+func (m *ClusterManager) forgetGroups(seen map[string]bool) {
+	m.groupsMu.Lock()
+	defer m.groupsMu.Unlock()
+
+	for group := range m.groupStates {
+		if !seen[group] {
+			delete(m.groupStates, group)
+		}
+	}
+}
+
+// addGroupEvent records an event, dropping the oldest beyond maxGroupEvents.
+// groupsMu must be held.
+func (m *ClusterManager) addGroupEvent(event domain.KafkaGroupEvent) {
+	m.groupEvents = append(m.groupEvents, event)
+	if len(m.groupEvents) > maxGroupEvents {
+		m.groupEvents = m.groupEvents[len(m.groupEvents)-maxGroupEvents:]
+	}
+}
+
+// GetGroupEvents returns recent consumer group events, newest first. Empty
+// group and eventType match every group and type.
+func (m *ClusterManager) GetGroupEvents(group, eventType string, limit int) ([]domain.KafkaGroupEvent, error) {
+	if limit <= 0 {
+		limit = defaultGroupEventsLimit
+	}
+	if limit > maxGroupEvents {
+		return nil, fmt.Errorf("limit must be at most %d", maxGroupEvents)
+	}
+
+	m.groupsMu.Lock()
+	defer m.groupsMu.Unlock()
+
+	events := make([]domain.KafkaGroupEvent, 0)
+	for i := len(m.groupEvents) - 1; i >= 0 && len(events) < limit; i-- {
+		e := m.groupEvents[i]
+		if (group == "" || e.GroupID == group) && (eventType == "" || e.Type == eventType) {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// rebalanceLimits returns the configured rebalance thresholds or their defaults
+func (m *ClusterManager) rebalanceLimits() (time.Duration, int, time.Duration) {
+	stuckAfter, stormCount, stormWindow := defaultRebalanceStuckAfter, defaultRebalanceStormCount, defaultRebalanceStormWindow
+
+	monitoring := m.config.Monitoring
+	if monitoring.RebalanceStuckAfter > 0 {
+		stuckAfter = time.Duration(monitoring.RebalanceStuckAfter) * time.Second
+	}
+	if monitoring.RebalanceStormCount > 0 {
+		stormCount = monitoring.RebalanceStormCount
+	}
+	if monitoring.RebalanceStormWindow > 0 {
+		stormWindow = time.Duration(monitoring.RebalanceStormWindow) * time.Second
+	}
+	return stuckAfter, stormCount, stormWindow
+}
+
+func memberEvent(e domain.KafkaGroupEvent, member domain.KafkaGroupMember) domain.KafkaGroupEvent {
+	e.ConsumerID = member.ConsumerID
+	e.GroupInstanceID = member.GroupInstanceID
+	e.ClientID = member.ClientID
+	e.Host = member.Host
+	e.Assignment = member.Assignment
+	return e
+}
+
+// isRebalancing reports whether a group state is part of a rebalance. The
+// last two are the states of KIP-848 consumer protocol groups.
+func isRebalancing(state string) bool {
+	switch state {
+	case "PreparingRebalance", "CompletingRebalance", "Assigning", "Reconciling":
+		return true
+	}
+	return false
+}
+
+// assignmentKey renders an assignment in a stable order for comparison
+func assignmentKey(assignment []domain.KafkaTopicAssignment) string {
+	parts := make([]string, 0, len(assignment))
+	for _, topic := range assignment {
+		partitions := append([]int32(nil), topic.Partitions...)
+		sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+		parts = append(parts, fmt.Sprintf("%s:%v", topic.Topic, partitions))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
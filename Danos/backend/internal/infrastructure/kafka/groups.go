@@ -0,0 +1,192 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+// GetConsumerGroup returns members, assignments, coordinator and lag of a
+// consumer group
+func (m *ClusterManager) GetConsumerGroup(groupID string) (*domain.KafkaConsumerGroupDetail, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+
+	detail, err := m.describeConsumerGroup(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe consumer group: %w", err)
+	}
+	if detail.State == "Dead" {
+		return nil, fmt.Errorf("consumer group %s not found", groupID)
+	}
+
+	detail.TopicLags, detail.Lag = m.getConsumerLag(groupID)
+	assignOwners(detail.TopicLags, detail.Members)
+
+	return detail, nil
+}
+
+// DeleteConsumerGroup deletes a consumer group and its committed offsets.
+// Only groups without active members can be deleted.
+func (m *ClusterManager) DeleteConsumerGroup(groupID string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return fmt.Errorf("kafka admin client not connected")
+	}
+
+	detail, err := m.describeConsumerGroup(groupID)
+	if err != nil {
+		return fmt.Errorf("failed to describe consumer group: %w", err)
+	}
+	if detail.State != "Empty" {
+		return fmt.Errorf("consumer group %s is %s, only empty groups can be deleted", groupID, detail.State)
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+	defer cancel()
+
+	result, err := m.adminClient.DeleteConsumerGroups(ctx, []string{groupID})
+	if err != nil {
+		return fmt.Errorf("failed to delete consumer group: %w", err)
+	}
+
+	for _, groupResult := range result.ConsumerGroupResults {
+		if groupResult.Error.Code() != kafka.ErrNoError {
+			return fmt.Errorf("failed to delete consumer group %s: %v", groupResult.Group, groupResult.Error)
+		}
+	}
+
+	log.Printf("Consumer group %s deleted successfully", groupID)
+	return nil
+}
+
+// DeleteConsumerGroupOffsets deletes the committed offsets of a group for a
+// topic. The group must not have members assigned to that topic.
+func (m *ClusterManager) DeleteConsumerGroupOffsets(groupID, topic string, partitions []int32) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return fmt.Errorf("kafka admin client not connected")
+	}
+	if m.extAdmin == nil {
+		return m.extAdminUnavailable("deleting offsets")
+	}
+
+	detail, err := m.describeConsumerGroup(groupID)
+	if err != nil {
+		return fmt.Errorf("failed to describe consumer group: %w", err)
+	}
+	for _, member := range detail.Members {
+		for _, assignment := range member.Assignment {
+			if assignment.Topic == topic {
+				return fmt.Errorf("consumer group %s is still consuming %s (member %s)", groupID, topic, member.ConsumerID)
+			}
+		}
+	}
+
+	if len(partitions) == 0 {
+		metadata, err := m.adminClient.GetMetadata(&topic, false, 3000)
+		if err != nil {
+			return fmt.Errorf("failed to get topic metadata: %w", err)
+		}
+		topicMetadata, exists := metadata.Topics[topic]
+		if !exists || topicMetadata.Error.Code() != kafka.ErrNoError {
+			return fmt.Errorf("topic %s not found", topic)
+		}
+		for _, partition := range topicMetadata.Partitions {
+			partitions = append(partitions, partition.ID)
+		}
+	}
+
+	var topicsSet kadm.TopicsSet
+	topicsSet.Add(topic, partitions...)
+
+	ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+	defer cancel()
+
+	responses, err := m.extAdmin.DeleteOffsets(ctx, groupID, topicsSet)
+	if err != nil {
+		return fmt.Errorf("failed to delete offsets: %w", err)
+	}
+	if err := responses.Error(); err != nil {
+		return fmt.Errorf("failed to delete offsets: %w", err)
+	}
+
+	// Rates are relative to the deleted offsets
+	m.samplesMu.Lock()
+	for _, partition := range partitions {
+		delete(m.lagSamples, lagSampleKey(groupID, topic, partition))
+	}
+	m.samplesMu.Unlock()
+
+	log.Printf("Offsets of consumer group %s for topic %s deleted successfully", groupID, topic)
+	return nil
+}
+
+// topicAssignments groups assigned partitions by topic
+func topicAssignments(partitions []kafka.TopicPartition) []domain.KafkaTopicAssignment {
+	byTopic := make(map[string][]int32)
+	for _, tp := range partitions {
+		if tp.Topic == nil {
+			continue
+		}
+		byTopic[*tp.Topic] = append(byTopic[*tp.Topic], tp.Partition)
+	}
+
+	assignments := make([]domain.KafkaTopicAssignment, 0, len(byTopic))
+	for topic, ids := range byTopic {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		assignments = append(assignments, domain.KafkaTopicAssignment{Topic: topic, Partitions: ids})
+	}
+	sort.Slice(assignments, func(i, j int) bool {
+		return assignments[i].Topic < assignments[j].Topic
+	})
+
+	return assignments
+}
+
+// assignOwners fills the consumer and host owning each lagging partition
+func assignOwners(lags []domain.KafkaTopicLag, members []domain.KafkaGroupMember) {
+	owners := make(map[string]domain.KafkaGroupMember)
+	for _, member := range members {
+		for _, assignment := range member.Assignment {
+			for _, partition := range assignment.Partitions {
+				owners[partitionKey(assignment.Topic, partition)] = member
+			}
+		}
+	}
+
+	for i := range lags {
+		if owner, ok := owners[partitionKey(lags[i].Topic, lags[i].Partition)]; ok {
+			lags[i].ConsumerID = owner.ConsumerID
+			lags[i].Host = owner.Host
+		}
+	}
+}
+
+// memberClientIDs returns the distinct client IDs of a group's members
+func memberClientIDs(members []domain.KafkaGroupMember) []string {
+	seen := make(map[string]bool, len(members))
+	ids := make([]string, 0, len(members))
+	for _, member := range members {
+		if !seen[member.ClientID] {
+			seen[member.ClientID] = true
+			ids = append(ids, member.ClientID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,420 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Limits of schema inference
+const (
+	defaultInferenceSamples = 100
+	maxInferenceSamples     = 10000
+	inferenceTimeout        = 2 * time.Minute
+	maxInferenceExamples    = 3
+	maxExampleLength        = 100
+	maxShapeOutliers        = 100
+)
+
+// inferNode merges the values seen at one path
+type inferNode struct {
+	types    map[string]int64
+	count    int64 // values seen at this path
+	objects  int64 // values that were objects, the base of field presence
+	fields   map[string]*inferNode
+	items    *inferNode
+	examples []string
+}
+
+// messageShape is the flattened set of field paths and types of one value
+type messageShape map[string]string
+
+func (m *ClusterManager) validateInference(topic string, req domain.KafkaSchemaInferenceRequest) error {
+	if req.SamplesPerPartition < 0 || req.SamplesPerPartition > maxInferenceSamples {
+		return fmt.Errorf("samples_per_partition must be between 0 and %d", maxInferenceSamples)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return fmt.Errorf("kafka admin client not connected")
+	}
+
+	metadata, err := m.adminClient.GetMetadata(&topic, false, 3000)
+	if err != nil {
+		return fmt.Errorf("failed to get topic metadata: %w", err)
+	}
+	if t, exists := metadata.Topics[topic]; !exists || t.Error.Code() != kafka.ErrNoError {
+		return fmt.Errorf("topic %s not found", topic)
+	}
+	return nil
+}
+
+// InferSchema reads the most recent messages of every partition of a topic
+// and infers the schema of their JSON values
+func (m *ClusterManager) InferSchema(ctx context.Context, topic string, req domain.KafkaSchemaInferenceRequest, update jobUpdate) (*domain.KafkaInferredSchema, error) {
+	samples := req.SamplesPerPartition
+	if samples <= 0 {
+		samples = defaultInferenceSamples
+	}
+
+	consumer, err := m.newReader("inference")
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	partitions, err := readerPartitions(consumer, topic, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := make([]offsetRange, 0, len(partitions))
+	var total int64
+	for _, partition := range partitions {
+		low, high, err := consumer.QueryWatermarkOffsets(topic, partition, readerTimeoutMs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query watermarks of partition %d: %w", partition, err)
+		}
+		r := offsetRange{partition: partition, start: max(high-int64(samples), low), end: high}
+		if r.start < r.end {
+			ranges = append(ranges, r)
+			total += r.end - r.start
+		}
+	}
+
+	result := &domain.KafkaInferredSchema{Topic: topic, Outliers: make([]domain.KafkaShapeOutlier, 0)}
+	root := &inferNode{}
+
+	type sampledShape struct {
+		partition int32
+		offset    int64
+		signature string
+	}
+	sampled := make([]sampledShape, 0, total)
+	shapes := make(map[string]messageShape)
+	counts := make(map[string]int64)
+
+	var visited int64
+	_, err = readRanges(ctx, consumer, topic, ranges, time.Now().Add(inferenceTimeout), func(msg *kafka.Message) bool {
+		visited++
+		if visited%100 == 0 && total > 0 {
+			update(float64(visited)/float64(total), nil)
+		}
+
+		result.Sampled++
+		value, ok := m.payloadJSON(msg.Value)
+		if !ok {
+			result.NonJSON++
+			return true
+		}
+
+		root.add(value)
+
+		shape := make(messageShape)
+		flattenShape("", value, shape)
+		signature := shape.signature()
+		if _, ok := shapes[signature]; !ok {
+			shapes[signature] = shape
+		}
+		counts[signature]++
+		sampled = append(sampled, sampledShape{
+			partition: msg.TopicPartition.Partition,
+			offset:    int64(msg.TopicPartition.Offset),
+			signature: signature,
+		})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if root.count > 0 {
+		result.Root = root.field("$", root.count)
+		result.JSONSchema = root.jsonSchema()
+		result.JSONSchema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+		result.JSONSchema["title"] = topic
+	}
+
+	// The dominant shape is the most common one, ties broken by signature
+	dominant := ""
+	for signature, count := range counts {
+		if count > counts[dominant] || (count == counts[dominant] && signature < dominant) {
+			dominant = signature
+		}
+	}
+	result.Shapes = len(counts)
+	if root.count > 0 {
+		result.DominantShapeShare = float64(counts[dominant]) / float64(root.count)
+	}
+
+	for _, s := range sampled {
+		if s.signature == dominant {
+			continue
+		}
+		result.Mismatches++
+		if len(result.Outliers) < maxShapeOutliers {
+			outlier := compareShapes(shapes[dominant], shapes[s.signature])
+			outlier.Partition = s.partition
+			outlier.Offset = s.offset
+			result.Outliers = append(result.Outliers, outlier)
+		}
+	}
+
+	return result, nil
+}
+
+// payloadJSON parses a value as JSON, decoding schema registry payloads
+// first when the cluster has a registry
+func (m *ClusterManager) payloadJSON(data []byte) (interface{}, bool) {
+	if m.registry != nil && len(data) >= registryHeaderSize && data[0] == registryMagicByte {
+		schema := m.registry.schema(int(binary.BigEndian.Uint32(data[1:registryHeaderSize])))
+		if schema.err == nil {
+			if decoded, err := schema.decode(data[registryHeaderSize:]); err == nil {
+				data = decoded
+			}
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return nil, false
+	}
+	return value, true
+}
+
+func (n *inferNode) add(value interface{}) {
+	if n.types == nil {
+		n.types = make(map[string]int64)
+	}
+	n.count++
+	valueType := jsonType(value)
+	n.types[valueType]++
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		n.objects++
+		if n.fields == nil {
+			n.fields = make(map[string]*inferNode)
+		}
+		for key, child := range v {
+			field, ok := n.fields[key]
+			if !ok {
+				field = &inferNode{}
+				n.fields[key] = field
+			}
+			field.add(child)
+		}
+	case []interface{}:
+		if n.items == nil {
+			n.items = &inferNode{}
+		}
+		for _, item := range v {
+			n.items.add(item)
+		}
+	default:
+		if valueType == "null" || len(n.examples) >= maxInferenceExamples {
+			return
+		}
+		example, _ := json.Marshal(v)
+		text := string(example)
+		if len(text) > maxExampleLength {
+			text = text[:maxExampleLength]
+		}
+		for _, existing := range n.examples {
+			if existing == text {
+				return
+			}
+		}
+		n.examples = append(n.examples, text)
+	}
+}
+
+// field converts the node to its report. parentObjects is the number of
+// objects that could have contained it.
+func (n *inferNode) field(path string, parentObjects int64) *domain.KafkaInferredField {
+	field := &domain.KafkaInferredField{
+		Path:        path,
+		Types:       n.types,
+		Occurrences: n.count,
+		Optional:    n.count < parentObjects,
+		Nullable:    n.types["null"] > 0,
+		Examples:    n.examples,
+	}
+	if parentObjects > 0 {
+		field.Presence = float64(n.count) / float64(parentObjects)
+	}
+
+	if len(n.fields) > 0 {
+		names := make([]string, 0, len(n.fields))
+		for name := range n.fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		field.Fields = make([]domain.KafkaInferredField, 0, len(names))
+		for _, name := range names {
+			field.Fields = append(field.Fields, *n.fields[name].field(joinPath(path, name), n.objects))
+		}
+	}
+	if n.items != nil && n.items.count > 0 {
+		field.Items = n.items.field(path+"[]", n.items.count)
+	}
+
+	return field
+}
+
+// jsonSchema renders the node as a JSON Schema. Fields present in every
+// object are required.
+func (n *inferNode) jsonSchema() map[string]interface{} {
+	types := make([]string, 0, len(n.types))
+	for t := range n.types {
+		// Integers are numbers when both were seen
+		if t == "integer" && n.types["number"] > 0 {
+			continue
+		}
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	schema := make(map[string]interface{})
+	if len(types) == 1 {
+		schema["type"] = types[0]
+	} else {
+		schema["type"] = types
+	}
+
+	if len(n.fields) > 0 {
+		properties := make(map[string]interface{}, len(n.fields))
+		required := make([]string, 0)
+		for name, field := range n.fields {
+			properties[name] = field.jsonSchema()
+			if field.count == n.objects {
+				required = append(required, name)
+			}
+		}
+		sort.Strings(required)
+		schema["properties"] = properties
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	}
+	if n.items != nil && n.items.count > 0 {
+		schema["items"] = n.items.jsonSchema()
+	}
+	if len(n.examples) > 0 {
+		examples := make([]interface{}, 0, len(n.examples))
+		for _, example := range n.examples {
+			var value interface{}
+			if json.Unmarshal([]byte(example), &value) == nil {
+				examples = append(examples, value)
+			}
+		}
+		schema["examples"] = examples
+	}
+
+	return schema
+}
+
+// flattenShape records the type of every path of a value. Array element
+// types are merged under path[].
+func flattenShape(path string, value interface{}, shape messageShape) {
+	if path == "" {
+		path = "$"
+	}
+
+	valueType := jsonType(value)
+	if existing, ok := shape[path]; ok && !strings.Contains("|"+existing+"|", "|"+valueType+"|") {
+		types := append(strings.Split(existing, "|"), valueType)
+		sort.Strings(types)
+		shape[path] = strings.Join(types, "|")
+	} else if !ok {
+		shape[path] = valueType
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			flattenShape(joinPath(path, key), child, shape)
+		}
+	case []interface{}:
+		for _, item := range v {
+			flattenShape(path+"[]", item, shape)
+		}
+	}
+}
+
+func (s messageShape) signature() string {
+	entries := make([]string, 0, len(s))
+	for path, valueType := range s {
+		entries = append(entries, path+":"+valueType)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+// compareShapes lists how shape differs from the dominant shape
+func compareShapes(dominant, shape messageShape) domain.KafkaShapeOutlier {
+	var outlier domain.KafkaShapeOutlier
+	for path, valueType := range dominant {
+		other, ok := shape[path]
+		switch {
+		case !ok:
+			outlier.Missing = append(outlier.Missing, path)
+		case other != valueType:
+			outlier.TypeMismatches = append(outlier.TypeMismatches, fmt.Sprintf("%s: %s instead of %s", path, other, valueType))
+		}
+	}
+	for path := range shape {
+		if _, ok := dominant[path]; !ok {
+			outlier.Extra = append(outlier.Extra, path)
+		}
+	}
+	sort.Strings(outlier.Missing)
+	sort.Strings(outlier.Extra)
+	sort.Strings(outlier.TypeMismatches)
+	return outlier
+}
+
+func joinPath(path, name string) string {
+	if path == "$" {
+		return name
+	}
+	return path + "." + name
+}
+
+// jsonType names the JSON Schema type of a value decoded with UseNumber
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return "number"
+		}
+		return "integer"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "unknown"
+}
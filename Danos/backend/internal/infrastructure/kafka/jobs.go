@@ -0,0 +1,228 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+)
+
+// Finished jobs kept for inspection; older ones are dropped
+const maxFinishedJobs = 50
+
+// jobUpdate reports the progress of a running job, with its running totals
+// when partial is not nil. partial must not be modified after the call.
+type jobUpdate func(progress float64, partial interface{})
+
+// jobRun is the work of a job. It must return soon after ctx is done.
+type jobRun func(ctx context.Context, update jobUpdate) (interface{}, error)
+
+// jobRegistry runs background jobs and keeps their state
+type jobRegistry struct {
+	mu   sync.Mutex
+	jobs map[string]*job
+	next int
+}
+
+type job struct {
+	info   domain.KafkaJob
+	cancel context.CancelFunc
+}
+
+func newJobRegistry() *jobRegistry {
+	return &jobRegistry{jobs: make(map[string]*job)}
+}
+
+// start runs a job in the background. The job is cancelled when parent is done.
+func (r *jobRegistry) start(parent context.Context, jobType, cluster, topic string, run jobRun) domain.KafkaJob {
+	ctx, cancel := context.WithCancel(parent)
+
+	r.mu.Lock()
+	r.next++
+	j := &job{
+		info: domain.KafkaJob{
+			ID:        fmt.Sprintf("%s-%d-%d", jobType, time.Now().Unix(), r.next),
+			Type:      jobType,
+			Cluster:   cluster,
+			Topic:     topic,
+			Status:    "running",
+			StartedAt: time.Now(),
+		},
+		cancel: cancel,
+	}
+	r.jobs[j.info.ID] = j
+	info := j.info
+	r.mu.Unlock()
+
+	update := func(progress float64, partial interface{}) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		j.info.Progress = progress
+		if partial != nil {
+			j.info.Result = partial
+		}
+	}
+
+	go func() {
+		defer cancel()
+		result, err := run(ctx, update)
+		r.finish(j, result, err, ctx.Err())
+	}()
+
+	log.Printf("Started Kafka job %s on %s/%s", info.ID, cluster, topic)
+	return info
+}
+
+func (r *jobRegistry) finish(j *job, result interface{}, err, ctxErr error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	j.info.FinishedAt = &now
+	if result != nil {
+		j.info.Result = result
+	}
+
+	switch {
+	case ctxErr != nil:
+		j.info.Status = "cancelled"
+	case err != nil:
+		j.info.Status = "failed"
+		j.info.Error = err.Error()
+	default:
+		j.info.Status = "completed"
+		j.info.Progress = 1
+	}
+	log.Printf("Kafka job %s %s", j.info.ID, j.info.Status)
+
+	r.prune()
+}
+
+// prune drops the oldest finished jobs beyond maxFinishedJobs
+func (r *jobRegistry) prune() {
+	finished := make([]*job, 0)
+	for _, j := range r.jobs {
+		if j.info.FinishedAt != nil {
+			finished = append(finished, j)
+		}
+	}
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+
+	sort.Slice(finished, func(i, k int) bool {
+		return finished[i].info.FinishedAt.Before(*finished[k].info.FinishedAt)
+	})
+	for _, j := range finished[:len(finished)-maxFinishedJobs] {
+		delete(r.jobs, j.info.ID)
+	}
+}
+
+func (r *jobRegistry) list() []domain.KafkaJob {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	jobs := make([]domain.KafkaJob, 0, len(r.jobs))
+	for _, j := range r.jobs {
+		jobs = append(jobs, j.info)
+	}
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].StartedAt.After(jobs[k].StartedAt)
+	})
+	return jobs
+}
+
+func (r *jobRegistry) get(id string) (*domain.KafkaJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	j, ok := r.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+	info := j.info
+	return &info, nil
+}
+
+func (r *jobRegistry) cancel(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	j, ok := r.jobs[id]
+	if !ok {
+		return fmt.Errorf("job %s not found", id)
+	}
+	if j.info.FinishedAt != nil {
+		return fmt.Errorf("job %s already %s", id, j.info.Status)
+	}
+	j.cancel()
+	return nil
+}
+
+// ListJobs returns the running and recently finished jobs, newest first
+func (m *KafkaManager) ListJobs() []domain.KafkaJob {
+	return m.jobs.list()
+}
+
+// GetJob returns a job by ID
+func (m *KafkaManager) GetJob(id string) (*domain.KafkaJob, error) {
+	return m.jobs.get(id)
+}
+
+// CancelJob stops a running job
+func (m *KafkaManager) CancelJob(id string) error {
+	return m.jobs.cancel(id)
+}
+
+// StartSchemaInference samples a topic in the background and infers the
+// schema of its JSON values
+func (m *KafkaManager) StartSchemaInference(clusterName, topic string, req domain.KafkaSchemaInferenceRequest) (*domain.KafkaJob, error) {
+	cluster, err := m.GetCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if err := cluster.validateInference(topic, req); err != nil {
+		return nil, err
+	}
+
+	job := m.jobs.start(cluster.ctx, "schema_inference", clusterName, topic, func(ctx context.Context, update jobUpdate) (interface{}, error) {
+		result, err := cluster.InferSchema(ctx, topic, req, update)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	})
+	return &job, nil
+}
+
+// StartReplay copies messages of a topic to a destination topic in the
+// background
+func (m *KafkaManager) StartReplay(clusterName, topic string, req domain.KafkaReplayRequest) (*domain.KafkaJob, error) {
+	source, err := m.GetCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if req.DestinationCluster == "" {
+		req.DestinationCluster = clusterName
+	}
+	destination, err := m.GetCluster(req.DestinationCluster)
+	if err != nil {
+		return nil, err
+	}
+	if err := source.validateReplay(destination, topic, req); err != nil {
+		return nil, err
+	}
+
+	job := m.jobs.start(source.ctx, "replay", clusterName, topic, func(ctx context.Context, update jobUpdate) (interface{}, error) {
+		result, err := source.Replay(ctx, destination, topic, req, update)
+		if result == nil {
+			return nil, err
+		}
+		return result, err
+	})
+	return &job, nil
+}
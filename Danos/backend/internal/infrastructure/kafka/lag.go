@@ -0,0 +1,222 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// minSampleInterval is the shortest gap between two offset samples used for
+// rate calculation. Polls closer together reuse the previous rates.
+const minSampleInterval = time.Second
+
+// offsetSample is a committed/log-end offset pair observed at a point in time.
+type offsetSample struct {
+	committed   int64
+	logEnd      int64
+	at          time.Time
+	consumeRate float64
+	produceRate float64
+}
+
+func partitionKey(topic string, partition int32) string {
+	return fmt.Sprintf("%s/%d", topic, partition)
+}
+
+// positionKey identifies an offset within a partition
+func positionKey(topic string, partition int32, offset int64) string {
+	return fmt.Sprintf("%s/%d@%d", topic, partition, offset)
+}
+
+func lagSampleKey(groupID, topic string, partition int32) string {
+	return groupID + "|" + partitionKey(topic, partition)
+}
+
+// updateLagRates records the offsets of a group partition and returns the
+// consumption and production rates (messages/sec) since the previous poll.
+func (m *ClusterManager) updateLagRates(groupID, topic string, partition int32, committed, logEnd int64, now time.Time) (float64, float64) {
+	m.samplesMu.Lock()
+	defer m.samplesMu.Unlock()
+
+	key := lagSampleKey(groupID, topic, partition)
+	prev, ok := m.lagSamples[key]
+	if !ok {
+		m.lagSamples[key] = offsetSample{committed: committed, logEnd: logEnd, at: now}
+		return 0, 0
+	}
+
+	elapsed := now.Sub(prev.at).Seconds()
+	if elapsed < minSampleInterval.Seconds() {
+		return prev.consumeRate, prev.produceRate
+	}
+
+	consumeRate := float64(committed-prev.committed) / elapsed
+	if consumeRate < 0 {
+		consumeRate = 0 // offsets were reset
+	}
+	produceRate := float64(logEnd-prev.logEnd) / elapsed
+	if produceRate < 0 {
+		produceRate = 0 // topic was recreated
+	}
+
+	m.lagSamples[key] = offsetSample{
+		committed:   committed,
+		logEnd:      logEnd,
+		at:          now,
+		consumeRate: consumeRate,
+		produceRate: produceRate,
+	}
+	return consumeRate, produceRate
+}
+
+// forgetLagSamples drops the offset samples of group partitions missing from
+// a poll
+func (m *ClusterManager) forgetLagSamples(seen map[string]bool) {
+	m.samplesMu.Lock()
+	defer m.samplesMu.Unlock()
+
+	for key := range m.lagSamples {
+		if !seen[key] {
+			delete(m.lagSamples, key)
+		}
+	}
+}
+
+// timeToDrain estimates how long it takes to consume the lag at the current
+// rates. It returns nil when the lag is not shrinking.
+func timeToDrain(lag int64, consumeRate, produceRate float64) *float64 {
+	seconds := 0.0
+	if lag > 0 {
+		drainRate := consumeRate - produceRate
+		if drainRate <= 0 {
+			return nil
+		}
+		seconds = float64(lag) / drainRate
+	}
+	return &seconds
+}
+
+// oldestUnconsumed returns the offset of the oldest unconsumed message of a
+// partition: the committed offset, or the log start when retention deleted
+// the messages from there
+func oldestUnconsumed(lag domain.KafkaTopicLag) int64 {
+	return max(lag.CurrentOffset, lag.LogStartOffset)
+}
+
+// lagPositions returns the position of the oldest unconsumed message of
+// every lagging partition
+func lagPositions(lags []domain.KafkaTopicLag) []kafka.TopicPartition {
+	positions := make([]kafka.TopicPartition, 0)
+	for i := range lags {
+		if lags[i].Lag > 0 && lags[i].CurrentOffset >= 0 {
+			positions = append(positions, kafka.TopicPartition{
+				Topic:     &lags[i].Topic,
+				Partition: lags[i].Partition,
+				Offset:    kafka.Offset(oldestUnconsumed(lags[i])),
+			})
+		}
+	}
+	return positions
+}
+
+// applyLagSeconds sets the lag in seconds of each partition to the age of
+// its oldest unconsumed message
+func applyLagSeconds(lags []domain.KafkaTopicLag, timestamps map[string]time.Time, now time.Time) {
+	for i := range lags {
+		if ts, ok := timestamps[positionKey(lags[i].Topic, lags[i].Partition, oldestUnconsumed(lags[i]))]; ok {
+			if age := now.Sub(ts).Seconds(); age > 0 {
+				lags[i].LagSeconds = age
+			}
+		}
+	}
+}
+
+// fetchTimestamps reads the message at each given position with the monitoring
+// consumer and returns its timestamp keyed by positionKey. Positions in the
+// same partition are read in turns, all within the timeout. Positions without
+// a message before the timeout are left out. A position that expired in the
+// meantime resets to the log start, whose message is then the oldest left.
+func (m *ClusterManager) fetchTimestamps(positions []kafka.TopicPartition, timeout time.Duration) map[string]time.Time {
+	timestamps := make(map[string]time.Time)
+	if len(positions) == 0 || m.consumer == nil {
+		return timestamps
+	}
+
+	m.fetchMu.Lock()
+	defer m.fetchMu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	pending := positions
+	for len(pending) > 0 && time.Now().Before(deadline) {
+		// A partition can only be assigned at one position at a time
+		turn := make(map[string]kafka.TopicPartition)
+		next := make([]kafka.TopicPartition, 0)
+		for _, position := range pending {
+			key := partitionKey(*position.Topic, position.Partition)
+			if queued, ok := turn[key]; ok {
+				if queued.Offset != position.Offset {
+					next = append(next, position)
+				}
+				continue
+			}
+			turn[key] = position
+		}
+
+		m.readTimestamps(turn, deadline, timestamps)
+		pending = next
+	}
+
+	return timestamps
+}
+
+// answersPosition reports whether a polled message is the one looked up for
+// a position: the message at the position or, where compaction or retention
+// removed it, the first one after. Earlier messages come from an offset reset.
+func answersPosition(position kafka.TopicPartition, msg *kafka.Message) bool {
+	return msg.TopicPartition.Offset >= position.Offset
+}
+
+// readTimestamps reads the message at one position per partition, keyed by
+// partitionKey, until the deadline. fetchMu must be held.
+func (m *ClusterManager) readTimestamps(turn map[string]kafka.TopicPartition, deadline time.Time, timestamps map[string]time.Time) {
+	assignment := make([]kafka.TopicPartition, 0, len(turn))
+	for _, position := range turn {
+		assignment = append(assignment, position)
+	}
+
+	if err := m.consumer.Assign(assignment); err != nil {
+		log.Printf("Error assigning partitions for timestamp lookup: %v", err)
+		return
+	}
+	defer m.consumer.Unassign()
+	// Paused partitions would stay paused for the next turn
+	defer m.consumer.Resume(assignment)
+
+	seen := make(map[string]bool)
+	for len(seen) < len(assignment) {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+
+		switch e := m.consumer.Poll(int(remaining.Milliseconds())).(type) {
+		case *kafka.Message:
+			key := partitionKey(*e.TopicPartition.Topic, e.TopicPartition.Partition)
+			position, ok := turn[key]
+			if !ok || seen[key] || !answersPosition(position, e) {
+				continue
+			}
+			seen[key] = true
+			if e.TimestampType != kafka.TimestampNotAvailable {
+				timestamps[positionKey(*position.Topic, position.Partition, int64(position.Offset))] = e.Timestamp
+			}
+			// One message per partition is enough
+			m.consumer.Pause([]kafka.TopicPartition{e.TopicPartition})
+		case kafka.Error:
+			log.Printf("Error fetching message timestamps: %v", e)
+		}
+	}
+}
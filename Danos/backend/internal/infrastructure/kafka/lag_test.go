@@ -0,0 +1,61 @@
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+func TestAnswersPosition(t *testing.T) {
+	topic := "orders"
+	position := kafka.TopicPartition{Topic: &topic, Partition: 0, Offset: 10}
+	message := func(offset kafka.Offset) *kafka.Message {
+		return &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 0, Offset: offset}}
+	}
+
+	tests := []struct {
+		name   string
+		offset kafka.Offset
+		want   bool
+	}{
+		{name: "at the position", offset: 10, want: true},
+		{name: "after a compacted gap", offset: 12, want: true},
+		{name: "before the position", offset: 9, want: false},
+		{name: "reset to the log start", offset: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := answersPosition(position, message(tt.offset)); got != tt.want {
+				t.Errorf("answersPosition(offset %d) = %v, want %v", tt.offset, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLagPositionsClampToLogStart(t *testing.T) {
+	lags := []domain.KafkaTopicLag{
+		{Topic: "orders", Partition: 0, CurrentOffset: 5, LogStartOffset: 2, LogEndOffset: 9, Lag: 4},
+		{Topic: "orders", Partition: 1, CurrentOffset: 5, LogStartOffset: 20, LogEndOffset: 30, Lag: 25, OffsetOutOfRange: true},
+		{Topic: "orders", Partition: 2, CurrentOffset: 30, LogStartOffset: 20, LogEndOffset: 30},
+	}
+
+	positions := lagPositions(lags)
+	if len(positions) != 2 {
+		t.Fatalf("positions = %v, want 2", positions)
+	}
+	if positions[0].Offset != 5 || positions[1].Offset != 20 {
+		t.Errorf("offsets = %d, %d, want 5, 20", positions[0].Offset, positions[1].Offset)
+	}
+
+	now := time.Now()
+	applyLagSeconds(lags, map[string]time.Time{
+		positionKey("orders", 0, 5):  now.Add(-time.Minute),
+		positionKey("orders", 1, 20): now.Add(-time.Hour),
+	}, now)
+	if lags[0].LagSeconds != 60 || lags[1].LagSeconds != 3600 || lags[2].LagSeconds != 0 {
+		t.Errorf("lag seconds = %v, %v, %v", lags[0].LagSeconds, lags[1].LagSeconds, lags[2].LagSeconds)
+	}
+}
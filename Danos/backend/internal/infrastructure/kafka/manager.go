@@ -0,0 +1,213 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+)
+
+// KafkaManager holds one ClusterManager per configured Kafka cluster
+type KafkaManager struct {
+	mu       sync.RWMutex
+	clusters map[string]*ClusterManager
+	config   *domain.KafkaConfig
+	jobs     *jobRegistry
+}
+
+func NewKafkaManager() *KafkaManager {
+	return &KafkaManager{
+		clusters: make(map[string]*ClusterManager),
+		jobs:     newJobRegistry(),
+	}
+}
+
+// Initialize connects every configured cluster. Clusters that fail to connect
+// are kept so they report as disconnected; an error is only returned when no
+// cluster could be connected.
+func (m *KafkaManager) Initialize(config *domain.KafkaConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.config = config
+	return m.connectClusters()
+}
+
+func (m *KafkaManager) connectClusters() error {
+	if len(m.config.Clusters) == 0 {
+		return fmt.Errorf("no kafka clusters configured")
+	}
+
+	connected := 0
+	var lastErr error
+	for i := range m.config.Clusters {
+		clusterConfig := &m.config.Clusters[i]
+		if existing, ok := m.clusters[clusterConfig.Name]; ok {
+			existing.Close()
+		}
+
+		cluster := newClusterManager(clusterConfig.Name)
+		m.clusters[clusterConfig.Name] = cluster
+
+		if err := cluster.Initialize(clusterConfig); err != nil {
+			log.Printf("Failed to connect to Kafka cluster %s: %v", clusterConfig.Name, err)
+			lastErr = fmt.Errorf("cluster %s: %w", clusterConfig.Name, err)
+			continue
+		}
+		connected++
+	}
+
+	if connected == 0 {
+		return lastErr
+	}
+	return nil
+}
+
+func (m *KafkaManager) Reconnect(config *domain.KafkaConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	log.Println("Reconnecting Kafka clients...")
+
+	m.closeClusters()
+
+	// Wait a bit for cleanup
+	time.Sleep(100 * time.Millisecond)
+
+	m.config = config
+	return m.connectClusters()
+}
+
+// GetCluster returns the manager of a named cluster
+func (m *KafkaManager) GetCluster(name string) (*ClusterManager, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cluster, exists := m.clusters[name]
+	if !exists {
+		return nil, fmt.Errorf("kafka cluster %s not found", name)
+	}
+	return cluster, nil
+}
+
+// GetAllClusters returns the managers of every configured cluster
+func (m *KafkaManager) GetAllClusters() map[string]*ClusterManager {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make(map[string]*ClusterManager, len(m.clusters))
+	for name, cluster := range m.clusters {
+		result[name] = cluster
+	}
+	return result
+}
+
+// GetMetrics collects metrics from every connected cluster, keyed by cluster
+// name. Clusters that fail are logged and left out.
+func (m *KafkaManager) GetMetrics() (map[string]domain.KafkaMetrics, error) {
+	clusters := m.GetAllClusters()
+	if len(clusters) == 0 {
+		return nil, fmt.Errorf("kafka client not connected")
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	metrics := make(map[string]domain.KafkaMetrics, len(clusters))
+
+	for name, cluster := range clusters {
+		if !cluster.IsConnected() {
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string, cluster *ClusterManager) {
+			defer wg.Done()
+			metric, err := cluster.GetMetrics()
+			if err != nil {
+				log.Printf("Error getting Kafka metrics for %s: %v", name, err)
+				return
+			}
+			mu.Lock()
+			metrics[name] = *metric
+			mu.Unlock()
+		}(name, cluster)
+	}
+
+	wg.Wait()
+	return metrics, nil
+}
+
+// IsConnected reports whether at least one cluster is connected
+func (m *KafkaManager) IsConnected() bool {
+	for _, cluster := range m.GetAllClusters() {
+		if cluster.IsConnected() {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *KafkaManager) GetConnectionStatus() map[string]interface{} {
+	clusters := m.GetAllClusters()
+
+	names := make([]string, 0, len(clusters))
+	for name := range clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	statuses := make([]map[string]interface{}, 0, len(names))
+	connected := 0
+	for _, name := range names {
+		status := "disconnected"
+		if clusters[name].IsConnected() {
+			status = "connected"
+			connected++
+		}
+
+		entry := map[string]interface{}{
+			"name":    name,
+			"status":  status,
+			"brokers": clusters[name].GetConnectionInfo(),
+		}
+		// Features such as reassignment, log dirs and quotas are disabled
+		// without the extended admin client, e.g. on GSSAPI clusters
+		if err := clusters[name].ExtendedAdminError(); err != nil {
+			entry["extended_admin_error"] = err.Error()
+		}
+		statuses = append(statuses, entry)
+	}
+
+	overall := "partial"
+	switch connected {
+	case 0:
+		overall = "disconnected"
+	case len(statuses):
+		overall = "connected"
+	}
+
+	return map[string]interface{}{
+		"status":   overall,
+		"clusters": statuses,
+	}
+}
+
+func (m *KafkaManager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closeClusters()
+	return nil
+}
+
+func (m *KafkaManager) closeClusters() {
+	for name, cluster := range m.clusters {
+		if err := cluster.Close(); err != nil {
+			log.Printf("Error closing Kafka cluster %s: %v", name, err)
+		}
+	}
+	m.clusters = make(map[string]*ClusterManager)
+}
@@ -0,0 +1,384 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Limits of message browsing and search
+const (
+	defaultBrowseLimit = 50
+	maxBrowseLimit     = 500
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+	defaultSearchScan  = 10000
+	maxSearchScan      = 100000
+	browseTimeout      = 10 * time.Second
+	searchTimeout      = 30 * time.Second
+)
+
+// Errors of message requests that do not match the topic or the limits
+var (
+	ErrInvalidMessageRequest = errors.New("invalid message request")
+	ErrTopicNotFound         = errors.New("topic not found")
+	ErrPartitionNotFound     = errors.New("partition not found")
+)
+
+// Timeout of the metadata, watermark and offset lookups of a reader
+const readerTimeoutMs = 3000
+
+// offsetRange is a span of offsets to read from one partition, end exclusive
+type offsetRange struct {
+	partition int32
+	start     int64
+	end       int64
+}
+
+// readerConfig returns the configuration of a consumer that is only ever
+// assigned partitions. librdkafka requires a group ID, but the group is
+// never joined and no offsets are committed.
+func (m *ClusterManager) readerConfig(purpose string) kafka.ConfigMap {
+	config := m.buildKafkaConfig()
+	config["group.id"] = "danos-" + purpose + "-" + m.name
+	config["enable.auto.commit"] = false
+	config["enable.auto.offset.store"] = false
+	return config
+}
+
+// BrowseMessages returns messages of a topic. Without an offset or timestamp
+// the latest messages are returned, newest first; otherwise messages are read
+// forward from the offset or timestamp on every selected partition.
+func (m *ClusterManager) BrowseMessages(topic string, req domain.KafkaBrowseRequest) ([]domain.KafkaMessage, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultBrowseLimit
+	}
+	if limit > maxBrowseLimit {
+		return nil, fmt.Errorf("%w: limit must be at most %d", ErrInvalidMessageRequest, maxBrowseLimit)
+	}
+
+	consumer, err := m.newReader("browse")
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	partitions, err := readerPartitions(consumer, topic, req.Partition)
+	if err != nil {
+		return nil, err
+	}
+
+	var starts map[int32]int64
+	if req.Timestamp > 0 {
+		starts, err = offsetsForTime(consumer, topic, partitions, time.UnixMilli(req.Timestamp))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ranges := make([]offsetRange, 0, len(partitions))
+	for _, partition := range partitions {
+		low, high, err := consumer.QueryWatermarkOffsets(topic, partition, readerTimeoutMs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query watermarks of partition %d: %w", partition, err)
+		}
+
+		r := offsetRange{partition: partition, start: low, end: high}
+		switch {
+		case req.Timestamp > 0:
+			start, ok := starts[partition]
+			if !ok {
+				continue // no message at or after the timestamp
+			}
+			r.start = max(start, low)
+			r.end = min(r.start+int64(limit), high)
+		case req.Offset >= 0:
+			r.start = max(req.Offset, low)
+			r.end = min(r.start+int64(limit), high)
+		default:
+			r.start = max(high-int64(limit), low)
+		}
+		if r.start < r.end {
+			ranges = append(ranges, r)
+		}
+	}
+
+	messages := make([]domain.KafkaMessage, 0)
+	_, err = readRanges(m.ctx, consumer, topic, ranges, time.Now().Add(browseTimeout), func(msg *kafka.Message) bool {
+		messages = append(messages, *m.decodeMessage(msg))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	latest := req.Timestamp <= 0 && req.Offset < 0
+	sort.Slice(messages, func(i, j int) bool {
+		a, b := messages[i], messages[j]
+		before := messageTime(a).Before(messageTime(b)) ||
+			(messageTime(a).Equal(messageTime(b)) && (a.Partition < b.Partition || (a.Partition == b.Partition && a.Offset < b.Offset)))
+		if latest {
+			return !before
+		}
+		return before
+	})
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+
+	return messages, nil
+}
+
+// SearchMessages scans a topic for messages whose decoded key and value
+// match the request filters
+func (m *ClusterManager) SearchMessages(topic string, req domain.KafkaSearchRequest) (*domain.KafkaSearchResult, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		return nil, fmt.Errorf("%w: limit must be at most %d", ErrInvalidMessageRequest, maxSearchLimit)
+	}
+	maxScan := req.MaxScan
+	if maxScan <= 0 {
+		maxScan = defaultSearchScan
+	}
+	if maxScan > maxSearchScan {
+		return nil, fmt.Errorf("%w: max_scan must be at most %d", ErrInvalidMessageRequest, maxSearchScan)
+	}
+
+	var keyFilter, valueFilter *regexp.Regexp
+	var err error
+	if req.Key != "" {
+		if keyFilter, err = regexp.Compile(req.Key); err != nil {
+			return nil, fmt.Errorf("%w: invalid key filter: %v", ErrInvalidMessageRequest, err)
+		}
+	}
+	if req.Value != "" {
+		if valueFilter, err = regexp.Compile(req.Value); err != nil {
+			return nil, fmt.Errorf("%w: invalid value filter: %v", ErrInvalidMessageRequest, err)
+		}
+	}
+
+	consumer, err := m.newReader("search")
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	partitions, err := readerPartitions(consumer, topic, -1)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Partitions) > 0 {
+		for _, partition := range req.Partitions {
+			if !containsPartition(partitions, partition) {
+				return nil, fmt.Errorf("%w: partition %d of topic %s", ErrPartitionNotFound, partition, topic)
+			}
+		}
+		partitions = req.Partitions
+	}
+
+	var starts map[int32]int64
+	if req.Since != nil {
+		starts, err = offsetsForTime(consumer, topic, partitions, *req.Since)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ranges := make([]offsetRange, 0, len(partitions))
+	for _, partition := range partitions {
+		low, high, err := consumer.QueryWatermarkOffsets(topic, partition, readerTimeoutMs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query watermarks of partition %d: %w", partition, err)
+		}
+
+		r := offsetRange{partition: partition, start: max(high-int64(maxScan), low), end: high}
+		if req.Since != nil {
+			start, ok := starts[partition]
+			if !ok {
+				continue
+			}
+			r.start = max(start, low)
+			r.end = min(r.start+int64(maxScan), high)
+		}
+		if r.start < r.end {
+			ranges = append(ranges, r)
+		}
+	}
+
+	result := &domain.KafkaSearchResult{Messages: make([]domain.KafkaMessage, 0)}
+	partial, err := readRanges(m.ctx, consumer, topic, ranges, time.Now().Add(searchTimeout), func(msg *kafka.Message) bool {
+		if req.Until != nil && msg.TimestampType != kafka.TimestampNotAvailable && msg.Timestamp.After(*req.Until) {
+			return true
+		}
+		result.Scanned++
+
+		message := m.decodeMessage(msg)
+		if (keyFilter != nil && !keyFilter.MatchString(message.Key)) || (valueFilter != nil && !valueFilter.MatchString(message.Value)) {
+			return true
+		}
+		result.Messages = append(result.Messages, *message)
+		return len(result.Messages) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Partial = partial
+
+	sort.Slice(result.Messages, func(i, j int) bool {
+		return messageTime(result.Messages[i]).Before(messageTime(result.Messages[j]))
+	})
+
+	return result, nil
+}
+
+func (m *ClusterManager) newReader(purpose string) (*kafka.Consumer, error) {
+	m.mu.RLock()
+	connected := m.connected
+	m.mu.RUnlock()
+	if !connected {
+		return nil, fmt.Errorf("kafka cluster %s not connected", m.name)
+	}
+
+	config := m.readerConfig(purpose)
+	config["enable.partition.eof"] = true
+
+	consumer, err := kafka.NewConsumer(&config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s consumer: %w", purpose, err)
+	}
+	return consumer, nil
+}
+
+// readerPartitions returns the partitions of a topic, or only partition when
+// it is not -1
+func readerPartitions(consumer *kafka.Consumer, topic string, partition int32) ([]int32, error) {
+	metadata, err := consumer.GetMetadata(&topic, false, readerTimeoutMs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topic metadata: %w", err)
+	}
+	t, exists := metadata.Topics[topic]
+	if !exists || t.Error.Code() == kafka.ErrUnknownTopicOrPart || t.Error.Code() == kafka.ErrUnknownTopic {
+		return nil, fmt.Errorf("%w: %s", ErrTopicNotFound, topic)
+	}
+	if t.Error.Code() != kafka.ErrNoError {
+		return nil, fmt.Errorf("failed to get metadata of topic %s: %v", topic, t.Error)
+	}
+
+	partitions := make([]int32, 0, len(t.Partitions))
+	for _, p := range t.Partitions {
+		if partition < 0 || p.ID == partition {
+			partitions = append(partitions, p.ID)
+		}
+	}
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("%w: partition %d of topic %s", ErrPartitionNotFound, partition, topic)
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+
+	return partitions, nil
+}
+
+// offsetsForTime returns the first offset at or after t per partition.
+// Partitions without such a message are left out.
+func offsetsForTime(consumer *kafka.Consumer, topic string, partitions []int32, t time.Time) (map[int32]int64, error) {
+	query := make([]kafka.TopicPartition, 0, len(partitions))
+	for _, partition := range partitions {
+		query = append(query, kafka.TopicPartition{Topic: &topic, Partition: partition, Offset: kafka.Offset(t.UnixMilli())})
+	}
+
+	found, err := consumer.OffsetsForTimes(query, readerTimeoutMs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up offsets for time: %w", err)
+	}
+
+	offsets := make(map[int32]int64, len(found))
+	for _, tp := range found {
+		if tp.Error == nil && tp.Offset >= 0 {
+			offsets[tp.Partition] = int64(tp.Offset)
+		}
+	}
+	return offsets, nil
+}
+
+// readRanges reads the ranges and passes each message to visit until every
+// range is read, visit returns false, ctx is done or the deadline passes. A
+// zero deadline never passes. It reports whether reading stopped early.
+func readRanges(ctx context.Context, consumer *kafka.Consumer, topic string, ranges []offsetRange, deadline time.Time, visit func(*kafka.Message) bool) (bool, error) {
+	if len(ranges) == 0 {
+		return false, nil
+	}
+
+	ends := make(map[int32]int64, len(ranges))
+	assignment := make([]kafka.TopicPartition, 0, len(ranges))
+	for _, r := range ranges {
+		ends[r.partition] = r.end
+		assignment = append(assignment, kafka.TopicPartition{Topic: &topic, Partition: r.partition, Offset: kafka.Offset(r.start)})
+	}
+	if err := consumer.Assign(assignment); err != nil {
+		return false, fmt.Errorf("failed to assign partitions: %w", err)
+	}
+	defer consumer.Unassign()
+
+	done := func(partition int32) {
+		if _, ok := ends[partition]; ok {
+			delete(ends, partition)
+			consumer.Pause([]kafka.TopicPartition{{Topic: &topic, Partition: partition}})
+		}
+	}
+
+	for len(ends) > 0 {
+		wait := 500 * time.Millisecond
+		if !deadline.IsZero() {
+			wait = min(time.Until(deadline), wait)
+		}
+		if wait <= 0 || ctx.Err() != nil {
+			return true, nil
+		}
+
+		switch e := consumer.Poll(int(wait.Milliseconds())).(type) {
+		case *kafka.Message:
+			partition := e.TopicPartition.Partition
+			end, ok := ends[partition]
+			if !ok {
+				continue
+			}
+			if int64(e.TopicPartition.Offset) >= end {
+				done(partition)
+				continue
+			}
+			if !visit(e) {
+				return true, nil
+			}
+			if int64(e.TopicPartition.Offset) >= end-1 {
+				done(partition)
+			}
+		case kafka.PartitionEOF:
+			// Transaction markers and compaction can leave the last offsets empty
+			done(e.Partition)
+		case kafka.Error:
+			if e.IsFatal() {
+				return false, fmt.Errorf("consumer failed: %w", e)
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// messageTime is the message timestamp, zero when it has none
+func messageTime(message domain.KafkaMessage) time.Time {
+	if message.Timestamp == nil {
+		return time.Time{}
+	}
+	return *message.Timestamp
+}
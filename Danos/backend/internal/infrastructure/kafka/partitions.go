@@ -0,0 +1,226 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+// GetTopicPartitions returns leader, replica and watermark details for every
+// partition of a topic
+func (m *ClusterManager) GetTopicPartitions(topicName string) ([]domain.KafkaPartitionDetail, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+
+	metadata, err := m.adminClient.GetMetadata(&topicName, false, 3000)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topic metadata: %w", err)
+	}
+
+	topic, exists := metadata.Topics[topicName]
+	if !exists || topic.Error.Code() == kafka.ErrUnknownTopicOrPart {
+		return nil, fmt.Errorf("topic %s not found", topicName)
+	}
+	if topic.Error.Code() != kafka.ErrNoError {
+		return nil, fmt.Errorf("topic %s has error: %v", topicName, topic.Error)
+	}
+
+	partitions := make([]kafka.TopicPartition, 0, len(topic.Partitions))
+	var topicsSet kadm.TopicsSet
+	for _, partition := range topic.Partitions {
+		partitions = append(partitions, kafka.TopicPartition{Topic: &topicName, Partition: partition.ID})
+		topicsSet.Add(topicName, partition.ID)
+	}
+
+	lows, err := m.listOffsets(partitions, kafka.EarliestOffsetSpec)
+	if err != nil {
+		log.Printf("Error listing low watermarks for %s: %v", topicName, err)
+	}
+	highs, err := m.listOffsets(partitions, kafka.LatestOffsetSpec)
+	if err != nil {
+		log.Printf("Error listing high watermarks for %s: %v", topicName, err)
+	}
+	sizes := m.getReplicaLogSizes(topicsSet)
+
+	liveBrokers := liveBrokerIDs(metadata)
+	details := make([]domain.KafkaPartitionDetail, 0, len(topic.Partitions))
+	for _, partition := range topic.Partitions {
+		detail := partitionDetail(topicName, partition, liveBrokers)
+		detail.LowWatermark = lows[partitionKey(topicName, partition.ID)]
+		detail.HighWatermark = highs[partitionKey(topicName, partition.ID)]
+		detail.ReplicaSizes = sizes[partitionKey(topicName, partition.ID)]
+		details = append(details, detail)
+	}
+
+	sort.Slice(details, func(i, j int) bool {
+		return details[i].Partition < details[j].Partition
+	})
+
+	return details, nil
+}
+
+// GetBrokerPlacement returns leader and replica counts per broker across all
+// topics, including internal ones, to show placement skew
+func (m *ClusterManager) GetBrokerPlacement() (*domain.KafkaPlacementView, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+
+	metadata, err := m.adminClient.GetMetadata(nil, true, 3000)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster metadata: %w", err)
+	}
+
+	return buildPlacementView(metadata), nil
+}
+
+func buildPlacementView(metadata *kafka.Metadata) *domain.KafkaPlacementView {
+	view := &domain.KafkaPlacementView{}
+	brokers := make(map[int32]*domain.KafkaBrokerPlacement)
+	for _, broker := range metadata.Brokers {
+		brokers[broker.ID] = &domain.KafkaBrokerPlacement{
+			BrokerID: broker.ID,
+			Host:     broker.Host,
+			Online:   true,
+		}
+	}
+
+	// Replicas may live on brokers that are currently down
+	placement := func(id int32) *domain.KafkaBrokerPlacement {
+		if _, exists := brokers[id]; !exists {
+			brokers[id] = &domain.KafkaBrokerPlacement{BrokerID: id}
+		}
+		return brokers[id]
+	}
+
+	liveBrokers := liveBrokerIDs(metadata)
+	for topicName, topic := range metadata.Topics {
+		if topic.Error.Code() != kafka.ErrNoError {
+			continue
+		}
+		for _, partition := range topic.Partitions {
+			detail := partitionDetail(topicName, partition, liveBrokers)
+			view.TotalPartitions++
+
+			if detail.UnderReplicated {
+				view.UnderReplicated++
+			}
+			if detail.Offline {
+				view.OfflinePartitions++
+			} else {
+				placement(detail.Leader).Leaders++
+				if !detail.IsPreferredLeader {
+					view.NonPreferred++
+				}
+			}
+			if detail.PreferredLeader >= 0 {
+				placement(detail.PreferredLeader).PreferredLeaders++
+			}
+
+			inSync := make(map[int32]bool, len(detail.ISR))
+			for _, id := range detail.ISR {
+				inSync[id] = true
+			}
+			for _, id := range detail.Replicas {
+				placement(id).Replicas++
+				if !inSync[id] {
+					placement(id).OutOfSyncReplicas++
+				}
+			}
+		}
+	}
+
+	view.Brokers = make([]domain.KafkaBrokerPlacement, 0, len(brokers))
+	var totalLeaders, totalReplicas int
+	for _, broker := range brokers {
+		totalLeaders += broker.Leaders
+		totalReplicas += broker.Replicas
+	}
+
+	onlineCount := len(metadata.Brokers)
+	for _, broker := range brokers {
+		if broker.Online && onlineCount > 0 {
+			broker.LeaderSkew = skewPercent(broker.Leaders, totalLeaders, onlineCount)
+			broker.ReplicaSkew = skewPercent(broker.Replicas, totalReplicas, onlineCount)
+		}
+		view.Brokers = append(view.Brokers, *broker)
+	}
+
+	sort.Slice(view.Brokers, func(i, j int) bool {
+		return view.Brokers[i].BrokerID < view.Brokers[j].BrokerID
+	})
+
+	return view
+}
+
+// partitionDetail describes a partition from metadata. Replicas on brokers
+// missing from the metadata broker list are reported offline.
+func partitionDetail(topicName string, partition kafka.PartitionMetadata, liveBrokers map[int32]bool) domain.KafkaPartitionDetail {
+	detail := domain.KafkaPartitionDetail{
+		Topic:           topicName,
+		Partition:       partition.ID,
+		Leader:          partition.Leader,
+		PreferredLeader: -1,
+		Replicas:        partition.Replicas,
+		ISR:             partition.Isrs,
+		OfflineReplicas: make([]int32, 0),
+		UnderReplicated: len(partition.Replicas) > len(partition.Isrs),
+		Offline:         partition.Leader == -1,
+	}
+
+	if len(partition.Replicas) > 0 {
+		detail.PreferredLeader = partition.Replicas[0]
+		detail.IsPreferredLeader = partition.Leader == partition.Replicas[0]
+	}
+
+	for _, id := range partition.Replicas {
+		if !liveBrokers[id] {
+			detail.OfflineReplicas = append(detail.OfflineReplicas, id)
+		}
+	}
+
+	return detail
+}
+
+func liveBrokerIDs(metadata *kafka.Metadata) map[int32]bool {
+	live := make(map[int32]bool, len(metadata.Brokers))
+	for _, broker := range metadata.Brokers {
+		live[broker.ID] = true
+	}
+	return live
+}
+
+// skewPercent returns how far count is from the per-broker mean, in percent
+func skewPercent(count, total, brokers int) float64 {
+	mean := float64(total) / float64(brokers)
+	if mean == 0 {
+		return 0
+	}
+	return (float64(count) - mean) / mean * 100
+}
+
+// minISR returns the ISR size of the least replicated partition of a topic
+func minISR(topic kafka.TopicMetadata) int {
+	if len(topic.Partitions) == 0 {
+		return 0
+	}
+
+	smallest := len(topic.Partitions[0].Isrs)
+	for _, partition := range topic.Partitions[1:] {
+		if len(partition.Isrs) < smallest {
+			smallest = len(partition.Isrs)
+		}
+	}
+	return smallest
+}
@@ -0,0 +1,245 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// The KRaft metadata log, replicated by the controller quorum
+const metadataTopic = "__cluster_metadata"
+
+// A quorum replica that has not fetched or caught up for longer is unhealthy.
+// Brokers fetch the metadata log continuously and time out after 9 seconds
+// by default.
+const quorumFetchTimeout = 10 * time.Second
+
+// GetQuorum describes the KRaft controller quorum
+func (m *ClusterManager) GetQuorum() (*domain.KafkaQuorum, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+	if m.extClient == nil {
+		return nil, m.extAdminUnavailable("describing the quorum")
+	}
+
+	switch m.clusterMode() {
+	case "kraft":
+		return m.describeQuorum()
+	case "zookeeper":
+		return nil, fmt.Errorf("kafka cluster %s runs in ZooKeeper mode", m.name)
+	default:
+		return nil, fmt.Errorf("failed to determine the mode of kafka cluster %s", m.name)
+	}
+}
+
+// clusterMode returns kraft when a broker advertises a finalized
+// metadata.version feature, which only KRaft clusters have, and zookeeper
+// otherwise. Empty means no broker answered.
+func (m *ClusterManager) clusterMode() string {
+	m.versionsMu.Lock()
+	defer m.versionsMu.Unlock()
+
+	if m.mode != "" || m.extAdmin == nil {
+		return m.mode
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 3*time.Second)
+	defer cancel()
+
+	versions, err := m.extAdmin.ApiVersions(ctx)
+	if err != nil {
+		log.Printf("Error getting broker api versions: %v", err)
+	}
+
+	answered, kraft := false, false
+	versions.Each(func(v kadm.BrokerApiVersions) {
+		if v.Err != nil {
+			return
+		}
+		answered = true
+		for _, feature := range v.Raw().FinalizedFeatures {
+			if feature.Name == "metadata.version" {
+				kraft = true
+			}
+		}
+	})
+
+	switch {
+	case kraft:
+		m.mode = "kraft"
+	case answered:
+		m.mode = "zookeeper"
+	}
+	return m.mode
+}
+
+// describeQuorum asks the active controller, through any broker, for the
+// state of the metadata log replicas
+func (m *ClusterManager) describeQuorum() (*domain.KafkaQuorum, error) {
+	if m.extClient == nil {
+		return nil, m.extAdminUnavailable("describing the quorum")
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 3*time.Second)
+	defer cancel()
+
+	partition := kmsg.NewDescribeQuorumRequestTopicPartition()
+	partition.Partition = 0
+	topic := kmsg.NewDescribeQuorumRequestTopic()
+	topic.Topic = metadataTopic
+	topic.Partitions = append(topic.Partitions, partition)
+	req := kmsg.NewPtrDescribeQuorumRequest()
+	req.Topics = append(req.Topics, topic)
+
+	resp, err := req.RequestWith(ctx, m.extClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe quorum: %w", err)
+	}
+	if err := kerr.ErrorForCode(resp.ErrorCode); err != nil {
+		return nil, fmt.Errorf("failed to describe quorum: %w", err)
+	}
+	if len(resp.Topics) == 0 || len(resp.Topics[0].Partitions) == 0 {
+		return nil, fmt.Errorf("quorum description is empty")
+	}
+
+	p := resp.Topics[0].Partitions[0]
+	if err := kerr.ErrorForCode(p.ErrorCode); err != nil {
+		return nil, fmt.Errorf("failed to describe quorum: %w", err)
+	}
+
+	// Fetch times are only reported from version 1
+	timed := resp.Version >= 1
+	now := time.Now()
+
+	quorum := &domain.KafkaQuorum{
+		LeaderID:      p.LeaderID,
+		LeaderEpoch:   p.LeaderEpoch,
+		HighWatermark: p.HighWatermark,
+		Voters:        make([]domain.KafkaQuorumReplica, 0, len(p.CurrentVoters)),
+		Observers:     make([]domain.KafkaQuorumReplica, 0, len(p.Observers)),
+	}
+	for _, voter := range p.CurrentVoters {
+		role := "follower"
+		if voter.ReplicaID == p.LeaderID {
+			role = "leader"
+		}
+		quorum.Voters = append(quorum.Voters, quorumReplica(voter, role, p.HighWatermark, timed, now))
+	}
+	for _, observer := range p.Observers {
+		quorum.Observers = append(quorum.Observers, quorumReplica(observer, "observer", p.HighWatermark, timed, now))
+	}
+
+	sort.Slice(quorum.Voters, func(i, j int) bool { return quorum.Voters[i].ReplicaID < quorum.Voters[j].ReplicaID })
+	sort.Slice(quorum.Observers, func(i, j int) bool { return quorum.Observers[i].ReplicaID < quorum.Observers[j].ReplicaID })
+
+	return quorum, nil
+}
+
+func quorumReplica(state kmsg.DescribeQuorumResponseTopicPartitionReplicaState, role string, highWatermark int64, timed bool, now time.Time) domain.KafkaQuorumReplica {
+	replica := domain.KafkaQuorumReplica{
+		ReplicaID:    state.ReplicaID,
+		Role:         role,
+		Status:       "ok",
+		LogEndOffset: state.LogEndOffset,
+		Lag:          -1,
+	}
+	if state.LogEndOffset >= 0 {
+		replica.Lag = max(highWatermark-state.LogEndOffset, 0)
+	}
+	if state.LastFetchTimestamp >= 0 {
+		at := time.UnixMilli(state.LastFetchTimestamp)
+		age := now.Sub(at).Seconds()
+		replica.LastFetch = &at
+		replica.LastFetchSeconds = &age
+	}
+	if state.LastCaughtUpTimestamp >= 0 {
+		at := time.UnixMilli(state.LastCaughtUpTimestamp)
+		replica.LastCaughtUp = &at
+	}
+
+	if role == "leader" || !timed {
+		return replica
+	}
+	switch {
+	case replica.LastFetch == nil || now.Sub(*replica.LastFetch) > quorumFetchTimeout:
+		replica.Status = "unreachable"
+	case replica.LastCaughtUp == nil || now.Sub(*replica.LastCaughtUp) > quorumFetchTimeout:
+		replica.Status = "lagging"
+	}
+	return replica
+}
+
+// quorumHealthy reports whether the quorum has a leader and every voter is
+// keeping up with it
+func quorumHealthy(quorum *domain.KafkaQuorum) bool {
+	if quorum.LeaderID < 0 {
+		return false
+	}
+	for _, voter := range quorum.Voters {
+		if voter.Status != "ok" {
+			return false
+		}
+	}
+	return true
+}
+
+// applyQuorum marks the active controller among the brokers and adds their
+// metadata lag. Brokers that fetch the metadata log but are missing from the
+// cluster metadata are registered but fenced.
+func applyQuorum(brokers []domain.KafkaBrokerMetrics, quorum *domain.KafkaQuorum) []domain.KafkaBrokerMetrics {
+	replicas := make(map[int32]domain.KafkaQuorumReplica, len(quorum.Voters)+len(quorum.Observers))
+	for _, voter := range quorum.Voters {
+		replicas[voter.ReplicaID] = voter
+	}
+	for _, observer := range quorum.Observers {
+		replicas[observer.ReplicaID] = observer
+	}
+
+	listed := make(map[int32]bool, len(brokers))
+	for i := range brokers {
+		broker := &brokers[i]
+		id := int32(broker.BrokerID)
+		listed[id] = true
+		broker.IsController = id == quorum.LeaderID
+
+		replica, ok := replicas[id]
+		if !ok {
+			continue
+		}
+		if replica.Lag >= 0 {
+			lag := replica.Lag
+			broker.MetadataLag = &lag
+		}
+		if broker.Status == "offline" && replica.Role == "observer" && replica.Status != "unreachable" {
+			broker.Status = "fenced"
+		}
+	}
+
+	for _, observer := range quorum.Observers {
+		if listed[observer.ReplicaID] || observer.Status == "unreachable" {
+			continue
+		}
+		broker := domain.KafkaBrokerMetrics{BrokerID: int(observer.ReplicaID), Status: "fenced"}
+		if observer.Lag >= 0 {
+			lag := observer.Lag
+			broker.MetadataLag = &lag
+		}
+		brokers = append(brokers, broker)
+	}
+
+	sort.Slice(brokers, func(i, j int) bool {
+		return brokers[i].BrokerID < brokers[j].BrokerID
+	})
+	return brokers
+}
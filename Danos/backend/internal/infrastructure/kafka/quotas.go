@@ -0,0 +1,249 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// Quota keys that can be described and altered
+const (
+	quotaProducerByteRate  = "producer_byte_rate"
+	quotaConsumerByteRate  = "consumer_byte_rate"
+	quotaRequestPercentage = "request_percentage"
+)
+
+// DescribeQuotas returns the client quotas of the entities matching filter.
+// An empty filter returns every user and client ID quota.
+func (m *ClusterManager) DescribeQuotas(filter domain.KafkaQuotaEntity) ([]domain.KafkaClientQuota, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+	if m.extAdmin == nil {
+		return nil, m.extAdminUnavailable("describing client quotas")
+	}
+
+	return m.describeQuotas(quotaComponents(filter))
+}
+
+// AlterQuota sets and removes quotas of a single entity
+func (m *ClusterManager) AlterQuota(req domain.KafkaAlterQuotaRequest) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return fmt.Errorf("kafka admin client not connected")
+	}
+	if m.extAdmin == nil {
+		return m.extAdminUnavailable("altering client quotas")
+	}
+
+	entity, err := toQuotaEntity(req.Entity)
+	if err != nil {
+		return err
+	}
+
+	ops := make([]kadm.AlterClientQuotaOp, 0)
+	for key, value := range map[string]*float64{
+		quotaProducerByteRate:  req.ProducerByteRate,
+		quotaConsumerByteRate:  req.ConsumerByteRate,
+		quotaRequestPercentage: req.RequestPercentage,
+	} {
+		if value == nil {
+			continue
+		}
+		if *value <= 0 {
+			return fmt.Errorf("%s must be positive", key)
+		}
+		ops = append(ops, kadm.AlterClientQuotaOp{Key: key, Value: *value})
+	}
+	for _, key := range req.Remove {
+		if key != quotaProducerByteRate && key != quotaConsumerByteRate && key != quotaRequestPercentage {
+			return fmt.Errorf("invalid quota %q", key)
+		}
+		ops = append(ops, kadm.AlterClientQuotaOp{Key: key, Remove: true})
+	}
+	if len(ops) == 0 {
+		return fmt.Errorf("no quotas to set or remove")
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+	defer cancel()
+
+	results, err := m.extAdmin.AlterClientQuotas(ctx, []kadm.AlterClientQuotaEntry{{Entity: entity, Ops: ops}})
+	if err != nil {
+		return fmt.Errorf("failed to alter client quotas: %w", err)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			if result.ErrMessage != "" {
+				return fmt.Errorf("failed to alter client quotas of %s: %v: %s", result.Entity, result.Err, result.ErrMessage)
+			}
+			return fmt.Errorf("failed to alter client quotas of %s: %v", result.Entity, result.Err)
+		}
+	}
+
+	log.Printf("Client quotas of %s altered successfully", entity)
+	return nil
+}
+
+func (m *ClusterManager) describeQuotas(components []kadm.DescribeClientQuotaComponent) ([]domain.KafkaClientQuota, error) {
+	ctx, cancel := context.WithTimeout(m.ctx, 5*time.Second)
+	defer cancel()
+
+	described, err := m.extAdmin.DescribeClientQuotas(ctx, false, components)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe client quotas: %w", err)
+	}
+
+	quotas := make([]domain.KafkaClientQuota, 0, len(described))
+	for _, d := range described {
+		entity, ok := fromQuotaEntity(d.Entity)
+		if !ok {
+			continue
+		}
+
+		quota := domain.KafkaClientQuota{Entity: entity}
+		for _, value := range d.Values {
+			v := value.Value
+			switch value.Key {
+			case quotaProducerByteRate:
+				quota.ProducerByteRate = &v
+			case quotaConsumerByteRate:
+				quota.ConsumerByteRate = &v
+			case quotaRequestPercentage:
+				quota.RequestPercentage = &v
+			}
+		}
+		quotas = append(quotas, quota)
+	}
+
+	sort.Slice(quotas, func(i, j int) bool {
+		a, b := quotas[i].Entity, quotas[j].Entity
+		if a.User != b.User {
+			return a.User < b.User
+		}
+		return a.ClientID < b.ClientID
+	})
+
+	return quotas, nil
+}
+
+// applyGroupQuotas attaches to each group the client ID quotas that apply to
+// its members. Group descriptions carry no principal, so user quotas cannot
+// be matched.
+func (m *ClusterManager) applyGroupQuotas(groups []domain.KafkaConsumerMetrics) {
+	if m.extAdmin == nil || len(groups) == 0 {
+		return
+	}
+
+	quotas, err := m.describeQuotas(nil)
+	if err != nil {
+		log.Printf("Error describing client quotas: %v", err)
+		return
+	}
+
+	for i := range groups {
+		groups[i].Quotas = groupQuotas(groups[i].ClientIDs, quotas)
+	}
+}
+
+// groupQuotas returns the quotas whose client ID is one of clientIDs or the
+// default client ID
+func groupQuotas(clientIDs []string, quotas []domain.KafkaClientQuota) []domain.KafkaClientQuota {
+	ids := make(map[string]bool, len(clientIDs))
+	for _, id := range clientIDs {
+		ids[id] = true
+	}
+
+	matched := make([]domain.KafkaClientQuota, 0)
+	for _, quota := range quotas {
+		if quota.Entity.DefaultClientID || (quota.Entity.ClientID != "" && ids[quota.Entity.ClientID]) {
+			matched = append(matched, quota)
+		}
+	}
+	return matched
+}
+
+func quotaComponents(filter domain.KafkaQuotaEntity) []kadm.DescribeClientQuotaComponent {
+	components := make([]kadm.DescribeClientQuotaComponent, 0, 2)
+
+	switch {
+	case filter.DefaultUser:
+		components = append(components, kadm.DescribeClientQuotaComponent{Type: "user", MatchType: kmsg.QuotasMatchTypeDefault})
+	case filter.User != "":
+		user := filter.User
+		components = append(components, kadm.DescribeClientQuotaComponent{Type: "user", MatchName: &user, MatchType: kmsg.QuotasMatchTypeExact})
+	}
+
+	switch {
+	case filter.DefaultClientID:
+		components = append(components, kadm.DescribeClientQuotaComponent{Type: "client-id", MatchType: kmsg.QuotasMatchTypeDefault})
+	case filter.ClientID != "":
+		clientID := filter.ClientID
+		components = append(components, kadm.DescribeClientQuotaComponent{Type: "client-id", MatchName: &clientID, MatchType: kmsg.QuotasMatchTypeExact})
+	}
+
+	return components
+}
+
+func toQuotaEntity(entity domain.KafkaQuotaEntity) (kadm.ClientQuotaEntity, error) {
+	if (entity.User != "" && entity.DefaultUser) || (entity.ClientID != "" && entity.DefaultClientID) {
+		return nil, fmt.Errorf("an entity is either named or the default")
+	}
+
+	components := make(kadm.ClientQuotaEntity, 0, 2)
+	switch {
+	case entity.DefaultUser:
+		components = append(components, kadm.ClientQuotaEntityComponent{Type: "user"})
+	case entity.User != "":
+		user := entity.User
+		components = append(components, kadm.ClientQuotaEntityComponent{Type: "user", Name: &user})
+	}
+	switch {
+	case entity.DefaultClientID:
+		components = append(components, kadm.ClientQuotaEntityComponent{Type: "client-id"})
+	case entity.ClientID != "":
+		clientID := entity.ClientID
+		components = append(components, kadm.ClientQuotaEntityComponent{Type: "client-id", Name: &clientID})
+	}
+
+	if len(components) == 0 {
+		return nil, fmt.Errorf("a user or client_id entity is required")
+	}
+	return components, nil
+}
+
+// fromQuotaEntity converts a described entity. Entities with components other
+// than user and client ID, such as IP quotas, are not supported.
+func fromQuotaEntity(components kadm.ClientQuotaEntity) (domain.KafkaQuotaEntity, bool) {
+	var entity domain.KafkaQuotaEntity
+	for _, component := range components {
+		switch component.Type {
+		case "user":
+			if component.Name == nil {
+				entity.DefaultUser = true
+			} else {
+				entity.User = *component.Name
+			}
+		case "client-id":
+			if component.Name == nil {
+				entity.DefaultClientID = true
+			} else {
+				entity.ClientID = *component.Name
+			}
+		default:
+			return entity, false
+		}
+	}
+	return entity, true
+}
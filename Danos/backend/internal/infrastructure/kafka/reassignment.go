@@ -0,0 +1,847 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+// ErrInvalidReassignment is returned for reassignment requests and plans that
+// do not match the cluster
+var ErrInvalidReassignment = errors.New("invalid reassignment")
+
+// partitionAssignment is the current and planned replica list of a partition
+type partitionAssignment struct {
+	topic     string
+	partition int32
+	current   []int32
+	target    []int32
+}
+
+// PlanReassignment generates a balanced replica assignment from the current
+// cluster metadata. Nothing is changed on the cluster.
+func (m *ClusterManager) PlanReassignment(req domain.KafkaReassignmentRequest) (*domain.KafkaReassignmentPlan, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+
+	metadata, err := m.adminClient.GetMetadata(nil, true, 3000)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster metadata: %w", err)
+	}
+
+	decommission := make(map[int32]bool, len(req.Decommission))
+	for _, id := range req.Decommission {
+		decommission[id] = true
+	}
+
+	live := liveBrokers(metadata)
+	candidates := req.Brokers
+	if len(candidates) == 0 {
+		for _, broker := range metadata.Brokers {
+			candidates = append(candidates, broker.ID)
+		}
+	}
+	for _, id := range candidates {
+		if !live[id] {
+			return nil, fmt.Errorf("%w: broker %d not found", ErrInvalidReassignment, id)
+		}
+	}
+	brokers := make([]int32, 0, len(candidates))
+	for _, id := range candidates {
+		if !decommission[id] {
+			brokers = append(brokers, id)
+		}
+	}
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("%w: no target brokers left", ErrInvalidReassignment)
+	}
+	sort.Slice(brokers, func(i, j int) bool { return brokers[i] < brokers[j] })
+
+	topics := make(map[string]bool, len(req.Topics))
+	for _, topic := range req.Topics {
+		if _, exists := metadata.Topics[topic]; !exists {
+			return nil, fmt.Errorf("%w: topic %s not found", ErrInvalidReassignment, topic)
+		}
+		topics[topic] = true
+	}
+
+	assignments := make([]*partitionAssignment, 0)
+	for topicName, topic := range metadata.Topics {
+		if len(topics) > 0 && !topics[topicName] {
+			continue
+		}
+		if topic.Error.Code() != kafka.ErrNoError {
+			continue
+		}
+		for _, partition := range topic.Partitions {
+			// Without an explicit topic list a decommission only touches
+			// partitions that have a replica on a retired broker
+			if len(topics) == 0 && len(decommission) > 0 && !containsAny(partition.Replicas, decommission) {
+				continue
+			}
+			assignments = append(assignments, &partitionAssignment{
+				topic:     topicName,
+				partition: partition.ID,
+				current:   partition.Replicas,
+			})
+		}
+	}
+
+	return planReassignment(assignments, brokers)
+}
+
+// planReassignment spreads the replicas of the given partitions evenly over
+// brokers. Replicas already on a target broker stay where they are when the
+// balance allows it, and the replication factor is preserved.
+func planReassignment(assignments []*partitionAssignment, brokers []int32) (*domain.KafkaReassignmentPlan, error) {
+	sort.Slice(assignments, func(i, j int) bool {
+		if assignments[i].topic != assignments[j].topic {
+			return assignments[i].topic < assignments[j].topic
+		}
+		return assignments[i].partition < assignments[j].partition
+	})
+
+	targetSet := make(map[int32]bool, len(brokers))
+	for _, id := range brokers {
+		targetSet[id] = true
+	}
+
+	// Keep replicas that already sit on a target broker
+	load := make(map[int32]int, len(brokers))
+	for _, id := range brokers {
+		load[id] = 0
+	}
+	for _, a := range assignments {
+		if len(a.current) > len(brokers) {
+			return nil, fmt.Errorf("%w: %s/%d has replication factor %d but only %d target brokers",
+				ErrInvalidReassignment, a.topic, a.partition, len(a.current), len(brokers))
+		}
+		a.target = make([]int32, 0, len(a.current))
+		for _, id := range a.current {
+			if targetSet[id] {
+				a.target = append(a.target, id)
+				load[id]++
+			}
+		}
+	}
+
+	// Fill missing replicas on the least loaded brokers
+	for _, a := range assignments {
+		for len(a.target) < len(a.current) {
+			id := leastLoaded(load, brokers, a.target)
+			a.target = append(a.target, id)
+			load[id]++
+		}
+	}
+
+	// Move replicas from the most to the least loaded broker until the
+	// difference is at most one replica
+	for {
+		over := mostLoaded(load, brokers)
+		under := leastLoaded(load, brokers, nil)
+		if load[over]-load[under] <= 1 {
+			break
+		}
+
+		moved := movableAssignment(assignments, over, under)
+		if moved == nil {
+			break
+		}
+		for i, id := range moved.target {
+			if id == over {
+				moved.target[i] = under
+			}
+		}
+		load[over]--
+		load[under]++
+	}
+
+	balancePreferredLeaders(assignments, brokers)
+
+	plan := &domain.KafkaReassignmentPlan{
+		Brokers:    brokers,
+		Partitions: make([]domain.KafkaPartitionMove, 0),
+		BrokerLoad: load,
+	}
+	for _, a := range assignments {
+		if equalReplicas(a.current, a.target) {
+			continue
+		}
+		for _, id := range a.target {
+			if !containsBroker(a.current, id) {
+				plan.ReplicaMoves++
+			}
+		}
+		if len(a.current) == 0 || a.current[0] != a.target[0] {
+			plan.LeaderMoves++
+		}
+		plan.Partitions = append(plan.Partitions, domain.KafkaPartitionMove{
+			Topic:           a.topic,
+			Partition:       a.partition,
+			CurrentReplicas: a.current,
+			TargetReplicas:  a.target,
+		})
+	}
+
+	return plan, nil
+}
+
+// movableAssignment finds a partition with a replica on from that can move to
+// to, preferring replicas the plan itself added so fewer replicas move
+func movableAssignment(assignments []*partitionAssignment, from, to int32) *partitionAssignment {
+	var fallback *partitionAssignment
+	for _, a := range assignments {
+		if !containsBroker(a.target, from) || containsBroker(a.target, to) {
+			continue
+		}
+		if !containsBroker(a.current, from) {
+			return a
+		}
+		if fallback == nil {
+			fallback = a
+		}
+	}
+	return fallback
+}
+
+// balancePreferredLeaders reorders replicas so every broker is the first
+// (preferred) replica of a similar number of partitions
+func balancePreferredLeaders(assignments []*partitionAssignment, brokers []int32) {
+	leaders := make(map[int32]int, len(brokers))
+	for _, a := range assignments {
+		if len(a.target) > 0 {
+			leaders[a.target[0]]++
+		}
+	}
+
+	maxLeaders := (len(assignments) + len(brokers) - 1) / len(brokers)
+	for _, a := range assignments {
+		if len(a.target) < 2 {
+			continue
+		}
+		first := a.target[0]
+		if leaders[first] <= maxLeaders {
+			continue
+		}
+
+		best := 0
+		for i := 1; i < len(a.target); i++ {
+			if leaders[a.target[i]] < leaders[a.target[best]] {
+				best = i
+			}
+		}
+		if best == 0 || leaders[a.target[best]]+1 >= leaders[first] {
+			continue
+		}
+
+		chosen := a.target[best]
+		copy(a.target[1:best+1], a.target[:best])
+		a.target[0] = chosen
+		leaders[first]--
+		leaders[chosen]++
+	}
+}
+
+// leastLoaded returns the broker with the fewest replicas that is not in exclude
+func leastLoaded(load map[int32]int, brokers []int32, exclude []int32) int32 {
+	best := int32(-1)
+	for _, id := range brokers {
+		if containsBroker(exclude, id) {
+			continue
+		}
+		if best == -1 || load[id] < load[best] {
+			best = id
+		}
+	}
+	return best
+}
+
+func mostLoaded(load map[int32]int, brokers []int32) int32 {
+	best := brokers[0]
+	for _, id := range brokers[1:] {
+		if load[id] > load[best] {
+			best = id
+		}
+	}
+	return best
+}
+
+func liveBrokers(metadata *kafka.Metadata) map[int32]bool {
+	brokers := make(map[int32]bool, len(metadata.Brokers))
+	for _, broker := range metadata.Brokers {
+		brokers[broker.ID] = true
+	}
+	return brokers
+}
+
+func containsBroker(ids []int32, id int32) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(ids []int32, set map[int32]bool) bool {
+	for _, id := range ids {
+		if set[id] {
+			return true
+		}
+	}
+	return false
+}
+
+func equalReplicas(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ExecuteReassignment submits a plan to the cluster, optionally throttling
+// replication, and tracks its progress in the background
+func (m *ClusterManager) ExecuteReassignment(req domain.KafkaReassignmentExecuteRequest) (*domain.KafkaReassignmentStatus, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+	if m.extAdmin == nil {
+		return nil, m.extAdminUnavailable("partition reassignment")
+	}
+	if len(req.Plan.Partitions) == 0 {
+		return nil, fmt.Errorf("plan has no partitions to move")
+	}
+
+	m.reassignMu.Lock()
+	defer m.reassignMu.Unlock()
+
+	if m.reassignment != nil && m.reassignment.State == "running" {
+		return nil, fmt.Errorf("a reassignment is already running")
+	}
+
+	// The plan may be stale or edited by hand, it must still match the cluster
+	metadata, err := m.adminClient.GetMetadata(nil, true, 3000)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster metadata: %w", err)
+	}
+	if err := validateReassignmentPlan(metadata, req.Plan); err != nil {
+		return nil, err
+	}
+
+	var restore []kafka.ConfigResource
+	if req.ThrottleBytesPerSec > 0 {
+		if restore, err = m.setReplicationThrottle(m.ctx, req.Plan, req.ThrottleBytesPerSec); err != nil {
+			return nil, fmt.Errorf("failed to set replication throttle: %w", err)
+		}
+	}
+
+	var assignReq kadm.AlterPartitionAssignmentsReq
+	for _, move := range req.Plan.Partitions {
+		assignReq.Assign(move.Topic, move.Partition, move.TargetReplicas)
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+	defer cancel()
+
+	responses, err := m.extAdmin.AlterPartitionAssignments(ctx, assignReq)
+	if err != nil {
+		m.restoreReplicationThrottle(m.ctx, restore)
+		return nil, fmt.Errorf("failed to alter partition assignments: %w", err)
+	}
+
+	status := &domain.KafkaReassignmentStatus{
+		State:               "running",
+		StartedAt:           time.Now(),
+		ThrottleBytesPerSec: req.ThrottleBytesPerSec,
+		Partitions:          make([]domain.KafkaReassignmentProgress, 0, len(req.Plan.Partitions)),
+	}
+
+	rejected := 0
+	for _, move := range req.Plan.Partitions {
+		if response, ok := responses[move.Topic][move.Partition]; ok && response.Err != nil {
+			rejected++
+			if status.Error == "" {
+				status.Error = fmt.Sprintf("%s/%d: %v %s", move.Topic, move.Partition, response.Err, response.ErrMessage)
+			}
+			continue
+		}
+		status.Partitions = append(status.Partitions, domain.KafkaReassignmentProgress{
+			Topic:          move.Topic,
+			Partition:      move.Partition,
+			TargetReplicas: move.TargetReplicas,
+			Replicas:       move.CurrentReplicas,
+		})
+	}
+	status.TotalPartitions = len(status.Partitions)
+
+	if rejected > 0 {
+		status.Error = fmt.Sprintf("%d partitions rejected, first: %s", rejected, status.Error)
+	}
+	if status.TotalPartitions == 0 {
+		m.restoreReplicationThrottle(m.ctx, restore)
+		return nil, fmt.Errorf("all partitions were rejected: %s", status.Error)
+	}
+
+	m.reassignment = status
+	m.reassignThrottle = restore
+	go m.trackReassignment()
+
+	log.Printf("Started reassignment of %d partitions", status.TotalPartitions)
+	return copyReassignmentStatus(status), nil
+}
+
+// validateReassignmentPlan checks a plan against the live metadata: every
+// partition must still have the replicas the plan was made from, and every
+// target replica must be on a live broker
+func validateReassignmentPlan(metadata *kafka.Metadata, plan domain.KafkaReassignmentPlan) error {
+	brokers := liveBrokers(metadata)
+	seen := make(map[string]bool, len(plan.Partitions))
+
+	for _, move := range plan.Partitions {
+		key := partitionKey(move.Topic, move.Partition)
+		if seen[key] {
+			return fmt.Errorf("%w: %s is in the plan more than once", ErrInvalidReassignment, key)
+		}
+		seen[key] = true
+
+		topic, exists := metadata.Topics[move.Topic]
+		if !exists || topic.Error.Code() != kafka.ErrNoError {
+			return fmt.Errorf("%w: topic %s not found", ErrInvalidReassignment, move.Topic)
+		}
+		var current []int32
+		found := false
+		for _, partition := range topic.Partitions {
+			if partition.ID == move.Partition {
+				current, found = partition.Replicas, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: partition %s not found", ErrInvalidReassignment, key)
+		}
+		if !equalReplicas(current, move.CurrentReplicas) {
+			return fmt.Errorf("%w: replicas of %s are %v, not %v as planned, plan again",
+				ErrInvalidReassignment, key, current, move.CurrentReplicas)
+		}
+
+		if len(move.TargetReplicas) == 0 {
+			return fmt.Errorf("%w: %s has no target replicas", ErrInvalidReassignment, key)
+		}
+		for i, id := range move.TargetReplicas {
+			if !brokers[id] {
+				return fmt.Errorf("%w: target broker %d of %s not found", ErrInvalidReassignment, id, key)
+			}
+			if containsBroker(move.TargetReplicas[:i], id) {
+				return fmt.Errorf("%w: %s lists broker %d twice", ErrInvalidReassignment, key, id)
+			}
+		}
+	}
+	return nil
+}
+
+// GetReassignmentStatus returns the progress of the last started reassignment
+func (m *ClusterManager) GetReassignmentStatus() (*domain.KafkaReassignmentStatus, error) {
+	m.reassignMu.Lock()
+	defer m.reassignMu.Unlock()
+
+	if m.reassignment == nil {
+		return nil, fmt.Errorf("no reassignment has been started")
+	}
+	return copyReassignmentStatus(m.reassignment), nil
+}
+
+// trackReassignment polls the cluster until every partition of the running
+// reassignment is done, then removes the replication throttle. Close removes
+// the throttle of a reassignment still running.
+func (m *ClusterManager) trackReassignment() {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			m.finishReassignment("failed", "kafka connection closed")
+			return
+		case <-ticker.C:
+		}
+
+		done, err := m.updateReassignmentProgress()
+		if err != nil {
+			log.Printf("Error checking reassignment progress: %v", err)
+			if !m.IsConnected() {
+				m.finishReassignment("failed", "kafka connection closed")
+				return
+			}
+			continue
+		}
+
+		if done {
+			m.mu.RLock()
+			if m.connected {
+				m.releaseReplicationThrottle(m.ctx)
+			}
+			m.mu.RUnlock()
+			m.finishReassignment("completed", "")
+			log.Println("Partition reassignment completed")
+			return
+		}
+	}
+}
+
+// updateReassignmentProgress refreshes the running status and reports whether
+// every partition has finished moving
+func (m *ClusterManager) updateReassignmentProgress() (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.extAdmin == nil {
+		return false, fmt.Errorf("kafka admin client not connected")
+	}
+
+	m.reassignMu.Lock()
+	var topicsSet kadm.TopicsSet
+	for _, progress := range m.reassignment.Partitions {
+		topicsSet.Add(progress.Topic, progress.Partition)
+	}
+	m.reassignMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(m.ctx, 5*time.Second)
+	defer cancel()
+
+	ongoing, err := m.extAdmin.ListPartitionReassignments(ctx, topicsSet)
+	if err != nil {
+		return false, err
+	}
+
+	m.reassignMu.Lock()
+	defer m.reassignMu.Unlock()
+
+	status := m.reassignment
+	status.DonePartitions = 0
+	for i := range status.Partitions {
+		progress := &status.Partitions[i]
+		if current, ok := ongoing[progress.Topic][progress.Partition]; ok {
+			progress.Replicas = current.Replicas
+			progress.AddingReplicas = current.AddingReplicas
+			progress.RemovingReplicas = current.RemovingReplicas
+			progress.Done = false
+			continue
+		}
+		progress.Replicas = progress.TargetReplicas
+		progress.AddingReplicas = nil
+		progress.RemovingReplicas = nil
+		progress.Done = true
+		status.DonePartitions++
+	}
+
+	return status.DonePartitions == status.TotalPartitions, nil
+}
+
+func (m *ClusterManager) finishReassignment(state, errMessage string) {
+	m.reassignMu.Lock()
+	defer m.reassignMu.Unlock()
+
+	now := time.Now()
+	m.reassignment.State = state
+	m.reassignment.CompletedAt = &now
+	if errMessage != "" {
+		m.reassignment.Error = errMessage
+	}
+}
+
+// setReplicationThrottle throttles the moving replicas the same way
+// kafka-reassign-partitions does: a rate on every involved broker, and the
+// leader and follower replica lists on every involved topic. Replica lists
+// already set on a topic are extended. It returns the config changes that
+// restore the replaced values.
+func (m *ClusterManager) setReplicationThrottle(parent context.Context, plan domain.KafkaReassignmentPlan, rate int64) ([]kafka.ConfigResource, error) {
+	brokers := make(map[int32]bool)
+	replicas := make(map[string]map[string][]string) // by topic, then config name
+
+	addReplica := func(topic, name string, partition, id int32) {
+		if replicas[topic] == nil {
+			replicas[topic] = make(map[string][]string)
+		}
+		replicas[topic][name] = append(replicas[topic][name], fmt.Sprintf("%d:%d", partition, id))
+	}
+	for _, move := range plan.Partitions {
+		for _, id := range move.CurrentReplicas {
+			brokers[id] = true
+			addReplica(move.Topic, "leader.replication.throttled.replicas", move.Partition, id)
+		}
+		for _, id := range move.TargetReplicas {
+			brokers[id] = true
+			if !containsBroker(move.CurrentReplicas, id) {
+				addReplica(move.Topic, "follower.replication.throttled.replicas", move.Partition, id)
+			}
+		}
+	}
+
+	wanted := make([]kafka.ConfigResource, 0, len(brokers)+len(replicas))
+	value := strconv.FormatInt(rate, 10)
+	for id := range brokers {
+		wanted = append(wanted, kafka.ConfigResource{
+			Type: kafka.ResourceBroker,
+			Name: strconv.Itoa(int(id)),
+			Config: []kafka.ConfigEntry{
+				{Name: "leader.replication.throttled.rate", Value: value},
+				{Name: "follower.replication.throttled.rate", Value: value},
+			},
+		})
+	}
+	for topic, lists := range replicas {
+		resource := kafka.ConfigResource{Type: kafka.ResourceTopic, Name: topic}
+		for name, list := range lists {
+			resource.Config = append(resource.Config, kafka.ConfigEntry{Name: name, Value: strings.Join(list, ",")})
+		}
+		wanted = append(wanted, resource)
+	}
+
+	current, err := m.describeConfigEntries(parent, wanted)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]kafka.ConfigResource, 0, len(wanted))
+	restore := make([]kafka.ConfigResource, 0, len(wanted))
+	for _, resource := range wanted {
+		change := kafka.ConfigResource{Type: resource.Type, Name: resource.Name}
+		undo := kafka.ConfigResource{Type: resource.Type, Name: resource.Name}
+
+		for _, entry := range resource.Config {
+			previous, explicit := current[configResourceKey(resource.Type, resource.Name)][entry.Name]
+			explicit = explicit && (previous.Source == kafka.ConfigSourceDynamicTopic || previous.Source == kafka.ConfigSourceDynamicBroker)
+
+			if explicit && resource.Type == kafka.ResourceTopic {
+				entry.Value = mergeThrottledReplicas(previous.Value, entry.Value)
+			}
+			if explicit && previous.Value == entry.Value {
+				continue
+			}
+
+			change.Config = append(change.Config, kafka.ConfigEntry{Name: entry.Name, Value: entry.Value, IncrementalOperation: kafka.AlterConfigOpTypeSet})
+			if explicit {
+				undo.Config = append(undo.Config, kafka.ConfigEntry{Name: entry.Name, Value: previous.Value, IncrementalOperation: kafka.AlterConfigOpTypeSet})
+			} else {
+				undo.Config = append(undo.Config, kafka.ConfigEntry{Name: entry.Name, IncrementalOperation: kafka.AlterConfigOpTypeDelete})
+			}
+		}
+
+		if len(change.Config) > 0 {
+			changes = append(changes, change)
+			restore = append(restore, undo)
+		}
+	}
+
+	if err := m.alterConfigs(parent, changes); err != nil {
+		m.restoreReplicationThrottle(parent, restore)
+		return nil, err
+	}
+	return restore, nil
+}
+
+// mergeThrottledReplicas adds partition:broker entries to a throttled
+// replica list. "*" already throttles every replica.
+func mergeThrottledReplicas(existing, added string) string {
+	switch existing {
+	case "*":
+		return existing
+	case "":
+		return added
+	}
+
+	entries := strings.Split(existing, ",")
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		seen[entry] = true
+	}
+	for _, entry := range strings.Split(added, ",") {
+		if !seen[entry] {
+			seen[entry] = true
+			entries = append(entries, entry)
+		}
+	}
+	return strings.Join(entries, ",")
+}
+
+// restoreReplicationThrottle applies the changes returned by
+// setReplicationThrottle
+func (m *ClusterManager) restoreReplicationThrottle(parent context.Context, restore []kafka.ConfigResource) {
+	if len(restore) == 0 {
+		return
+	}
+	if err := m.alterConfigs(parent, restore); err != nil {
+		log.Printf("Error removing replication throttle: %v", err)
+	}
+}
+
+// releaseReplicationThrottle removes the throttle of the running
+// reassignment. mu must be held.
+func (m *ClusterManager) releaseReplicationThrottle(parent context.Context) {
+	m.reassignMu.Lock()
+	restore := m.reassignThrottle
+	m.reassignThrottle = nil
+	m.reassignMu.Unlock()
+
+	m.restoreReplicationThrottle(parent, restore)
+}
+
+func configResourceKey(resourceType kafka.ResourceType, name string) string {
+	return resourceType.String() + "/" + name
+}
+
+// describeConfigEntries describes the given resources, keyed by
+// configResourceKey and then config name
+func (m *ClusterManager) describeConfigEntries(parent context.Context, resources []kafka.ConfigResource) (map[string]map[string]kafka.ConfigEntryResult, error) {
+	entries := make(map[string]map[string]kafka.ConfigEntryResult, len(resources))
+
+	for _, batch := range configBatches(resources) {
+		describe := make([]kafka.ConfigResource, 0, len(batch))
+		for _, resource := range batch {
+			describe = append(describe, kafka.ConfigResource{Type: resource.Type, Name: resource.Name})
+		}
+
+		ctx, cancel := context.WithTimeout(parent, 5*time.Second)
+		results, err := m.adminClient.DescribeConfigs(ctx, describe)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			if result.Error.Code() != kafka.ErrNoError {
+				return nil, fmt.Errorf("%s %s: %v", result.Type, result.Name, result.Error)
+			}
+			entries[configResourceKey(result.Type, result.Name)] = result.Config
+		}
+	}
+	return entries, nil
+}
+
+// alterConfigs incrementally alters the given resources. Every batch is
+// tried, the first error is returned.
+func (m *ClusterManager) alterConfigs(parent context.Context, resources []kafka.ConfigResource) error {
+	var firstErr error
+	for _, batch := range configBatches(resources) {
+		ctx, cancel := context.WithTimeout(parent, 10*time.Second)
+		results, err := m.adminClient.IncrementalAlterConfigs(ctx, batch)
+		cancel()
+		if err == nil {
+			for _, result := range results {
+				if result.Error.Code() != kafka.ErrNoError {
+					err = fmt.Errorf("%s %s: %v", result.Type, result.Name, result.Error)
+					break
+				}
+			}
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// configBatches splits config resources into admin requests. Broker configs
+// are sent to the broker itself, so each broker needs a request of its own.
+func configBatches(resources []kafka.ConfigResource) [][]kafka.ConfigResource {
+	batches := make([][]kafka.ConfigResource, 0)
+	others := make([]kafka.ConfigResource, 0)
+	for _, resource := range resources {
+		if resource.Type == kafka.ResourceBroker {
+			batches = append(batches, []kafka.ConfigResource{resource})
+		} else {
+			others = append(others, resource)
+		}
+	}
+	if len(others) > 0 {
+		batches = append(batches, others)
+	}
+	return batches
+}
+
+func copyReassignmentStatus(status *domain.KafkaReassignmentStatus) *domain.KafkaReassignmentStatus {
+	copied := *status
+	copied.Partitions = append([]domain.KafkaReassignmentProgress(nil), status.Partitions...)
+	return &copied
+}
+
+// ElectPreferredLeaders moves leadership back to the preferred replica of
+// every partition of the given topics (all topics when empty) that is led by
+// another broker
+func (m *ClusterManager) ElectPreferredLeaders(topics []string) ([]domain.KafkaLeaderElectionResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+
+	metadata, err := m.adminClient.GetMetadata(nil, true, 3000)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster metadata: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		wanted[topic] = true
+	}
+
+	partitions := make([]kafka.TopicPartition, 0)
+	for topicName, topic := range metadata.Topics {
+		if len(wanted) > 0 && !wanted[topicName] {
+			continue
+		}
+		for _, partition := range topic.Partitions {
+			if len(partition.Replicas) > 0 && partition.Leader != partition.Replicas[0] {
+				name := topicName
+				partitions = append(partitions, kafka.TopicPartition{Topic: &name, Partition: partition.ID})
+			}
+		}
+	}
+
+	results := make([]domain.KafkaLeaderElectionResult, 0, len(partitions))
+	if len(partitions) == 0 {
+		return results, nil
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+	defer cancel()
+
+	response, err := m.adminClient.ElectLeaders(ctx, kafka.NewElectLeadersRequest(kafka.ElectionTypePreferred, partitions))
+	if err != nil {
+		return nil, fmt.Errorf("failed to elect preferred leaders: %w", err)
+	}
+
+	for _, tp := range response.TopicPartitions {
+		result := domain.KafkaLeaderElectionResult{
+			Topic:     *tp.Topic,
+			Partition: tp.Partition,
+		}
+		if tp.Error != nil {
+			result.Error = tp.Error.Error()
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
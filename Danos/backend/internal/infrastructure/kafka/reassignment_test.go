@@ -0,0 +1,177 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+)
+
+// assignmentsOf builds one assignment per replica list of a topic
+func assignmentsOf(topic string, replicas ...[]int32) []*partitionAssignment {
+	assignments := make([]*partitionAssignment, 0, len(replicas))
+	for i, current := range replicas {
+		assignments = append(assignments, &partitionAssignment{topic: topic, partition: int32(i), current: current})
+	}
+	return assignments
+}
+
+func TestPlanReassignment(t *testing.T) {
+	tests := []struct {
+		name        string
+		assignments []*partitionAssignment
+		brokers     []int32
+		moves       int // replicas placed on a new broker
+		wantErr     bool
+	}{
+		{
+			name:        "balanced cluster stays put",
+			assignments: assignmentsOf("orders", []int32{1, 2}, []int32{2, 3}, []int32{3, 1}),
+			brokers:     []int32{1, 2, 3},
+			moves:       0,
+		},
+		{
+			name:        "new broker takes a share",
+			assignments: assignmentsOf("orders", []int32{1, 2}, []int32{2, 3}, []int32{3, 1}, []int32{1, 2}, []int32{2, 3}, []int32{3, 1}),
+			brokers:     []int32{1, 2, 3, 4},
+			moves:       3,
+		},
+		{
+			name:        "decommissioned broker is emptied",
+			assignments: assignmentsOf("orders", []int32{1, 3}, []int32{2, 3}, []int32{3, 1}, []int32{3, 2}),
+			brokers:     []int32{1, 2},
+			moves:       4,
+		},
+		{
+			name:        "too few brokers for the replication factor",
+			assignments: assignmentsOf("orders", []int32{1, 2, 3}),
+			brokers:     []int32{1, 2},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := planReassignment(tt.assignments, tt.brokers)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidReassignment) {
+					t.Fatalf("error = %v, want ErrInvalidReassignment", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("planReassignment: %v", err)
+			}
+
+			if plan.ReplicaMoves != tt.moves {
+				t.Errorf("replica moves = %d, want %d", plan.ReplicaMoves, tt.moves)
+			}
+
+			load := make(map[int32]int)
+			for _, a := range tt.assignments {
+				if len(a.target) != len(a.current) {
+					t.Errorf("%s/%d target %v changes the replication factor of %v", a.topic, a.partition, a.target, a.current)
+				}
+				seen := make(map[int32]bool)
+				for _, id := range a.target {
+					if seen[id] || !containsBroker(tt.brokers, id) {
+						t.Errorf("%s/%d target %v has a duplicate or retired broker", a.topic, a.partition, a.target)
+					}
+					seen[id] = true
+					load[id]++
+				}
+			}
+
+			lowest, highest := load[tt.brokers[0]], load[tt.brokers[0]]
+			for _, id := range tt.brokers {
+				if load[id] != plan.BrokerLoad[id] {
+					t.Errorf("broker %d load = %d, plan reports %d", id, load[id], plan.BrokerLoad[id])
+				}
+				lowest, highest = min(lowest, load[id]), max(highest, load[id])
+			}
+			if highest-lowest > 1 {
+				t.Errorf("unbalanced load %v", load)
+			}
+		})
+	}
+}
+
+func TestMovableAssignment(t *testing.T) {
+	kept := &partitionAssignment{topic: "orders", partition: 0, current: []int32{1, 2}, target: []int32{1, 2}}
+	added := &partitionAssignment{topic: "orders", partition: 1, current: []int32{2, 3}, target: []int32{1, 3}}
+	blocked := &partitionAssignment{topic: "orders", partition: 2, current: []int32{1, 4}, target: []int32{1, 4}}
+
+	tests := []struct {
+		name        string
+		assignments []*partitionAssignment
+		from, to    int32
+		want        *partitionAssignment
+	}{
+		{name: "prefers a replica the plan added", assignments: []*partitionAssignment{kept, added}, from: 1, to: 4, want: added},
+		{name: "falls back to an existing replica", assignments: []*partitionAssignment{kept}, from: 1, to: 4, want: kept},
+		{name: "skips partitions already on the target", assignments: []*partitionAssignment{blocked}, from: 1, to: 4, want: nil},
+		{name: "nothing on the source broker", assignments: []*partitionAssignment{kept, added}, from: 4, to: 3, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := movableAssignment(tt.assignments, tt.from, tt.to); got != tt.want {
+				t.Errorf("movableAssignment = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBalancePreferredLeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets [][]int32
+		brokers []int32
+		want    [][]int32
+	}{
+		{
+			name:    "already balanced",
+			targets: [][]int32{{1, 2}, {2, 3}, {3, 1}},
+			brokers: []int32{1, 2, 3},
+			want:    [][]int32{{1, 2}, {2, 3}, {3, 1}},
+		},
+		{
+			name:    "one broker leads everything",
+			targets: [][]int32{{1, 2}, {1, 3}, {1, 2}},
+			brokers: []int32{1, 2, 3},
+			want:    [][]int32{{2, 1}, {3, 1}, {1, 2}},
+		},
+		{
+			name:    "order of the followers is kept",
+			targets: [][]int32{{1, 2, 3}, {1, 2, 3}},
+			brokers: []int32{1, 2, 3},
+			want:    [][]int32{{2, 1, 3}, {1, 2, 3}},
+		},
+		{
+			name:    "single replicas cannot move",
+			targets: [][]int32{{1}, {1}},
+			brokers: []int32{1, 2},
+			want:    [][]int32{{1}, {1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assignments := make([]*partitionAssignment, 0, len(tt.targets))
+			for i, target := range tt.targets {
+				assignments = append(assignments, &partitionAssignment{
+					topic:     "orders",
+					partition: int32(i),
+					current:   append([]int32(nil), target...),
+					target:    append([]int32(nil), target...),
+				})
+			}
+
+			balancePreferredLeaders(assignments, tt.brokers)
+
+			for i, a := range assignments {
+				if !equalReplicas(a.target, tt.want[i]) {
+					t.Errorf("partition %d target = %v, want %v", i, a.target, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"golang.org/x/time/rate"
+)
+
+// Settings of replays
+const (
+	replayOriginHeader = "danos-replay-origin"
+	// Messages queued in the producer. The delivery channel holds as many
+	// reports, so the producer never blocks on it.
+	replayQueueSize        = 10000
+	replayProgressInterval = time.Second
+	replayFlushTimeout     = 30 * time.Second
+	replayCancelTimeout    = 5 * time.Second
+)
+
+// validateReplay checks a replay request before its job starts
+func (m *ClusterManager) validateReplay(destination *ClusterManager, topic string, req domain.KafkaReplayRequest) error {
+	if req.DestinationTopic == "" {
+		return fmt.Errorf("destination_topic is required")
+	}
+	if destination == m && req.DestinationTopic == topic {
+		return fmt.Errorf("destination topic must differ from the source topic")
+	}
+	if req.StartOffset != nil && req.StartTime != nil {
+		return fmt.Errorf("start_offset and start_time cannot both be set")
+	}
+	if req.EndOffset != nil && req.EndTime != nil {
+		return fmt.Errorf("end_offset and end_time cannot both be set")
+	}
+	if req.StartOffset != nil && *req.StartOffset < 0 {
+		return fmt.Errorf("start_offset must not be negative")
+	}
+	if req.StartOffset != nil && req.EndOffset != nil && *req.EndOffset <= *req.StartOffset {
+		return fmt.Errorf("end_offset must be greater than start_offset")
+	}
+	if req.StartTime != nil && req.EndTime != nil && !req.EndTime.After(*req.StartTime) {
+		return fmt.Errorf("end_time must be after start_time")
+	}
+	if req.MaxPerSec < 0 {
+		return fmt.Errorf("max_per_sec must not be negative")
+	}
+	if _, _, err := replayFilters(req); err != nil {
+		return err
+	}
+
+	sourcePartitions, err := m.topicPartitionIDs(topic)
+	if err != nil {
+		return err
+	}
+	for _, partition := range req.Partitions {
+		if !containsPartition(sourcePartitions, partition) {
+			return fmt.Errorf("partition %d of topic %s not found", partition, topic)
+		}
+	}
+
+	destinationPartitions, err := destination.topicPartitionIDs(req.DestinationTopic)
+	if err != nil {
+		return fmt.Errorf("destination: %w", err)
+	}
+	if req.PreservePartitions {
+		selected := req.Partitions
+		if len(selected) == 0 {
+			selected = sourcePartitions
+		}
+		for _, partition := range selected {
+			if !containsPartition(destinationPartitions, partition) {
+				return fmt.Errorf("destination topic %s has no partition %d", req.DestinationTopic, partition)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Replay copies the selected messages of a topic to the destination topic.
+// It stops early when ctx is done or the destination cluster is closed.
+func (m *ClusterManager) Replay(ctx context.Context, destination *ClusterManager, topic string, req domain.KafkaReplayRequest, update jobUpdate) (*domain.KafkaReplayResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(destination.ctx, cancel)()
+
+	keyFilter, valueFilter, err := replayFilters(req)
+	if err != nil {
+		return nil, err
+	}
+
+	consumer, err := m.newReader("replay")
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	ranges, err := replayRanges(consumer, topic, req)
+	if err != nil {
+		return nil, err
+	}
+
+	producer, err := destination.newReplayProducer()
+	if err != nil {
+		return nil, err
+	}
+	defer producer.Close()
+
+	result := &domain.KafkaReplayResult{
+		SourceCluster:      m.name,
+		SourceTopic:        topic,
+		DestinationCluster: destination.name,
+		DestinationTopic:   req.DestinationTopic,
+	}
+	for _, r := range ranges {
+		result.Total += r.end - r.start
+	}
+
+	var limiter *rate.Limiter
+	if req.MaxPerSec > 0 {
+		limiter = rate.NewLimiter(rate.Limit(req.MaxPerSec), req.MaxPerSec)
+	}
+
+	deliveries := make(chan kafka.Event, replayQueueSize)
+	var pending int64
+	record := func(event kafka.Event) {
+		msg, ok := event.(*kafka.Message)
+		if !ok {
+			return
+		}
+		pending--
+		if msg.TopicPartition.Error != nil {
+			result.Failed++
+			result.LastError = msg.TopicPartition.Error.Error()
+			return
+		}
+		result.Produced++
+	}
+	drain := func() {
+		for {
+			select {
+			case event := <-deliveries:
+				record(event)
+			default:
+				return
+			}
+		}
+	}
+
+	destinationTopic := req.DestinationTopic
+	lastUpdate := time.Now()
+	_, err = readRanges(ctx, consumer, topic, ranges, time.Time{}, func(msg *kafka.Message) bool {
+		result.Scanned++
+		drain()
+
+		if time.Since(lastUpdate) >= replayProgressInterval && result.Total > 0 {
+			snapshot := *result
+			update(float64(result.Scanned)/float64(result.Total), &snapshot)
+			lastUpdate = time.Now()
+		}
+
+		if keyFilter != nil || valueFilter != nil {
+			message := m.decodeMessage(msg)
+			if !tailMatches(keyFilter, message.Key) || !tailMatches(valueFilter, message.Value) {
+				result.Filtered++
+				return true
+			}
+		}
+
+		if limiter != nil && limiter.Wait(ctx) != nil {
+			return false
+		}
+
+		out := &kafka.Message{
+			TopicPartition: kafka.TopicPartition{Topic: &destinationTopic, Partition: kafka.PartitionAny},
+			Key:            msg.Key,
+			Value:          msg.Value,
+			Headers:        rewriteHeaders(msg.Headers, req.Headers, fmt.Sprintf("%s/%s/%d/%d", m.name, topic, msg.TopicPartition.Partition, msg.TopicPartition.Offset)),
+		}
+		if req.PreservePartitions {
+			out.TopicPartition.Partition = msg.TopicPartition.Partition
+		}
+		if req.PreserveTimestamps && msg.TimestampType != kafka.TimestampNotAvailable {
+			out.Timestamp = msg.Timestamp
+		}
+
+		for {
+			err := producer.Produce(out, deliveries)
+			if err == nil {
+				pending++
+				return true
+			}
+			if kafkaErr, ok := err.(kafka.Error); !ok || kafkaErr.Code() != kafka.ErrQueueFull {
+				result.Failed++
+				result.LastError = err.Error()
+				return true
+			}
+
+			// Wait for deliveries to free the producer queue
+			select {
+			case event := <-deliveries:
+				record(event)
+			case <-ctx.Done():
+				return false
+			}
+		}
+	})
+
+	// Wait for the delivery of everything already produced
+	flushTimeout := replayFlushTimeout
+	if ctx.Err() != nil {
+		flushTimeout = replayCancelTimeout
+	}
+	timeout := time.After(flushTimeout)
+wait:
+	for pending > 0 {
+		select {
+		case event := <-deliveries:
+			record(event)
+		case <-timeout:
+			break wait
+		}
+	}
+
+	if err != nil {
+		return result, err
+	}
+	if pending > 0 {
+		return result, fmt.Errorf("%d messages not confirmed by the destination within %s", pending, flushTimeout)
+	}
+	if destination.ctx.Err() != nil {
+		return result, fmt.Errorf("destination cluster %s was closed", destination.name)
+	}
+	return result, nil
+}
+
+// replayRanges resolves the offsets a replay reads from every partition
+func replayRanges(consumer *kafka.Consumer, topic string, req domain.KafkaReplayRequest) ([]offsetRange, error) {
+	partitions := req.Partitions
+	if len(partitions) == 0 {
+		var err error
+		if partitions, err = readerPartitions(consumer, topic, -1); err != nil {
+			return nil, err
+		}
+	}
+
+	var starts, ends map[int32]int64
+	var err error
+	if req.StartTime != nil {
+		if starts, err = offsetsForTime(consumer, topic, partitions, *req.StartTime); err != nil {
+			return nil, err
+		}
+	}
+	if req.EndTime != nil {
+		if ends, err = offsetsForTime(consumer, topic, partitions, *req.EndTime); err != nil {
+			return nil, err
+		}
+	}
+
+	ranges := make([]offsetRange, 0, len(partitions))
+	for _, partition := range partitions {
+		low, high, err := consumer.QueryWatermarkOffsets(topic, partition, readerTimeoutMs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query watermarks of partition %d: %w", partition, err)
+		}
+
+		r := offsetRange{partition: partition, start: low, end: high}
+		switch {
+		case req.StartOffset != nil:
+			r.start = max(*req.StartOffset, low)
+		case req.StartTime != nil:
+			start, ok := starts[partition]
+			if !ok {
+				continue // no message at or after the start time
+			}
+			r.start = max(start, low)
+		}
+		switch {
+		case req.EndOffset != nil:
+			r.end = min(*req.EndOffset, high)
+		case req.EndTime != nil:
+			if end, ok := ends[partition]; ok {
+				r.end = min(end, high)
+			}
+		}
+		if r.start < r.end {
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges, nil
+}
+
+func (m *ClusterManager) newReplayProducer() (*kafka.Producer, error) {
+	m.mu.RLock()
+	connected := m.connected
+	m.mu.RUnlock()
+	if !connected {
+		return nil, fmt.Errorf("kafka cluster %s not connected", m.name)
+	}
+
+	config := m.buildKafkaConfig()
+	config["acks"] = "all"
+	config["enable.idempotence"] = true
+	config["queue.buffering.max.messages"] = replayQueueSize
+
+	producer, err := kafka.NewProducer(&config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create replay producer: %w", err)
+	}
+	return producer, nil
+}
+
+// topicPartitionIDs returns the sorted partition IDs of a topic
+func (m *ClusterManager) topicPartitionIDs(topic string) ([]int32, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+
+	metadata, err := m.adminClient.GetMetadata(&topic, false, 3000)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topic metadata: %w", err)
+	}
+	t, exists := metadata.Topics[topic]
+	if !exists || t.Error.Code() != kafka.ErrNoError {
+		return nil, fmt.Errorf("topic %s not found", topic)
+	}
+
+	partitions := make([]int32, 0, len(t.Partitions))
+	for _, p := range t.Partitions {
+		partitions = append(partitions, p.ID)
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+	return partitions, nil
+}
+
+func replayFilters(req domain.KafkaReplayRequest) (*regexp.Regexp, *regexp.Regexp, error) {
+	return tailFilters(domain.KafkaTailRequest{KeyFilter: req.Key, ValueFilter: req.Value})
+}
+
+// rewriteHeaders applies a header rewrite to the headers of a copied message
+func rewriteHeaders(headers []kafka.Header, rewrite domain.KafkaReplayHeaderRewrite, origin string) []kafka.Header {
+	drop := make(map[string]bool, len(rewrite.Remove)+len(rewrite.Set))
+	for _, key := range rewrite.Remove {
+		drop[key] = true
+	}
+	for key := range rewrite.Set {
+		drop[key] = true
+	}
+	if rewrite.AddOrigin {
+		drop[replayOriginHeader] = true
+	}
+
+	rewritten := make([]kafka.Header, 0, len(headers)+len(rewrite.Set)+1)
+	for _, header := range headers {
+		if !drop[header.Key] {
+			rewritten = append(rewritten, header)
+		}
+	}
+
+	keys := make([]string, 0, len(rewrite.Set))
+	for key := range rewrite.Set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		rewritten = append(rewritten, kafka.Header{Key: key, Value: []byte(rewrite.Set[key])})
+	}
+	if rewrite.AddOrigin {
+		rewritten = append(rewritten, kafka.Header{Key: replayOriginHeader, Value: []byte(origin)})
+	}
+	return rewritten
+}
+
+func containsPartition(partitions []int32, partition int32) bool {
+	for _, p := range partitions {
+		if p == partition {
+			return true
+		}
+	}
+	return false
+}
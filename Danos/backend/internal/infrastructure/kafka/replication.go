@@ -0,0 +1,476 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// Settings of replication monitoring
+const (
+	defaultMaxCheckpointAge = 180 // seconds
+	// Messages read from the end of every partition of the checkpoints and
+	// offset syncs topics
+	replicationScanMessages = 10000
+	heartbeatScanMessages   = 10
+	replicationReadTimeout  = 10 * time.Second
+)
+
+// offsetSync maps an upstream offset to the offset of its replica
+type offsetSync struct {
+	upstream   int64
+	downstream int64
+}
+
+// checkpoint is the translated committed offset of a group on a replicated
+// partition
+type checkpoint struct {
+	topic      string // target topic
+	partition  int32
+	upstream   int64
+	downstream int64
+	at         time.Time
+}
+
+// GetReplicationStatus reports the MirrorMaker 2 flows replicating into a
+// cluster
+func (m *KafkaManager) GetReplicationStatus(clusterName string) ([]domain.KafkaReplicationStatus, error) {
+	target, err := m.GetCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]domain.KafkaReplicationStatus, 0, len(target.config.Replication))
+	for _, flow := range target.config.Replication {
+		flow = replicationDefaults(flow, clusterName)
+
+		source, err := m.GetCluster(flow.SourceCluster)
+		if err != nil {
+			statuses = append(statuses, domain.KafkaReplicationStatus{
+				SourceCluster:  flow.SourceCluster,
+				TargetCluster:  clusterName,
+				SourceAlias:    flow.SourceAlias,
+				Status:         "error",
+				Error:          err.Error(),
+				Topics:         make([]domain.KafkaReplicatedTopicLag, 0),
+				ConsumerGroups: make([]domain.KafkaReplicatedGroup, 0),
+				InternalTopics: make([]domain.KafkaReplicationInternalTopic, 0),
+			})
+			continue
+		}
+		statuses = append(statuses, target.replicationStatus(source, flow))
+	}
+	return statuses, nil
+}
+
+// replicationDefaults fills the MirrorMaker 2 default names of a flow
+func replicationDefaults(flow domain.KafkaReplicationFlow, targetName string) domain.KafkaReplicationFlow {
+	if flow.SourceAlias == "" {
+		flow.SourceAlias = flow.SourceCluster
+	}
+	if flow.TargetAlias == "" {
+		flow.TargetAlias = targetName
+	}
+	if flow.CheckpointsTopic == "" {
+		flow.CheckpointsTopic = flow.SourceAlias + ".checkpoints.internal"
+	}
+	if flow.HeartbeatsTopic == "" {
+		flow.HeartbeatsTopic = "heartbeats"
+	}
+	if flow.OffsetSyncsTopic == "" {
+		flow.OffsetSyncsTopic = "mm2-offset-syncs." + flow.TargetAlias + ".internal"
+	}
+	if flow.MaxCheckpointAge <= 0 {
+		flow.MaxCheckpointAge = defaultMaxCheckpointAge
+	}
+
+	topics := make([]domain.KafkaReplicatedTopic, 0, len(flow.Topics))
+	for _, topic := range flow.Topics {
+		if topic.Target == "" {
+			topic.Target = flow.SourceAlias + "." + topic.Source
+		}
+		topics = append(topics, topic)
+	}
+	flow.Topics = topics
+	return flow
+}
+
+// replicationStatus compares a flow's source topics with their replicas on
+// this cluster and checks the freshness of the MirrorMaker 2 internal topics
+func (m *ClusterManager) replicationStatus(source *ClusterManager, flow domain.KafkaReplicationFlow) domain.KafkaReplicationStatus {
+	status := domain.KafkaReplicationStatus{
+		SourceCluster:  source.name,
+		TargetCluster:  m.name,
+		SourceAlias:    flow.SourceAlias,
+		Status:         "ok",
+		Topics:         make([]domain.KafkaReplicatedTopicLag, 0),
+		ConsumerGroups: make([]domain.KafkaReplicatedGroup, 0),
+		InternalTopics: make([]domain.KafkaReplicationInternalTopic, 0, 3),
+	}
+
+	targetReader, err := m.newReader("replication")
+	if err != nil {
+		status.Status = "error"
+		status.Error = err.Error()
+		return status
+	}
+	defer targetReader.Close()
+
+	sourceReader, err := source.newReader("replication")
+	if err != nil {
+		status.Status = "error"
+		status.Error = err.Error()
+		return status
+	}
+	defer sourceReader.Close()
+
+	maxAge := time.Duration(flow.MaxCheckpointAge) * time.Second
+
+	// Offset syncs translate upstream offsets to offsets of the replicas
+	syncs := make(map[string]offsetSync)
+	syncsTopic := readInternalTopic(source.ctx, sourceReader, "offset_syncs", source.name, flow.OffsetSyncsTopic, replicationScanMessages, func(msg *kafka.Message) bool {
+		topic, partition, sync, ok := parseOffsetSync(msg.Key, msg.Value)
+		if ok {
+			syncs[partitionKey(topic, partition)] = sync
+		}
+		return ok
+	})
+
+	checkpoints := make(map[string]map[string]checkpoint)
+	checkpointsTopic := readInternalTopic(m.ctx, targetReader, "checkpoints", m.name, flow.CheckpointsTopic, replicationScanMessages, func(msg *kafka.Message) bool {
+		group, cp, ok := parseCheckpoint(msg.Key, msg.Value)
+		if !ok {
+			return false
+		}
+		cp.at = msg.Timestamp
+		if checkpoints[group] == nil {
+			checkpoints[group] = make(map[string]checkpoint)
+		}
+		checkpoints[group][partitionKey(cp.topic, cp.partition)] = cp
+		return true
+	})
+	checkpointsTopic.Stale = internalTopicStale(checkpointsTopic, maxAge)
+
+	heartbeatsTopic := readInternalTopic(m.ctx, targetReader, "heartbeats", m.name, flow.HeartbeatsTopic, heartbeatScanMessages, func(*kafka.Message) bool {
+		return true
+	})
+	heartbeatsTopic.Stale = internalTopicStale(heartbeatsTopic, maxAge)
+
+	status.InternalTopics = append(status.InternalTopics, checkpointsTopic, heartbeatsTopic, syncsTopic)
+	for _, internal := range status.InternalTopics {
+		if !internal.Found || internal.Stale {
+			status.Status = "warning"
+		}
+	}
+
+	topics := flow.Topics
+	if len(topics) == 0 {
+		if topics, err = remoteTopics(targetReader, flow); err != nil {
+			status.Status = "error"
+			status.Error = err.Error()
+			return status
+		}
+	}
+
+	for _, topic := range topics {
+		status.Topics = append(status.Topics, topicReplicationLag(source, sourceReader, targetReader, topic, syncs))
+	}
+
+	// Source topic of every replica, for the groups' committed offsets
+	upstreamTopics := make(map[string]string, len(topics))
+	for _, topic := range topics {
+		upstreamTopics[topic.Target] = topic.Source
+	}
+
+	for _, group := range flow.ConsumerGroups {
+		replicated := domain.KafkaReplicatedGroup{Group: group, Partitions: make([]domain.KafkaReplicatedGroupPartition, 0)}
+		sourceCommitted := source.committedOffsets(group)
+		targetCommitted := m.committedOffsets(group)
+
+		for _, cp := range checkpoints[group] {
+			sourceTopic, ok := upstreamTopics[cp.topic]
+			if !ok {
+				sourceTopic = strings.TrimPrefix(cp.topic, flow.SourceAlias+".")
+			}
+
+			partition := domain.KafkaReplicatedGroupPartition{
+				SourceTopic:          sourceTopic,
+				TargetTopic:          cp.topic,
+				Partition:            cp.partition,
+				SourceCommitted:      -1,
+				CheckpointUpstream:   cp.upstream,
+				CheckpointDownstream: cp.downstream,
+				TargetCommitted:      -1,
+				CheckpointAt:         cp.at,
+			}
+			if offset, ok := sourceCommitted[partitionKey(sourceTopic, cp.partition)]; ok {
+				partition.SourceCommitted = offset
+				partition.CheckpointLag = max(offset-cp.upstream, 0)
+			}
+			if offset, ok := targetCommitted[partitionKey(cp.topic, cp.partition)]; ok {
+				partition.TargetCommitted = offset
+			}
+			replicated.Partitions = append(replicated.Partitions, partition)
+
+			if replicated.LastCheckpointAt == nil || cp.at.After(*replicated.LastCheckpointAt) {
+				at := cp.at
+				replicated.LastCheckpointAt = &at
+			}
+		}
+
+		sort.Slice(replicated.Partitions, func(i, j int) bool {
+			a, b := replicated.Partitions[i], replicated.Partitions[j]
+			return a.TargetTopic < b.TargetTopic || (a.TargetTopic == b.TargetTopic && a.Partition < b.Partition)
+		})
+
+		if replicated.LastCheckpointAt != nil {
+			age := time.Since(*replicated.LastCheckpointAt).Seconds()
+			replicated.CheckpointAgeSeconds = &age
+		}
+		replicated.Stale = replicated.CheckpointAgeSeconds == nil || *replicated.CheckpointAgeSeconds > maxAge.Seconds()
+		if replicated.Stale {
+			status.Status = "warning"
+		}
+
+		status.ConsumerGroups = append(status.ConsumerGroups, replicated)
+	}
+
+	return status
+}
+
+// topicReplicationLag compares the high watermarks of a source topic and its
+// replica per partition
+func topicReplicationLag(source *ClusterManager, sourceReader, targetReader *kafka.Consumer, topic domain.KafkaReplicatedTopic, syncs map[string]offsetSync) domain.KafkaReplicatedTopicLag {
+	lag := domain.KafkaReplicatedTopicLag{
+		SourceTopic: topic.Source,
+		TargetTopic: topic.Target,
+		Partitions:  make([]domain.KafkaReplicatedPartitionLag, 0),
+	}
+
+	partitions, err := readerPartitions(sourceReader, topic.Source, -1)
+	if err != nil {
+		lag.Error = err.Error()
+		return lag
+	}
+
+	sourceTopic := topic.Source
+	positions := make([]kafka.TopicPartition, 0)
+	for _, partition := range partitions {
+		_, sourceHigh, err := sourceReader.QueryWatermarkOffsets(topic.Source, partition, readerTimeoutMs)
+		if err != nil {
+			lag.Error = fmt.Sprintf("failed to query source watermarks of partition %d: %v", partition, err)
+			continue
+		}
+		_, targetHigh, err := targetReader.QueryWatermarkOffsets(topic.Target, partition, readerTimeoutMs)
+		if err != nil {
+			lag.Error = fmt.Sprintf("failed to query target watermarks of partition %d: %v", partition, err)
+			continue
+		}
+
+		p := domain.KafkaReplicatedPartitionLag{
+			Partition:           partition,
+			SourceHighWatermark: sourceHigh,
+			TargetHighWatermark: targetHigh,
+			ReplicatedUpTo:      targetHigh,
+		}
+		// The replica offsets after the latest sync follow the upstream ones
+		if sync, ok := syncs[partitionKey(topic.Source, partition)]; ok && targetHigh >= sync.downstream {
+			p.ReplicatedUpTo = sync.upstream + (targetHigh - sync.downstream)
+			p.Translated = true
+		}
+		p.LagMessages = max(sourceHigh-p.ReplicatedUpTo, 0)
+		if p.LagMessages > 0 {
+			positions = append(positions, kafka.TopicPartition{
+				Topic:     &sourceTopic,
+				Partition: partition,
+				Offset:    kafka.Offset(p.ReplicatedUpTo),
+			})
+		}
+
+		lag.Partitions = append(lag.Partitions, p)
+		lag.LagMessages += p.LagMessages
+	}
+
+	// Lag in seconds is the age of the oldest message not yet replicated
+	now := time.Now()
+	timestamps := source.fetchTimestamps(positions, 2*time.Second)
+	for i := range lag.Partitions {
+		if ts, ok := timestamps[positionKey(topic.Source, lag.Partitions[i].Partition, lag.Partitions[i].ReplicatedUpTo)]; ok {
+			if age := now.Sub(ts).Seconds(); age > 0 {
+				lag.Partitions[i].LagSeconds = age
+				lag.MaxLagSeconds = max(lag.MaxLagSeconds, age)
+			}
+		}
+	}
+
+	return lag
+}
+
+// remoteTopics lists the replicas of a flow on the target cluster, named
+// <source_alias>.<topic> by the default replication policy
+func remoteTopics(consumer *kafka.Consumer, flow domain.KafkaReplicationFlow) ([]domain.KafkaReplicatedTopic, error) {
+	metadata, err := consumer.GetMetadata(nil, true, readerTimeoutMs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata: %w", err)
+	}
+
+	prefix := flow.SourceAlias + "."
+	topics := make([]domain.KafkaReplicatedTopic, 0)
+	for name := range metadata.Topics {
+		if !strings.HasPrefix(name, prefix) || strings.HasSuffix(name, ".internal") || name == prefix+"heartbeats" {
+			continue
+		}
+		topics = append(topics, domain.KafkaReplicatedTopic{Source: strings.TrimPrefix(name, prefix), Target: name})
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].Target < topics[j].Target })
+	return topics, nil
+}
+
+// readInternalTopic reads the last messages of every partition of a
+// MirrorMaker 2 internal topic. visit reports whether a message could be
+// read; the newest of those gives the last record time.
+func readInternalTopic(ctx context.Context, consumer *kafka.Consumer, role, cluster, topic string, perPartition int64, visit func(*kafka.Message) bool) domain.KafkaReplicationInternalTopic {
+	internal := domain.KafkaReplicationInternalTopic{Role: role, Cluster: cluster, Topic: topic}
+
+	partitions, err := readerPartitions(consumer, topic, -1)
+	if err != nil {
+		internal.Error = err.Error()
+		return internal
+	}
+	internal.Found = true
+
+	ranges := make([]offsetRange, 0, len(partitions))
+	for _, partition := range partitions {
+		low, high, err := consumer.QueryWatermarkOffsets(topic, partition, readerTimeoutMs)
+		if err != nil {
+			internal.Error = fmt.Sprintf("failed to query watermarks of partition %d: %v", partition, err)
+			return internal
+		}
+		if r := (offsetRange{partition: partition, start: max(high-perPartition, low), end: high}); r.start < r.end {
+			ranges = append(ranges, r)
+		}
+	}
+
+	_, err = readRanges(ctx, consumer, topic, ranges, time.Now().Add(replicationReadTimeout), func(msg *kafka.Message) bool {
+		if visit(msg) && msg.TimestampType != kafka.TimestampNotAvailable &&
+			(internal.LastRecordAt == nil || msg.Timestamp.After(*internal.LastRecordAt)) {
+			at := msg.Timestamp
+			internal.LastRecordAt = &at
+		}
+		return true
+	})
+	if err != nil {
+		internal.Error = err.Error()
+	}
+
+	if internal.LastRecordAt != nil {
+		age := time.Since(*internal.LastRecordAt).Seconds()
+		internal.AgeSeconds = &age
+	}
+	return internal
+}
+
+func internalTopicStale(internal domain.KafkaReplicationInternalTopic, maxAge time.Duration) bool {
+	return internal.AgeSeconds == nil || *internal.AgeSeconds > maxAge.Seconds()
+}
+
+// committedOffsets returns the committed offsets of a group keyed by
+// partitionKey, empty when the group has none
+func (m *ClusterManager) committedOffsets(group string) map[string]int64 {
+	offsets := make(map[string]int64)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return offsets
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 3*time.Second)
+	defer cancel()
+
+	result, err := m.adminClient.ListConsumerGroupOffsets(ctx, []kafka.ConsumerGroupTopicPartitions{{Group: group}})
+	if err != nil {
+		log.Printf("Error listing offsets of group %s on %s: %v", group, m.name, err)
+		return offsets
+	}
+	if len(result.ConsumerGroupsTopicPartitions) == 0 {
+		return offsets
+	}
+
+	for _, tp := range result.ConsumerGroupsTopicPartitions[0].Partitions {
+		if tp.Error == nil && tp.Offset >= 0 {
+			offsets[partitionKey(*tp.Topic, tp.Partition)] = int64(tp.Offset)
+		}
+	}
+	return offsets
+}
+
+// structReader reads the Kafka protocol struct encoding of MirrorMaker 2
+// internal records: big endian integers and strings with an int16 length
+type structReader struct {
+	data []byte
+	ok   bool
+}
+
+func newStructReader(data []byte) *structReader {
+	return &structReader{data: data, ok: true}
+}
+
+func (r *structReader) next(n int) []byte {
+	if !r.ok || len(r.data) < n {
+		r.ok = false
+		return make([]byte, n)
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *structReader) int16() int16 { return int16(binary.BigEndian.Uint16(r.next(2))) }
+func (r *structReader) int32() int32 { return int32(binary.BigEndian.Uint32(r.next(4))) }
+func (r *structReader) int64() int64 { return int64(binary.BigEndian.Uint64(r.next(8))) }
+
+func (r *structReader) string() string {
+	n := r.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(r.next(int(n)))
+}
+
+// parseOffsetSync reads an offset sync record. The key holds the upstream
+// topic and partition, the value the upstream and downstream offsets.
+func parseOffsetSync(key, value []byte) (string, int32, offsetSync, bool) {
+	k := newStructReader(key)
+	topic := k.string()
+	partition := k.int32()
+
+	v := newStructReader(value)
+	sync := offsetSync{upstream: v.int64(), downstream: v.int64()}
+
+	return topic, partition, sync, k.ok && v.ok
+}
+
+// parseCheckpoint reads a checkpoint record. The key holds the group and the
+// target topic and partition, the value a version header followed by the
+// upstream and downstream offsets and the commit metadata.
+func parseCheckpoint(key, value []byte) (string, checkpoint, bool) {
+	k := newStructReader(key)
+	group := k.string()
+	cp := checkpoint{topic: k.string(), partition: k.int32()}
+
+	v := newStructReader(value)
+	v.int16() // version
+	cp.upstream = v.int64()
+	cp.downstream = v.int64()
+
+	return group, cp, k.ok && v.ok
+}
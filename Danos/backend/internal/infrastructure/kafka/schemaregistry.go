@@ -0,0 +1,363 @@
+package kafka
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/linkedin/goavro/v2"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Failed schema lookups are retried after this long instead of on every message
+const schemaRetryInterval = 30 * time.Second
+
+// Registry error codes for a subject or subject config that does not exist
+const (
+	registrySubjectNotFound = 40401
+	registryConfigNotFound  = 40408
+)
+
+// schemaRegistry is a client for the Confluent schema registry REST API. It
+// caches schemas by ID, as registered schemas never change.
+type schemaRegistry struct {
+	baseURL     string
+	username    string
+	password    string
+	bearerToken string
+	client      *http.Client
+
+	mu       sync.Mutex
+	schemas  map[int]*registrySchema
+	fetching map[int]chan struct{} // closed when the lookup of an ID ends
+}
+
+// registrySchema is a schema looked up by ID, parsed for decoding
+type registrySchema struct {
+	schemaType string
+	versions   []registrySubjectVersion
+	avro       *goavro.Codec
+	proto      protoreflect.FileDescriptor
+	err        error
+	fetchedAt  time.Time
+}
+
+type registrySubjectVersion struct {
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// registryResponse is the schema as returned by the ID and subject version
+// lookups
+type registryResponse struct {
+	Subject    string              `json:"subject"`
+	Version    int                 `json:"version"`
+	ID         int                 `json:"id"`
+	SchemaType string              `json:"schemaType"` // empty means AVRO
+	Schema     string              `json:"schema"`
+	References []registryReference `json:"references"`
+}
+
+type registryReference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// registryError is the error body of the registry API
+type registryError struct {
+	Code    int    `json:"error_code"`
+	Message string `json:"message"`
+	Status  int    `json:"-"`
+}
+
+func (e *registryError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("schema registry returned status %d", e.Status)
+	}
+	return fmt.Sprintf("schema registry error %d: %s", e.Code, e.Message)
+}
+
+// newSchemaRegistry creates a registry client, or returns nil when no
+// registry is configured
+func newSchemaRegistry(config domain.KafkaSchemaRegistry) (*schemaRegistry, error) {
+	if config.URL == "" {
+		return nil, nil
+	}
+
+	timeout := 5 * time.Second
+	if config.Timeout > 0 {
+		timeout = time.Duration(config.Timeout) * time.Second
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if strings.HasPrefix(strings.ToLower(config.URL), "https://") {
+		tlsConfig, err := buildTLSConfig(config.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema registry TLS config: %w", err)
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &schemaRegistry{
+		baseURL:     strings.TrimRight(config.URL, "/"),
+		username:    config.Username,
+		password:    config.Password,
+		bearerToken: config.BearerToken,
+		client:      &http.Client{Timeout: timeout, Transport: transport},
+		schemas:     make(map[int]*registrySchema),
+		fetching:    make(map[int]chan struct{}),
+	}, nil
+}
+
+// get calls a registry endpoint and decodes the JSON response into out
+func (r *schemaRegistry) get(path string, query url.Values, out interface{}) error {
+	endpoint := r.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json, application/json")
+	switch {
+	case r.bearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+r.bearerToken)
+	case r.username != "":
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("schema registry request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read schema registry response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		regErr := &registryError{Status: resp.StatusCode}
+		_ = json.Unmarshal(body, regErr)
+		return regErr
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("invalid schema registry response: %w", err)
+	}
+	return nil
+}
+
+// schema returns the parsed schema of an ID, looking it up on first use. The
+// lookup runs outside mu so a slow ID does not hold up the others; callers
+// asking for an ID being looked up wait for that lookup.
+func (r *schemaRegistry) schema(id int) *registrySchema {
+	for {
+		r.mu.Lock()
+		if cached, ok := r.schemas[id]; ok && (cached.err == nil || time.Since(cached.fetchedAt) < schemaRetryInterval) {
+			r.mu.Unlock()
+			return cached
+		}
+		if done, ok := r.fetching[id]; ok {
+			r.mu.Unlock()
+			<-done
+			continue
+		}
+		done := make(chan struct{})
+		r.fetching[id] = done
+		r.mu.Unlock()
+
+		schema := r.fetchSchema(id)
+		schema.fetchedAt = time.Now()
+
+		r.mu.Lock()
+		r.schemas[id] = schema
+		delete(r.fetching, id)
+		r.mu.Unlock()
+		close(done)
+		return schema
+	}
+}
+
+func (r *schemaRegistry) fetchSchema(id int) *registrySchema {
+	schema := &registrySchema{}
+
+	var resp registryResponse
+	query := url.Values{}
+	// Protobuf schemas are fetched as serialized descriptors, which avoids
+	// parsing .proto sources. Other schema types ignore the format.
+	query.Set("format", "serialized")
+	if err := r.get(fmt.Sprintf("/schemas/ids/%d", id), query, &resp); err != nil {
+		schema.err = err
+		return schema
+	}
+
+	schema.schemaType = schemaType(resp.SchemaType)
+	switch schema.schemaType {
+	case "AVRO":
+		schema.avro, schema.err = r.avroCodec(resp)
+	case "PROTOBUF":
+		schema.proto, schema.err = r.protoFile(resp)
+	case "JSON":
+		// JSON Schema payloads are plain JSON and need no schema to read
+	default:
+		schema.err = fmt.Errorf("unsupported schema type %s", resp.SchemaType)
+	}
+
+	// Subject and version are informational, decoding works without them
+	var versions []registrySubjectVersion
+	if err := r.get(fmt.Sprintf("/schemas/ids/%d/versions", id), nil, &versions); err == nil {
+		schema.versions = versions
+	}
+
+	return schema
+}
+
+// subjectVersion picks the subject of a schema used by a topic, preferring
+// the topic name strategy subject
+func (s *registrySchema) subjectVersion(topic string, isKey bool) (string, int) {
+	if len(s.versions) == 0 {
+		return "", 0
+	}
+
+	want := topic + "-value"
+	if isKey {
+		want = topic + "-key"
+	}
+	for _, v := range s.versions {
+		if v.Subject == want {
+			return v.Subject, v.Version
+		}
+	}
+	return s.versions[0].Subject, s.versions[0].Version
+}
+
+func (r *schemaRegistry) subjects() ([]string, error) {
+	subjects := make([]string, 0)
+	if err := r.get("/subjects", nil, &subjects); err != nil {
+		return nil, err
+	}
+	return subjects, nil
+}
+
+func (r *schemaRegistry) versions(subject string) ([]int, error) {
+	versions := make([]int, 0)
+	if err := r.get("/subjects/"+url.PathEscape(subject)+"/versions", nil, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// subjectVersion looks up a version of a subject, "latest" being allowed
+func (r *schemaRegistry) subjectVersion(subject, version string, query url.Values) (registryResponse, error) {
+	var resp registryResponse
+	err := r.get("/subjects/"+url.PathEscape(subject)+"/versions/"+url.PathEscape(version), query, &resp)
+	return resp, err
+}
+
+// compatibility returns the compatibility level of a subject, falling back
+// to the global level, or the global level when subject is empty
+func (r *schemaRegistry) compatibility(subject string) (*domain.KafkaSchemaCompatibility, error) {
+	var config struct {
+		CompatibilityLevel string `json:"compatibilityLevel"`
+	}
+
+	if subject != "" {
+		err := r.get("/config/"+url.PathEscape(subject), nil, &config)
+		if err == nil {
+			return &domain.KafkaSchemaCompatibility{Subject: subject, Level: config.CompatibilityLevel}, nil
+		}
+		var regErr *registryError
+		if !errors.As(err, &regErr) || (regErr.Code != registryConfigNotFound && regErr.Code != registrySubjectNotFound) {
+			return nil, err
+		}
+	}
+
+	if err := r.get("/config", nil, &config); err != nil {
+		return nil, err
+	}
+	return &domain.KafkaSchemaCompatibility{
+		Subject:   subject,
+		Level:     config.CompatibilityLevel,
+		Inherited: subject != "",
+	}, nil
+}
+
+// GetSchemaSubjects lists the subjects of the cluster's schema registry
+func (m *ClusterManager) GetSchemaSubjects() ([]string, error) {
+	if m.registry == nil {
+		return nil, fmt.Errorf("no schema registry configured for cluster %s", m.name)
+	}
+	return m.registry.subjects()
+}
+
+// GetSchemaVersions lists the registered versions of a subject
+func (m *ClusterManager) GetSchemaVersions(subject string) ([]int, error) {
+	if m.registry == nil {
+		return nil, fmt.Errorf("no schema registry configured for cluster %s", m.name)
+	}
+	return m.registry.versions(subject)
+}
+
+// GetSchema returns a version of a subject, version being a number or "latest"
+func (m *ClusterManager) GetSchema(subject, version string) (*domain.KafkaSchema, error) {
+	if m.registry == nil {
+		return nil, fmt.Errorf("no schema registry configured for cluster %s", m.name)
+	}
+	if version != "latest" {
+		if _, err := strconv.Atoi(version); err != nil {
+			return nil, fmt.Errorf("version must be a number or latest")
+		}
+	}
+
+	resp, err := m.registry.subjectVersion(subject, version, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &domain.KafkaSchema{
+		Subject:    resp.Subject,
+		Version:    resp.Version,
+		ID:         resp.ID,
+		Type:       schemaType(resp.SchemaType),
+		Schema:     resp.Schema,
+		References: make([]domain.KafkaSchemaReference, 0, len(resp.References)),
+	}
+	for _, ref := range resp.References {
+		schema.References = append(schema.References, domain.KafkaSchemaReference{
+			Name:    ref.Name,
+			Subject: ref.Subject,
+			Version: ref.Version,
+		})
+	}
+	return schema, nil
+}
+
+// GetSchemaCompatibility returns the compatibility level of a subject, or the
+// global level when subject is empty
+func (m *ClusterManager) GetSchemaCompatibility(subject string) (*domain.KafkaSchemaCompatibility, error) {
+	if m.registry == nil {
+		return nil, fmt.Errorf("no schema registry configured for cluster %s", m.name)
+	}
+	return m.registry.compatibility(subject)
+}
+
+// schemaType normalizes the registry schema type, which is empty for Avro
+func schemaType(value string) string {
+	if value == "" {
+		return "AVRO"
+	}
+	return strings.ToUpper(value)
+}
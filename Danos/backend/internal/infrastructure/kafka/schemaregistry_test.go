@@ -0,0 +1,284 @@
+package kafka
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/linkedin/goavro/v2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const testAvroSchema = `{"type": "record", "name": "Order", "fields": [
+	{"name": "id", "type": "long"},
+	{"name": "item", "type": "string"}
+]}`
+
+// testProtoFile has two messages so that payloads need a message index
+func testProtoFile(t *testing.T) *descriptorpb.FileDescriptorProto {
+	t.Helper()
+	field := func(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     kind.Enum(),
+		}
+	}
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("shop.proto"),
+		Package: proto.String("shop"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Order"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+			}},
+			{Name: proto.String("Item"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("sku", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("qty", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+			}},
+		},
+	}
+}
+
+// framed prefixes a payload with the registry header of a schema ID
+func framed(id uint32, payload []byte) []byte {
+	data := []byte{registryMagicByte, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(data[1:], id)
+	return append(data, payload...)
+}
+
+// newTestRegistry starts a fake schema registry serving the given paths
+func newTestRegistry(t *testing.T, responses map[string]interface{}) (*ClusterManager, *atomic.Int32) {
+	t.Helper()
+
+	var lookups atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/schemas/ids/1" || r.URL.Path == "/schemas/ids/2" {
+			lookups.Add(1)
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code": 40403, "message": "Schema not found"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	registry, err := newSchemaRegistry(domain.KafkaSchemaRegistry{URL: server.URL + "/"})
+	if err != nil {
+		t.Fatalf("newSchemaRegistry: %v", err)
+	}
+	return &ClusterManager{name: "test", registry: registry}, &lookups
+}
+
+func TestDecodeRegistryPayloads(t *testing.T) {
+	file := testProtoFile(t)
+	serialized, err := proto.Marshal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, lookups := newTestRegistry(t, map[string]interface{}{
+		"/schemas/ids/1":          registryResponse{Schema: testAvroSchema},
+		"/schemas/ids/1/versions": []registrySubjectVersion{{Subject: "audit-value", Version: 1}, {Subject: "orders-value", Version: 3}},
+		"/schemas/ids/2":          registryResponse{SchemaType: "PROTOBUF", Schema: base64.StdEncoding.EncodeToString(serialized)},
+		"/schemas/ids/2/versions": []registrySubjectVersion{{Subject: "items-value", Version: 2}},
+	})
+
+	codec, err := goavro.NewCodec(testAvroSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	avroPayload, err := codec.BinaryFromNative(nil, map[string]interface{}{"id": int64(7), "item": "book"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	descriptor, err := protodesc.NewFile(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := dynamicpb.NewMessage(descriptor.Messages().ByName("Item"))
+	item.Set(item.Descriptor().Fields().ByName("sku"), protoreflect.ValueOfString("A-1"))
+	item.Set(item.Descriptor().Fields().ByName("qty"), protoreflect.ValueOfInt32(2))
+	protoPayload, err := proto.Marshal(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Message index of the second message of the file
+	protoPayload = append(binary.AppendVarint(binary.AppendVarint(nil, 1), 1), protoPayload...)
+
+	tests := []struct {
+		name    string
+		topic   string
+		data    []byte
+		want    map[string]interface{}
+		schema  *domain.KafkaMessageSchema
+		wantErr bool
+	}{
+		{
+			name:   "avro with the topic subject",
+			topic:  "orders",
+			data:   framed(1, avroPayload),
+			want:   map[string]interface{}{"id": 7.0, "item": "book"},
+			schema: &domain.KafkaMessageSchema{ID: 1, Type: "AVRO", Subject: "orders-value", Version: 3},
+		},
+		{
+			name:   "avro under another subject",
+			topic:  "archive",
+			data:   framed(1, avroPayload),
+			want:   map[string]interface{}{"id": 7.0, "item": "book"},
+			schema: &domain.KafkaMessageSchema{ID: 1, Type: "AVRO", Subject: "audit-value", Version: 1},
+		},
+		{
+			name:   "protobuf nested index",
+			topic:  "items",
+			data:   framed(2, protoPayload),
+			want:   map[string]interface{}{"sku": "A-1", "qty": 2.0},
+			schema: &domain.KafkaMessageSchema{ID: 2, Type: "PROTOBUF", Subject: "items-value", Version: 2},
+		},
+		{name: "unknown schema", topic: "orders", data: framed(9, avroPayload), wantErr: true},
+		{name: "corrupt avro", topic: "orders", data: framed(1, []byte{0xff}), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, encoding, schema, _, err := m.decodePayload(tt.topic, tt.data, false)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", text)
+				}
+				if encoding == "json" || schema != nil {
+					t.Errorf("failed decode returned encoding %s and schema %+v", encoding, schema)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodePayload: %v", err)
+			}
+
+			var got map[string]interface{}
+			if err := json.Unmarshal([]byte(text), &got); err != nil {
+				t.Fatalf("decoded payload %q is not JSON: %v", text, err)
+			}
+			if encoding != "json" || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decoded = %s %v, want %v", encoding, got, tt.want)
+			}
+			if !reflect.DeepEqual(schema, tt.schema) {
+				t.Errorf("schema = %+v, want %+v", schema, tt.schema)
+			}
+		})
+	}
+
+	// Schemas are cached by ID, failed lookups until the retry interval
+	if n := lookups.Load(); n != 2 {
+		t.Errorf("schema lookups = %d, want 2", n)
+	}
+}
+
+func TestGetSchema(t *testing.T) {
+	m, _ := newTestRegistry(t, map[string]interface{}{
+		"/subjects/orders-value/versions/latest": registryResponse{
+			Subject:    "orders-value",
+			Version:    3,
+			ID:         1,
+			Schema:     testAvroSchema,
+			References: []registryReference{{Name: "shop.Item", Subject: "item", Version: 1}},
+		},
+	})
+
+	schema, err := m.GetSchema("orders-value", "latest")
+	if err != nil {
+		t.Fatalf("GetSchema: %v", err)
+	}
+	want := &domain.KafkaSchema{
+		Subject:    "orders-value",
+		Version:    3,
+		ID:         1,
+		Type:       "AVRO",
+		Schema:     testAvroSchema,
+		References: []domain.KafkaSchemaReference{{Name: "shop.Item", Subject: "item", Version: 1}},
+	}
+	if !reflect.DeepEqual(schema, want) {
+		t.Errorf("schema = %+v, want %+v", schema, want)
+	}
+
+	if _, err := m.GetSchema("orders-value", "first"); err == nil {
+		t.Error("expected an error for a version that is not a number")
+	}
+	if _, err := m.GetSchema("orders-value", "4"); err == nil {
+		t.Error("expected an error for a missing version")
+	}
+}
+
+func TestSchemaLookupConcurrency(t *testing.T) {
+	release := make(chan struct{})
+	var lookups atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/schemas/ids/1":
+			lookups.Add(1)
+			<-release
+			json.NewEncoder(w).Encode(registryResponse{Schema: `"string"`})
+		case "/schemas/ids/2":
+			json.NewEncoder(w).Encode(registryResponse{Schema: `"long"`})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	registry, err := newSchemaRegistry(domain.KafkaSchemaRegistry{URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if schema := registry.schema(1); schema.err != nil {
+				t.Errorf("schema 1: %v", schema.err)
+			}
+		}()
+	}
+
+	for lookups.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Another ID is looked up while the first one hangs
+	done := make(chan *registrySchema)
+	go func() { done <- registry.schema(2) }()
+	select {
+	case schema := <-done:
+		if schema.err != nil {
+			t.Errorf("schema 2: %v", schema.err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("a slow schema lookup blocked another ID")
+	}
+
+	close(release)
+	wg.Wait()
+	if n := lookups.Load(); n != 1 {
+		t.Errorf("concurrent lookups of one ID = %d, want 1", n)
+	}
+}
@@ -0,0 +1,411 @@
+package kafka
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/oauth"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+)
+
+// applySecurityConfig sets the librdkafka security properties. Extra
+// properties from the config are applied last and override everything else.
+func applySecurityConfig(kafkaConfig kafka.ConfigMap, security domain.KafkaSecurity) {
+	protocol := strings.ToUpper(security.Protocol)
+	if protocol != "" {
+		kafkaConfig["security.protocol"] = protocol
+	}
+
+	if protocol == "SSL" || protocol == "SASL_SSL" {
+		applyTLSConfig(kafkaConfig, security.TLS)
+	}
+
+	if protocol == "SASL_SSL" || protocol == "SASL_PLAINTEXT" {
+		mechanism := strings.ToUpper(security.SASLMechanism)
+		if mechanism == "" {
+			mechanism = "PLAIN"
+		}
+		kafkaConfig["sasl.mechanism"] = mechanism
+
+		switch mechanism {
+		case "OAUTHBEARER":
+			oauthConfig := security.OAuth
+			if oauthConfig.TokenEndpointURL != "" {
+				kafkaConfig["sasl.oauthbearer.method"] = "oidc"
+				kafkaConfig["sasl.oauthbearer.token.endpoint.url"] = oauthConfig.TokenEndpointURL
+				kafkaConfig["sasl.oauthbearer.client.id"] = oauthConfig.ClientID
+				kafkaConfig["sasl.oauthbearer.client.secret"] = oauthConfig.ClientSecret
+				if oauthConfig.Scope != "" {
+					kafkaConfig["sasl.oauthbearer.scope"] = oauthConfig.Scope
+				}
+				if len(oauthConfig.Extensions) > 0 {
+					kafkaConfig["sasl.oauthbearer.extensions"] = joinExtensions(oauthConfig.Extensions)
+				}
+			}
+		case "GSSAPI":
+			kerberos := security.Kerberos
+			kafkaConfig["sasl.kerberos.service.name"] = defaultString(kerberos.ServiceName, "kafka")
+			if kerberos.Principal != "" {
+				kafkaConfig["sasl.kerberos.principal"] = kerberos.Principal
+			}
+			if kerberos.Keytab != "" {
+				kafkaConfig["sasl.kerberos.keytab"] = kerberos.Keytab
+			}
+		default:
+			kafkaConfig["sasl.username"] = security.Username
+			kafkaConfig["sasl.password"] = security.Password
+		}
+	}
+
+	for key, value := range security.Properties {
+		kafkaConfig[key] = value
+	}
+}
+
+func applyTLSConfig(kafkaConfig kafka.ConfigMap, tlsConfig domain.KafkaTLS) {
+	switch {
+	case tlsConfig.CAPEM != "":
+		kafkaConfig["ssl.ca.pem"] = tlsConfig.CAPEM
+	case tlsConfig.CALocation != "":
+		kafkaConfig["ssl.ca.location"] = tlsConfig.CALocation
+	default:
+		// Let librdkafka find the system CA bundle
+		kafkaConfig["ssl.ca.location"] = "probe"
+	}
+
+	if tlsConfig.CertPEM != "" {
+		kafkaConfig["ssl.certificate.pem"] = tlsConfig.CertPEM
+	} else if tlsConfig.CertLocation != "" {
+		kafkaConfig["ssl.certificate.location"] = tlsConfig.CertLocation
+	}
+
+	if tlsConfig.KeyPEM != "" {
+		kafkaConfig["ssl.key.pem"] = tlsConfig.KeyPEM
+	} else if tlsConfig.KeyLocation != "" {
+		kafkaConfig["ssl.key.location"] = tlsConfig.KeyLocation
+	}
+
+	if tlsConfig.KeyPassword != "" {
+		kafkaConfig["ssl.key.password"] = tlsConfig.KeyPassword
+	}
+
+	if tlsConfig.InsecureSkipVerify {
+		kafkaConfig["enable.ssl.certificate.verification"] = false
+		kafkaConfig["ssl.endpoint.identification.algorithm"] = "none"
+	}
+}
+
+func joinExtensions(extensions map[string]string) string {
+	pairs := make([]string, 0, len(extensions))
+	for key, value := range extensions {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// buildTLSConfig builds the crypto/tls configuration used by the franz-go
+// admin client from the same settings as the librdkafka clients
+func buildTLSConfig(tlsConfig domain.KafkaTLS) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: tlsConfig.InsecureSkipVerify}
+
+	caPEM, err := pemOrFile(tlsConfig.CAPEM, tlsConfig.CALocation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA: %w", err)
+	}
+	if caPEM != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in CA")
+		}
+		config.RootCAs = pool
+	}
+
+	certPEM, err := pemOrFile(tlsConfig.CertPEM, tlsConfig.CertLocation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client certificate: %w", err)
+	}
+	keyPEM, err := pemOrFile(tlsConfig.KeyPEM, tlsConfig.KeyLocation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client key: %w", err)
+	}
+
+	if certPEM != nil && keyPEM != nil {
+		if tlsConfig.KeyPassword != "" {
+			keyPEM, err = decryptKeyPEM(keyPEM, tlsConfig.KeyPassword)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+func pemOrFile(inline, path string) ([]byte, error) {
+	if inline != "" {
+		return []byte(inline), nil
+	}
+	if path == "" {
+		return nil, nil
+	}
+	return os.ReadFile(path)
+}
+
+// decryptKeyPEM decrypts an encrypted PKCS#8 private key. Legacy PEM
+// encryption (Proc-Type: 4,ENCRYPTED) is insecure and rejected.
+func decryptKeyPEM(keyPEM []byte, password string) ([]byte, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("invalid client key")
+	}
+
+	if _, legacy := block.Headers["Proc-Type"]; legacy {
+		return nil, fmt.Errorf("legacy encrypted PEM keys are not supported, convert the key with: openssl pkcs8 -topk8 -v2 aes-256-cbc")
+	}
+	if block.Type != "ENCRYPTED PRIVATE KEY" {
+		return keyPEM, nil
+	}
+
+	der, err := decryptPKCS8(block.Bytes, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt client key: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+var (
+	oidPBES2      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// decryptPKCS8 decrypts a PBES2 encrypted PKCS#8 key, as written by
+// openssl pkcs8 -topk8 -v2, using PBKDF2 and AES-CBC
+func decryptPKCS8(der []byte, password string) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("invalid encrypted key: %w", err)
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("unsupported key encryption %s, only PBES2 is supported", info.Algorithm.Algorithm)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("invalid PBES2 parameters: %w", err)
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("unsupported key derivation %s, only PBKDF2 is supported", params.KeyDerivationFunc.Algorithm)
+	}
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, fmt.Errorf("invalid PBKDF2 parameters: %w", err)
+	}
+
+	var prf func() hash.Hash
+	switch {
+	case len(kdf.PRF.Algorithm) == 0, kdf.PRF.Algorithm.Equal(oidHMACSHA1):
+		prf = sha1.New
+	case kdf.PRF.Algorithm.Equal(oidHMACSHA256):
+		prf = sha256.New
+	case kdf.PRF.Algorithm.Equal(oidHMACSHA512):
+		prf = sha512.New
+	default:
+		return nil, fmt.Errorf("unsupported PBKDF2 hash %s", kdf.PRF.Algorithm)
+	}
+
+	var keyLength int
+	switch scheme := params.EncryptionScheme.Algorithm; {
+	case scheme.Equal(oidAES128CBC):
+		keyLength = 16
+	case scheme.Equal(oidAES192CBC):
+		keyLength = 24
+	case scheme.Equal(oidAES256CBC):
+		keyLength = 32
+	default:
+		return nil, fmt.Errorf("unsupported key cipher %s, only AES-CBC is supported", scheme)
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil || len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("invalid AES-CBC parameters")
+	}
+
+	key, err := pbkdf2.Key(prf, password, kdf.Salt, kdf.IterationCount, keyLength)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data := info.EncryptedData
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("invalid encrypted key length")
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+
+	// A wrong password shows up as bad padding or an unparsable key
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, fmt.Errorf("wrong key password")
+	}
+	for _, b := range plain[len(plain)-padding:] {
+		if int(b) != padding {
+			return nil, fmt.Errorf("wrong key password")
+		}
+	}
+	plain = plain[:len(plain)-padding]
+	if _, err := x509.ParsePKCS8PrivateKey(plain); err != nil {
+		return nil, fmt.Errorf("wrong key password")
+	}
+
+	return plain, nil
+}
+
+// buildSASLMechanism returns the franz-go SASL mechanism for the configured
+// security settings. GSSAPI is only available to the librdkafka clients.
+func buildSASLMechanism(security domain.KafkaSecurity) (sasl.Mechanism, error) {
+	username := security.Username
+	password := security.Password
+
+	switch strings.ToUpper(security.SASLMechanism) {
+	case "", "PLAIN":
+		return plain.Auth{User: username, Pass: password}.AsMechanism(), nil
+	case "SCRAM-SHA-256":
+		return scram.Auth{User: username, Pass: password}.AsSha256Mechanism(), nil
+	case "SCRAM-SHA-512":
+		return scram.Auth{User: username, Pass: password}.AsSha512Mechanism(), nil
+	case "OAUTHBEARER":
+		if security.OAuth.TokenEndpointURL == "" {
+			return nil, fmt.Errorf("oauth token_endpoint_url is required")
+		}
+		source := &oauthTokenSource{config: security.OAuth}
+		return oauth.Oauth(source.auth), nil
+	case "GSSAPI":
+		return nil, fmt.Errorf("sasl mechanism GSSAPI is only supported by the librdkafka clients")
+	default:
+		return nil, fmt.Errorf("unsupported sasl mechanism: %s", security.SASLMechanism)
+	}
+}
+
+// oauthTokenSource fetches and caches tokens with the client credentials flow
+type oauthTokenSource struct {
+	config domain.KafkaOAuth
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (s *oauthTokenSource) auth(ctx context.Context) (oauth.Auth, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Refresh a little before expiry
+	if s.token == "" || time.Now().After(s.expires.Add(-30*time.Second)) {
+		if err := s.refresh(ctx); err != nil {
+			return oauth.Auth{}, err
+		}
+	}
+
+	return oauth.Auth{Token: s.token, Extensions: s.config.Extensions}, nil
+}
+
+func (s *oauthTokenSource) refresh(ctx context.Context) error {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if s.config.Scope != "" {
+		form.Set("scope", s.config.Scope)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.TokenEndpointURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(s.config.ClientSecret))
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch oauth token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch oauth token: %s", resp.Status)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("invalid oauth token response: %w", err)
+	}
+	if body.AccessToken == "" {
+		return fmt.Errorf("oauth token response has no access_token")
+	}
+
+	s.token = body.AccessToken
+	s.expires = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	if body.ExpiresIn <= 0 {
+		s.expires = time.Now().Add(5 * time.Minute)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"golang.org/x/time/rate"
+)
+
+// Rate cap of a live tail in messages per second
+const (
+	defaultTailRate = 50
+	maxTailRate     = 1000
+)
+
+// TailTopics streams new messages of the given topics to emit until ctx is
+// done, the cluster manager is closed or emit fails. The consumer is assigned
+// every partition at the latest offset and never joins a group or commits.
+// Messages over the rate cap are dropped and reported once per second.
+func (m *ClusterManager) TailTopics(ctx context.Context, req domain.KafkaTailRequest, emit func(domain.KafkaTailEvent) error) error {
+	keyFilter, valueFilter, err := tailFilters(req)
+	if err != nil {
+		return err
+	}
+
+	limit := req.MaxPerSec
+	if limit <= 0 {
+		limit = defaultTailRate
+	}
+	if limit > maxTailRate {
+		return fmt.Errorf("rate must be at most %d messages per second", maxTailRate)
+	}
+
+	assignment, err := m.tailAssignment(req.Topics)
+	if err != nil {
+		return err
+	}
+
+	consumerConfig := m.readerConfig("tail")
+	consumer, err := kafka.NewConsumer(&consumerConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create tail consumer: %w", err)
+	}
+	defer consumer.Close()
+
+	if err := consumer.Assign(assignment); err != nil {
+		return fmt.Errorf("failed to assign tail partitions: %w", err)
+	}
+
+	log.Printf("Tailing %v on cluster %s at %d messages/sec", req.Topics, m.name, limit)
+
+	limiter := rate.NewLimiter(rate.Limit(limit), limit)
+	var dropped int64
+	lastReport := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-m.ctx.Done():
+			return fmt.Errorf("cluster %s was closed", m.name)
+		default:
+		}
+
+		if dropped > 0 && time.Since(lastReport) >= time.Second {
+			if err := emit(domain.KafkaTailEvent{Type: "dropped", Dropped: dropped}); err != nil {
+				return nil
+			}
+			dropped = 0
+			lastReport = time.Now()
+		}
+
+		switch e := consumer.Poll(100).(type) {
+		case *kafka.Message:
+			message := m.decodeMessage(e)
+			if !tailMatches(keyFilter, message.Key) || !tailMatches(valueFilter, message.Value) {
+				continue
+			}
+			if !limiter.Allow() {
+				dropped++
+				continue
+			}
+			if err := emit(domain.KafkaTailEvent{Type: "message", Message: message}); err != nil {
+				return nil
+			}
+		case kafka.Error:
+			if e.IsFatal() {
+				return fmt.Errorf("tail consumer failed: %w", e)
+			}
+			log.Printf("Error tailing topics on cluster %s: %v", m.name, e)
+		}
+	}
+}
+
+// tailAssignment returns every partition of the topics at the latest offset
+func (m *ClusterManager) tailAssignment(topics []string) ([]kafka.TopicPartition, error) {
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("at least one topic is required")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+
+	metadata, err := m.adminClient.GetMetadata(nil, true, 3000)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster metadata: %w", err)
+	}
+
+	assignment := make([]kafka.TopicPartition, 0)
+	for i := range topics {
+		topic, exists := metadata.Topics[topics[i]]
+		if !exists || topic.Error.Code() != kafka.ErrNoError {
+			return nil, fmt.Errorf("topic %s not found", topics[i])
+		}
+		for _, partition := range topic.Partitions {
+			assignment = append(assignment, kafka.TopicPartition{
+				Topic:     &topics[i],
+				Partition: partition.ID,
+				Offset:    kafka.OffsetEnd,
+			})
+		}
+	}
+
+	return assignment, nil
+}
+
+func tailFilters(req domain.KafkaTailRequest) (*regexp.Regexp, *regexp.Regexp, error) {
+	var keyFilter, valueFilter *regexp.Regexp
+	var err error
+	if req.KeyFilter != "" {
+		if keyFilter, err = regexp.Compile(req.KeyFilter); err != nil {
+			return nil, nil, fmt.Errorf("invalid key filter: %w", err)
+		}
+	}
+	if req.ValueFilter != "" {
+		if valueFilter, err = regexp.Compile(req.ValueFilter); err != nil {
+			return nil, nil, fmt.Errorf("invalid value filter: %w", err)
+		}
+	}
+	return keyFilter, valueFilter, nil
+}
+
+func tailMatches(filter *regexp.Regexp, text string) bool {
+	return filter == nil || filter.MatchString(text)
+}
@@ -0,0 +1,195 @@
+package kafka
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+// watermarkSample is a partition's high watermark observed at a point in time.
+type watermarkSample struct {
+	highWatermark int64
+	at            time.Time
+	rate          float64
+}
+
+// updateThroughput records a partition's high watermark and returns the
+// produce rate (messages/sec) since the previous poll.
+func (m *ClusterManager) updateThroughput(topic string, partition int32, high int64, now time.Time) float64 {
+	m.samplesMu.Lock()
+	defer m.samplesMu.Unlock()
+
+	key := partitionKey(topic, partition)
+	prev, ok := m.watermarkSamples[key]
+	if !ok {
+		m.watermarkSamples[key] = watermarkSample{highWatermark: high, at: now}
+		return 0
+	}
+
+	elapsed := now.Sub(prev.at).Seconds()
+	if elapsed < minSampleInterval.Seconds() {
+		return prev.rate
+	}
+
+	rate := float64(high-prev.highWatermark) / elapsed
+	if rate < 0 {
+		rate = 0 // topic was recreated
+	}
+
+	m.watermarkSamples[key] = watermarkSample{highWatermark: high, at: now, rate: rate}
+	return rate
+}
+
+// forgetWatermarkSamples drops the samples of partitions missing from a poll
+func (m *ClusterManager) forgetWatermarkSamples(seen map[string]bool) {
+	m.samplesMu.Lock()
+	defer m.samplesMu.Unlock()
+
+	for key := range m.watermarkSamples {
+		if !seen[key] {
+			delete(m.watermarkSamples, key)
+		}
+	}
+}
+
+// listOffsets resolves spec for every given partition in a single request.
+// Offsets are keyed by partitionKey.
+func (m *ClusterManager) listOffsets(partitions []kafka.TopicPartition, spec kafka.OffsetSpec, options ...kafka.ListOffsetsAdminOption) (map[string]int64, error) {
+	offsets := make(map[string]int64)
+	if len(partitions) == 0 {
+		return offsets, nil
+	}
+
+	request := make(map[kafka.TopicPartition]kafka.OffsetSpec, len(partitions))
+	for _, tp := range partitions {
+		request[tp] = spec
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 3*time.Second)
+	defer cancel()
+
+	result, err := m.adminClient.ListOffsets(ctx, request, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	for tp, info := range result.ResultInfos {
+		if info.Error.Code() != kafka.ErrNoError {
+			continue
+		}
+		offsets[partitionKey(*tp.Topic, tp.Partition)] = int64(info.Offset)
+	}
+
+	return offsets, nil
+}
+
+// applyThroughput samples the high watermark of every partition of the given
+// topics and fills message rates per partition and per topic. Byte rates are
+// estimated from the average message size on the leaders' log dirs, and the
+// topic size is the sum of all its replicas.
+func (m *ClusterManager) applyThroughput(metrics []domain.KafkaTopicMetrics, metadata *kafka.Metadata) {
+	partitions := make([]kafka.TopicPartition, 0)
+	var topicsSet kadm.TopicsSet
+
+	for _, metric := range metrics {
+		topicName := metric.Name
+		for _, partition := range metadata.Topics[topicName].Partitions {
+			partitions = append(partitions, kafka.TopicPartition{Topic: &topicName, Partition: partition.ID})
+			topicsSet.Add(topicName, partition.ID)
+		}
+	}
+
+	highs, err := m.listOffsets(partitions, kafka.LatestOffsetSpec)
+	if err != nil {
+		log.Printf("Error listing high watermarks: %v", err)
+		return
+	}
+	lows, err := m.listOffsets(partitions, kafka.EarliestOffsetSpec)
+	if err != nil {
+		log.Printf("Error listing low watermarks: %v", err)
+		lows = make(map[string]int64)
+	}
+	sizes := m.getReplicaLogSizes(topicsSet)
+
+	now := time.Now()
+	seen := make(map[string]bool, len(highs))
+	for i := range metrics {
+		metric := &metrics[i]
+		var leaderSize, totalMessages int64
+		metric.TotalSize = 0
+		rates := make([]domain.KafkaPartitionRate, 0)
+
+		for _, partition := range metadata.Topics[metric.Name].Partitions {
+			key := partitionKey(metric.Name, partition.ID)
+			for _, size := range sizes[key] {
+				metric.TotalSize += size
+			}
+
+			high, ok := highs[key]
+			if !ok {
+				continue
+			}
+			seen[key] = true
+
+			if size, ok := sizes[key][partition.Leader]; ok {
+				if low, ok := lows[key]; ok && high > low {
+					leaderSize += size
+					totalMessages += high - low
+				}
+			}
+
+			rates = append(rates, domain.KafkaPartitionRate{
+				Partition:      partition.ID,
+				Leader:         partition.Leader,
+				HighWatermark:  high,
+				MessagesPerSec: m.updateThroughput(metric.Name, partition.ID, high, now),
+			})
+		}
+
+		if totalMessages > 0 {
+			metric.AvgMessageBytes = float64(leaderSize) / float64(totalMessages)
+		}
+
+		for j := range rates {
+			rates[j].BytesInPerSec = rates[j].MessagesPerSec * metric.AvgMessageBytes
+			metric.MessagesPerSec += rates[j].MessagesPerSec
+			metric.BytesInPerSec += rates[j].BytesInPerSec
+		}
+		metric.PartitionRates = rates
+	}
+
+	m.forgetWatermarkSamples(seen)
+}
+
+// applyConsumerThroughput estimates bytes out per partition, topic and
+// consumer group from the consumption rates of the monitored consumer groups.
+func applyConsumerThroughput(topics []domain.KafkaTopicMetrics, groups []domain.KafkaConsumerMetrics) {
+	avgMessageBytes := make(map[string]float64, len(topics))
+	for _, topic := range topics {
+		avgMessageBytes[topic.Name] = topic.AvgMessageBytes
+	}
+
+	consumeRates := make(map[string]float64)
+	for i := range groups {
+		group := &groups[i]
+		group.ConsumeBytesPerSec = 0
+		for _, topicLag := range group.TopicLags {
+			consumeRates[partitionKey(topicLag.Topic, topicLag.Partition)] += topicLag.ConsumeRate
+			group.ConsumeBytesPerSec += topicLag.ConsumeRate * avgMessageBytes[topicLag.Topic]
+		}
+	}
+
+	for i := range topics {
+		topic := &topics[i]
+		topic.BytesOutPerSec = 0
+		for j := range topic.PartitionRates {
+			rate := &topic.PartitionRates[j]
+			rate.BytesOutPerSec = consumeRates[partitionKey(topic.Name, rate.Partition)] * topic.AvgMessageBytes
+			topic.BytesOutPerSec += rate.BytesOutPerSec
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// PlanTopics compares the declared topic specs with the live cluster
+func (m *ClusterManager) PlanTopics(specs []domain.KafkaTopicSpec) (*domain.KafkaTopicPlan, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+
+	return m.planTopics(specs)
+}
+
+// ApplyTopics plans the declared topic specs and executes the safe part of
+// the plan: creates, partition increases and config changes. Replication
+// factor changes, partition decreases and undeclared topics are only reported.
+func (m *ClusterManager) ApplyTopics(specs []domain.KafkaTopicSpec) (*domain.KafkaTopicApplyResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+
+	plan, err := m.planTopics(specs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.KafkaTopicApplyResult{
+		Plan:    plan,
+		Actions: make([]domain.KafkaTopicAction, 0),
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 30*time.Second)
+	defer cancel()
+
+	if len(plan.Creates) > 0 {
+		names := make([]string, 0, len(plan.Creates))
+		topics := make([]kafka.TopicSpecification, 0, len(plan.Creates))
+		for _, spec := range plan.Creates {
+			names = append(names, spec.Name)
+			topics = append(topics, kafka.TopicSpecification{
+				Topic:             spec.Name,
+				NumPartitions:     spec.Partitions,
+				ReplicationFactor: spec.ReplicationFactor,
+				Config:            spec.Configs,
+			})
+		}
+		results, err := m.adminClient.CreateTopics(ctx, topics)
+		result.Actions = append(result.Actions, topicActions("create", names, results, err)...)
+	}
+
+	if len(plan.PartitionIncreases) > 0 {
+		names := make([]string, 0, len(plan.PartitionIncreases))
+		partitions := make([]kafka.PartitionsSpecification, 0, len(plan.PartitionIncreases))
+		for _, increase := range plan.PartitionIncreases {
+			names = append(names, increase.Topic)
+			partitions = append(partitions, kafka.PartitionsSpecification{
+				Topic:      increase.Topic,
+				IncreaseTo: increase.To,
+			})
+		}
+		results, err := m.adminClient.CreatePartitions(ctx, partitions)
+		result.Actions = append(result.Actions, topicActions("add_partitions", names, results, err)...)
+	}
+
+	if len(plan.ConfigChanges) > 0 {
+		byTopic := make(map[string][]kafka.ConfigEntry)
+		topics := make([]string, 0)
+		for _, change := range plan.ConfigChanges {
+			if _, ok := byTopic[change.Topic]; !ok {
+				topics = append(topics, change.Topic)
+			}
+			byTopic[change.Topic] = append(byTopic[change.Topic], kafka.ConfigEntry{
+				Name:                 change.Key,
+				Value:                change.To,
+				IncrementalOperation: kafka.AlterConfigOpTypeSet,
+			})
+		}
+
+		resources := make([]kafka.ConfigResource, 0, len(topics))
+		for _, topic := range topics {
+			resources = append(resources, kafka.ConfigResource{
+				Type:   kafka.ResourceTopic,
+				Name:   topic,
+				Config: byTopic[topic],
+			})
+		}
+
+		results, err := m.adminClient.IncrementalAlterConfigs(ctx, resources)
+		if err != nil {
+			for _, topic := range topics {
+				result.Actions = append(result.Actions, domain.KafkaTopicAction{Topic: topic, Action: "alter_configs", Error: err.Error()})
+			}
+		}
+		for _, configResult := range results {
+			action := domain.KafkaTopicAction{Topic: configResult.Name, Action: "alter_configs"}
+			if configResult.Error.Code() != kafka.ErrNoError {
+				action.Error = configResult.Error.String()
+			}
+			result.Actions = append(result.Actions, action)
+		}
+	}
+
+	failed := 0
+	for _, action := range result.Actions {
+		if action.Error != "" {
+			failed++
+		}
+	}
+	log.Printf("Applied topic specs on cluster %s: %d actions, %d failed", m.name, len(result.Actions), failed)
+
+	return result, nil
+}
+
+func (m *ClusterManager) planTopics(specs []domain.KafkaTopicSpec) (*domain.KafkaTopicPlan, error) {
+	metadata, err := m.adminClient.GetMetadata(nil, true, 3000)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster metadata: %w", err)
+	}
+
+	plan := &domain.KafkaTopicPlan{
+		Cluster:            m.name,
+		Creates:            make([]domain.KafkaTopicSpec, 0),
+		PartitionIncreases: make([]domain.KafkaPartitionIncrease, 0),
+		ConfigChanges:      make([]domain.KafkaTopicConfigChange, 0),
+		Unsafe:             make([]domain.KafkaTopicPlanIssue, 0),
+		Undeclared:         make([]string, 0),
+	}
+
+	declared := make(map[string]bool, len(specs))
+	existing := make([]domain.KafkaTopicSpec, 0, len(specs))
+	for _, spec := range specs {
+		declared[spec.Name] = true
+
+		topic, exists := metadata.Topics[spec.Name]
+		switch {
+		case !exists || topic.Error.Code() == kafka.ErrUnknownTopicOrPart:
+			plan.Creates = append(plan.Creates, spec)
+		case topic.Error.Code() != kafka.ErrNoError:
+			plan.Unsafe = append(plan.Unsafe, domain.KafkaTopicPlanIssue{
+				Topic:  spec.Name,
+				Reason: fmt.Sprintf("topic has error: %v", topic.Error),
+			})
+		default:
+			existing = append(existing, spec)
+		}
+	}
+
+	names := make([]string, 0, len(existing))
+	for _, spec := range existing {
+		names = append(names, spec.Name)
+	}
+	liveConfigs := m.describeTopicConfigs(names)
+
+	for _, spec := range existing {
+		topic := metadata.Topics[spec.Name]
+
+		partitions := len(topic.Partitions)
+		if spec.Partitions > partitions {
+			plan.PartitionIncreases = append(plan.PartitionIncreases, domain.KafkaPartitionIncrease{
+				Topic: spec.Name,
+				From:  partitions,
+				To:    spec.Partitions,
+			})
+		} else if spec.Partitions < partitions {
+			plan.Unsafe = append(plan.Unsafe, domain.KafkaTopicPlanIssue{
+				Topic:  spec.Name,
+				Reason: fmt.Sprintf("declares %d partitions but has %d, partitions cannot be removed", spec.Partitions, partitions),
+			})
+		}
+
+		if spec.ReplicationFactor > 0 && partitions > 0 {
+			if replicas := len(topic.Partitions[0].Replicas); replicas != spec.ReplicationFactor {
+				plan.Unsafe = append(plan.Unsafe, domain.KafkaTopicPlanIssue{
+					Topic:  spec.Name,
+					Reason: fmt.Sprintf("declares replication factor %d but has %d, change it with a reassignment", spec.ReplicationFactor, replicas),
+				})
+			}
+		}
+
+		if len(spec.Configs) == 0 {
+			continue
+		}
+		live, ok := liveConfigs[spec.Name]
+		if !ok {
+			plan.Unsafe = append(plan.Unsafe, domain.KafkaTopicPlanIssue{
+				Topic:  spec.Name,
+				Reason: "configs could not be described",
+			})
+			continue
+		}
+
+		keys := make([]string, 0, len(spec.Configs))
+		for key := range spec.Configs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if live[key] != spec.Configs[key] {
+				plan.ConfigChanges = append(plan.ConfigChanges, domain.KafkaTopicConfigChange{
+					Topic: spec.Name,
+					Key:   key,
+					From:  live[key],
+					To:    spec.Configs[key],
+				})
+			}
+		}
+	}
+
+	for name := range metadata.Topics {
+		// Internal topics are managed by Kafka itself
+		if !declared[name] && !strings.HasPrefix(name, "__") {
+			plan.Undeclared = append(plan.Undeclared, name)
+		}
+	}
+
+	sort.Slice(plan.Creates, func(i, j int) bool {
+		return plan.Creates[i].Name < plan.Creates[j].Name
+	})
+	sort.Slice(plan.PartitionIncreases, func(i, j int) bool {
+		return plan.PartitionIncreases[i].Topic < plan.PartitionIncreases[j].Topic
+	})
+	sort.SliceStable(plan.ConfigChanges, func(i, j int) bool {
+		return plan.ConfigChanges[i].Topic < plan.ConfigChanges[j].Topic
+	})
+	sort.SliceStable(plan.Unsafe, func(i, j int) bool {
+		return plan.Unsafe[i].Topic < plan.Unsafe[j].Topic
+	})
+	sort.Strings(plan.Undeclared)
+
+	return plan, nil
+}
+
+// topicActions converts per-topic admin results into apply actions. When the
+// request itself failed, every topic gets its error.
+func topicActions(action string, topics []string, results []kafka.TopicResult, err error) []domain.KafkaTopicAction {
+	actions := make([]domain.KafkaTopicAction, 0, len(topics))
+	if err != nil {
+		for _, topic := range topics {
+			actions = append(actions, domain.KafkaTopicAction{Topic: topic, Action: action, Error: err.Error()})
+		}
+		return actions
+	}
+
+	for _, result := range results {
+		topicAction := domain.KafkaTopicAction{Topic: result.Topic, Action: action}
+		if result.Error.Code() != kafka.ErrNoError {
+			topicAction.Error = result.Error.String()
+		}
+		actions = append(actions, topicAction)
+	}
+	return actions
+}
@@ -0,0 +1,333 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/twmb/franz-go/pkg/kadm"
+)
+
+// Transactions open longer than the broker default transaction.max.timeout.ms
+// should have been aborted by their coordinator
+const hangingTransactionAge = 15 * time.Minute
+
+// Transaction states in which a transaction is still open
+var openTransactionStates = []string{"Ongoing", "PrepareCommit", "PrepareAbort", "PrepareEpochFence"}
+
+// GetTransactions lists and describes transactions in the given states.
+// No states means every open transaction.
+func (m *ClusterManager) GetTransactions(states []string) ([]domain.KafkaTransaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+	if m.extAdmin == nil {
+		return nil, m.extAdminUnavailable("listing transactions")
+	}
+
+	if len(states) == 0 {
+		states = openTransactionStates
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+	defer cancel()
+
+	listed, err := m.extAdmin.ListTransactions(ctx, nil, states)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	transactions := make([]domain.KafkaTransaction, 0, len(listed))
+	if len(listed) == 0 {
+		return transactions, nil
+	}
+
+	described, err := m.extAdmin.DescribeTransactions(ctx, listed.TransactionalIDs()...)
+	if err != nil {
+		log.Printf("Error describing transactions: %v", err)
+	}
+
+	now := time.Now()
+	for _, l := range listed.Sorted() {
+		transaction := domain.KafkaTransaction{
+			TransactionalID: l.TxnID,
+			Coordinator:     l.Coordinator,
+			State:           l.State,
+			ProducerID:      l.ProducerID,
+			Partitions:      make([]domain.KafkaTopicAssignment, 0),
+		}
+
+		d, ok := described[l.TxnID]
+		switch {
+		case !ok:
+			transaction.Error = "transaction could not be described"
+		case d.Err != nil:
+			transaction.Error = d.Err.Error()
+		default:
+			transaction.State = d.State
+			transaction.ProducerID = d.ProducerID
+			transaction.ProducerEpoch = d.ProducerEpoch
+			transaction.TimeoutMs = d.TimeoutMillis
+			if d.StartTimestamp >= 0 {
+				start := time.UnixMilli(d.StartTimestamp)
+				transaction.StartTime = &start
+				transaction.OpenSeconds = now.Sub(start).Seconds()
+			}
+			for _, topic := range d.Topics.Sorted() {
+				transaction.Partitions = append(transaction.Partitions, domain.KafkaTopicAssignment{
+					Topic:      topic.Topic,
+					Partitions: topic.Partitions,
+				})
+			}
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+// GetTopicProducers returns the active idempotent and transactional producers
+// of every partition of a topic, with hanging transactions flagged
+func (m *ClusterManager) GetTopicProducers(topicName string) ([]domain.KafkaPartitionProducers, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+	if m.extAdmin == nil {
+		return nil, m.extAdminUnavailable("describing producers")
+	}
+
+	metadata, err := m.adminClient.GetMetadata(&topicName, false, 3000)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topic metadata: %w", err)
+	}
+	topic, exists := metadata.Topics[topicName]
+	if !exists || topic.Error.Code() != kafka.ErrNoError {
+		return nil, fmt.Errorf("topic %s not found", topicName)
+	}
+
+	var topicsSet kadm.TopicsSet
+	for _, partition := range topic.Partitions {
+		topicsSet.Add(topicName, partition.ID)
+	}
+
+	return m.describeProducers(topicsSet)
+}
+
+// GetHangingTransactions returns the partitions with hanging transactions,
+// listing only the hanging producers. No topics means every topic.
+func (m *ClusterManager) GetHangingTransactions(topics []string) ([]domain.KafkaPartitionProducers, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.connected || m.adminClient == nil {
+		return nil, fmt.Errorf("kafka admin client not connected")
+	}
+	if m.extAdmin == nil {
+		return nil, m.extAdminUnavailable("describing producers")
+	}
+
+	var topicsSet kadm.TopicsSet
+	if len(topics) > 0 {
+		metadata, err := m.adminClient.GetMetadata(nil, true, 3000)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cluster metadata: %w", err)
+		}
+		for _, name := range topics {
+			topic, exists := metadata.Topics[name]
+			if !exists || topic.Error.Code() != kafka.ErrNoError {
+				return nil, fmt.Errorf("topic %s not found", name)
+			}
+			for _, partition := range topic.Partitions {
+				topicsSet.Add(name, partition.ID)
+			}
+		}
+	}
+
+	partitions, err := m.describeProducers(topicsSet)
+	if err != nil {
+		return nil, err
+	}
+
+	hanging := make([]domain.KafkaPartitionProducers, 0)
+	for _, partition := range partitions {
+		producers := make([]domain.KafkaProducerState, 0)
+		for _, producer := range partition.Producers {
+			if producer.Hanging {
+				producers = append(producers, producer)
+			}
+		}
+		if len(producers) > 0 {
+			partition.Producers = producers
+			hanging = append(hanging, partition)
+		}
+	}
+
+	return hanging, nil
+}
+
+// describeProducers describes the producers of the given partitions, an
+// empty set meaning every partition, and fills watermarks and hanging flags
+func (m *ClusterManager) describeProducers(topics kadm.TopicsSet) ([]domain.KafkaPartitionProducers, error) {
+	ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+	defer cancel()
+
+	described, err := m.extAdmin.DescribeProducers(ctx, topics)
+	if err != nil && len(described) == 0 {
+		return nil, fmt.Errorf("failed to describe producers: %w", err)
+	}
+	if err != nil {
+		log.Printf("Error describing producers: %v", err)
+	}
+
+	sorted := described.SortedPartitions()
+
+	names := make([]string, len(sorted))
+	tps := make([]kafka.TopicPartition, 0, len(sorted))
+	for i := range sorted {
+		names[i] = sorted[i].Topic
+		tps = append(tps, kafka.TopicPartition{Topic: &names[i], Partition: sorted[i].Partition})
+	}
+
+	highs, err := m.listOffsets(tps, kafka.LatestOffsetSpec)
+	if err != nil {
+		log.Printf("Error listing high watermarks: %v", err)
+	}
+	stables, err := m.listOffsets(tps, kafka.LatestOffsetSpec, kafka.SetAdminIsolationLevel(kafka.IsolationLevelReadCommitted))
+	if err != nil {
+		log.Printf("Error listing last stable offsets: %v", err)
+	}
+
+	partitions := make([]domain.KafkaPartitionProducers, 0, len(sorted))
+	for _, p := range sorted {
+		key := partitionKey(p.Topic, p.Partition)
+		partition := domain.KafkaPartitionProducers{
+			Topic:            p.Topic,
+			Partition:        p.Partition,
+			Leader:           p.Leader,
+			HighWatermark:    highs[key],
+			LastStableOffset: stables[key],
+			Producers:        make([]domain.KafkaProducerState, 0, len(p.ActiveProducers)),
+		}
+		if p.Err != nil {
+			partition.Error = p.Err.Error()
+		}
+		if high, ok := highs[key]; ok {
+			if stable, ok := stables[key]; ok && high > stable {
+				partition.Uncommitted = high - stable
+			}
+		}
+
+		for _, producer := range p.ActiveProducers.Sorted() {
+			state := domain.KafkaProducerState{
+				ProducerID:       producer.ProducerID,
+				ProducerEpoch:    producer.ProducerEpoch,
+				LastSequence:     producer.LastSequence,
+				LastTimestamp:    time.UnixMilli(producer.LastTimestamp),
+				CoordinatorEpoch: producer.CoordinatorEpoch,
+			}
+			if producer.CurrentTxnStartOffset >= 0 {
+				start := producer.CurrentTxnStartOffset
+				state.TxnStartOffset = &start
+				_, hasStable := stables[key]
+				state.BlocksLSO = hasStable && start == partition.LastStableOffset
+			}
+			partition.Producers = append(partition.Producers, state)
+		}
+
+		partitions = append(partitions, partition)
+	}
+
+	m.markHangingTransactions(partitions)
+
+	return partitions, nil
+}
+
+// markHangingTransactions flags open transactions that their coordinator no
+// longer tracks for the partition, the same check as kafka-transactions.sh
+// find-hanging. Only transactions older than the maximum transaction timeout
+// are checked.
+func (m *ClusterManager) markHangingTransactions(partitions []domain.KafkaPartitionProducers) {
+	cutoff := time.Now().Add(-hangingTransactionAge)
+
+	producerIDs := make([]int64, 0)
+	seen := make(map[int64]bool)
+	for _, partition := range partitions {
+		for _, producer := range partition.Producers {
+			if producer.TxnStartOffset != nil && producer.LastTimestamp.Before(cutoff) && !seen[producer.ProducerID] {
+				seen[producer.ProducerID] = true
+				producerIDs = append(producerIDs, producer.ProducerID)
+			}
+		}
+	}
+	if len(producerIDs) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+	defer cancel()
+
+	listed, err := m.extAdmin.ListTransactions(ctx, producerIDs, nil)
+	if err != nil {
+		log.Printf("Error listing transactions of open producers: %v", err)
+		return
+	}
+
+	transactionalIDs := make(map[int64]string, len(listed))
+	for _, l := range listed {
+		transactionalIDs[l.ProducerID] = l.TxnID
+	}
+
+	var described kadm.DescribedTransactions
+	if len(listed) > 0 {
+		described, err = m.extAdmin.DescribeTransactions(ctx, listed.TransactionalIDs()...)
+		if err != nil {
+			log.Printf("Error describing transactions of open producers: %v", err)
+		}
+	}
+
+	for i := range partitions {
+		partition := &partitions[i]
+		for j := range partition.Producers {
+			producer := &partition.Producers[j]
+			if !seen[producer.ProducerID] || producer.TxnStartOffset == nil || !producer.LastTimestamp.Before(cutoff) {
+				continue
+			}
+
+			txnID, ok := transactionalIDs[producer.ProducerID]
+			if !ok {
+				producer.Hanging = true
+				producer.HangingReason = "no transactional ID uses this producer ID"
+				continue
+			}
+			producer.TransactionalID = txnID
+
+			txn, ok := described[txnID]
+			if !ok || txn.Err != nil {
+				// Cannot tell without the coordinator's view
+				continue
+			}
+			_, inTransaction := txn.Topics[partition.Topic][partition.Partition]
+			switch {
+			case txn.ProducerID != producer.ProducerID:
+				producer.Hanging = true
+				producer.HangingReason = fmt.Sprintf("transactional ID now uses producer ID %d", txn.ProducerID)
+			case txn.State != "Ongoing":
+				producer.Hanging = true
+				producer.HangingReason = fmt.Sprintf("coordinator reports the transaction as %s", txn.State)
+			case !inTransaction:
+				producer.Hanging = true
+				producer.HangingReason = "coordinator does not include this partition in the transaction"
+			}
+		}
+	}
+}
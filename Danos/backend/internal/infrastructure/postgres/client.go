@@ -107,6 +107,21 @@ func (m *PostgresManager) GetClient(name string) (*sql.DB, error) {
 	return client, nil
 }
 
+// GetDatabaseConfig returns the configuration of a named database
+func (m *PostgresManager) GetDatabaseConfig(name string) (domain.PostgreSQLDatabase, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.config != nil {
+		for _, dbConfig := range m.config.Databases {
+			if dbConfig.Name == name {
+				return dbConfig, nil
+			}
+		}
+	}
+	return domain.PostgreSQLDatabase{}, fmt.Errorf("postgres database %s not configured", name)
+}
+
 func (m *PostgresManager) GetAllClients() map[string]*sql.DB {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
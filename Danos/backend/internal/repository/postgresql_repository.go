@@ -2,11 +2,28 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"time"
 
 	"github.com/Danos/backend/internal/domain"
 )
 
+// Longest query text returned with a session or statement
+const (
+	maxSessionQueryLength   = 1024
+	maxStatementQueryLength = 4096
+)
+
+// ErrStatementsUnavailable is returned when pg_stat_statements is not
+// installed in the database
+var ErrStatementsUnavailable = errors.New("pg_stat_statements is not installed")
+
+// ErrSessionNotFound is returned when signalling a PID that is not a client
+// session of the current database
+var ErrSessionNotFound = errors.New("session not found")
+
 type PostgresRepository struct {
 	db *sql.DB
 }
@@ -57,15 +74,353 @@ func (r *PostgresRepository) GetMetrics(name string, schema string) (domain.Post
 		log.Printf("Error getting PostgreSQL uptime: %v", err)
 	}
 
-	// Active connections
+	metric.Uptime = metric.UptimeSeconds
+	metric.DatabaseSize = metric.SizeBytes
+	metric.Timestamp = time.Now()
+
+	// Connections of the current database by state. Lock waits are the
+	// waiting queries. The server wide count is what max_connections limits.
+	var serverConnections int
 	err = r.db.QueryRow(`
-		SELECT COUNT(*)
+		SELECT
+			COUNT(*) FILTER (WHERE datname = current_database()),
+			COUNT(*) FILTER (WHERE datname = current_database() AND state = 'active'),
+			COUNT(*) FILTER (WHERE datname = current_database() AND state = 'idle'),
+			COUNT(*) FILTER (WHERE datname = current_database() AND state IN ('idle in transaction', 'idle in transaction (aborted)')),
+			COUNT(*) FILTER (WHERE datname = current_database() AND state = 'active' AND pid <> pg_backend_pid()),
+			COUNT(*) FILTER (WHERE datname = current_database() AND state = 'active' AND wait_event_type = 'Lock'),
+			COUNT(*)
 		FROM pg_stat_activity
+		WHERE backend_type = 'client backend'
+	`).Scan(
+		&metric.Connections,
+		&metric.ActiveConnections,
+		&metric.IdleConnections,
+		&metric.IdleInTransaction,
+		&metric.ActiveQueries,
+		&metric.WaitingQueries,
+		&serverConnections,
+	)
+	if err != nil {
+		log.Printf("Error getting PostgreSQL connections: %v", err)
+	}
+
+	// Max connections
+	err = r.db.QueryRow(`
+		SELECT setting::int
+		FROM pg_settings
+		WHERE name = 'max_connections'
+	`).Scan(&metric.MaxConnections)
+	if err != nil {
+		log.Printf("Error getting PostgreSQL max connections: %v", err)
+	}
+	if metric.MaxConnections > 0 {
+		metric.ConnectionPercent = float64(serverConnections) / float64(metric.MaxConnections) * 100
+	}
+
+	// Database statistics
+	err = r.db.QueryRow(`
+		SELECT
+			xact_commit, xact_rollback,
+			blks_read, blks_hit,
+			tup_returned, tup_fetched, tup_inserted, tup_updated, tup_deleted,
+			conflicts, temp_files, temp_bytes, deadlocks
+		FROM pg_stat_database
 		WHERE datname = current_database()
-	`).Scan(&metric.ActiveConnections)
+	`).Scan(
+		&metric.Commits, &metric.Rollbacks,
+		&metric.BlocksRead, &metric.BlocksHit,
+		&metric.TuplesReturned, &metric.TuplesFetched, &metric.TuplesInserted, &metric.TuplesUpdated, &metric.TuplesDeleted,
+		&metric.ConflictCount, &metric.TempFiles, &metric.TempBytes, &metric.Deadlocks,
+	)
 	if err != nil {
-		log.Printf("Error getting PostgreSQL active connections: %v", err)
+		log.Printf("Error getting PostgreSQL database statistics: %v", err)
+	}
+	if total := metric.BlocksHit + metric.BlocksRead; total > 0 {
+		metric.CacheHitRatio = float64(metric.BlocksHit) / float64(total) * 100
+	}
+
+	// Statements executed, when pg_stat_statements is installed
+	if metric.Statements, _, err = r.statementCount(); err != nil {
+		log.Printf("Error getting PostgreSQL statement count: %v", err)
+	}
+
+	// Databases of the server. Sizes need the CONNECT privilege.
+	metric.Databases = make([]domain.DatabaseInfo, 0)
+	rows, err := r.db.Query(`
+		SELECT
+			d.datname,
+			CASE WHEN has_database_privilege(d.datname, 'CONNECT') THEN pg_database_size(d.datname) ELSE 0 END,
+			COALESCE(s.numbackends, 0),
+			COALESCE(s.xact_commit, 0),
+			COALESCE(s.xact_rollback, 0)
+		FROM pg_database d
+		LEFT JOIN pg_stat_database s ON s.datid = d.oid
+		WHERE NOT d.datistemplate
+		ORDER BY d.datname
+	`)
+	if err != nil {
+		log.Printf("Error getting PostgreSQL databases: %v", err)
+		return metric, nil
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var db domain.DatabaseInfo
+		if err := rows.Scan(&db.Name, &db.Size, &db.Connections, &db.Commits, &db.Rollbacks); err != nil {
+			log.Printf("Error scanning PostgreSQL database: %v", err)
+			continue
+		}
+		db.Transactions = db.Commits + db.Rollbacks
+		metric.Databases = append(metric.Databases, db)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error reading PostgreSQL databases: %v", err)
 	}
 
 	return metric, nil
 }
+
+// GetSessions lists the client sessions of the current database, longest in
+// their state first. An empty state matches every state.
+func (r *PostgresRepository) GetSessions(filter domain.PostgreSQLSessionFilter) ([]domain.PostgreSQLSession, error) {
+	rows, err := r.db.Query(`
+		SELECT
+			pid,
+			COALESCE(usename, ''),
+			COALESCE(application_name, ''),
+			COALESCE(host(client_addr), ''),
+			COALESCE(state, ''),
+			COALESCE(wait_event_type, ''),
+			COALESCE(wait_event, ''),
+			backend_start,
+			xact_start,
+			EXTRACT(EPOCH FROM now() - xact_start),
+			query_start,
+			EXTRACT(EPOCH FROM now() - query_start),
+			EXTRACT(EPOCH FROM now() - state_change),
+			LEFT(COALESCE(query, ''), $3::int),
+			LENGTH(COALESCE(query, '')) > $3::int
+		FROM pg_stat_activity
+		WHERE datname = current_database()
+			AND backend_type = 'client backend'
+			AND pid <> pg_backend_pid()
+			AND ($1::text = '' OR state = $1::text)
+			AND ($2::float8 <= 0 OR EXTRACT(EPOCH FROM now() - state_change) >= $2::float8)
+		ORDER BY state_change NULLS LAST, pid
+	`, filter.State, filter.MinDuration, maxSessionQueryLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]domain.PostgreSQLSession, 0)
+	for rows.Next() {
+		var session domain.PostgreSQLSession
+		var xactStart, queryStart sql.NullTime
+		var xactAge, queryAge, stateAge sql.NullFloat64
+		err := rows.Scan(
+			&session.PID,
+			&session.User,
+			&session.Application,
+			&session.ClientAddress,
+			&session.State,
+			&session.WaitEventType,
+			&session.WaitEvent,
+			&session.BackendStart,
+			&xactStart,
+			&xactAge,
+			&queryStart,
+			&queryAge,
+			&stateAge,
+			&session.Query,
+			&session.QueryTruncated,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session: %w", err)
+		}
+
+		if xactStart.Valid {
+			session.TransactionStart = &xactStart.Time
+		}
+		if queryStart.Valid {
+			session.QueryStart = &queryStart.Time
+		}
+		if xactAge.Valid {
+			session.TransactionAge = &xactAge.Float64
+		}
+		if queryAge.Valid {
+			session.QueryAge = &queryAge.Float64
+		}
+		if stateAge.Valid {
+			session.StateAge = &stateAge.Float64
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// PostgresCounters are the cumulative counters of the current database that
+// transaction and query rates derive from
+type PostgresCounters struct {
+	Transactions        int64 // commits and rollbacks
+	Statements          int64 // pg_stat_statements calls
+	StatementsInstalled bool
+}
+
+// GetCounters reads the rate counters of the current database
+func (r *PostgresRepository) GetCounters() (PostgresCounters, error) {
+	var counters PostgresCounters
+	err := r.db.QueryRow(`
+		SELECT xact_commit + xact_rollback
+		FROM pg_stat_database
+		WHERE datname = current_database()
+	`).Scan(&counters.Transactions)
+	if err != nil {
+		return counters, fmt.Errorf("failed to read database statistics: %w", err)
+	}
+
+	counters.Statements, counters.StatementsInstalled, err = r.statementCount()
+	if err != nil {
+		return counters, err
+	}
+	return counters, nil
+}
+
+// statementCount sums the pg_stat_statements calls of the current database,
+// and reports whether the extension is installed
+func (r *PostgresRepository) statementCount() (int64, bool, error) {
+	var installed bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements')
+	`).Scan(&installed)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to look up pg_stat_statements: %w", err)
+	}
+	if !installed {
+		return 0, false, nil
+	}
+
+	var count int64
+	err = r.db.QueryRow(`
+		SELECT COALESCE(SUM(calls), 0)::bigint
+		FROM pg_stat_statements
+		WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+	`).Scan(&count)
+	if err != nil {
+		return 0, true, fmt.Errorf("failed to read pg_stat_statements: %w", err)
+	}
+	return count, true, nil
+}
+
+// SignalSession cancels the running query of a session, or terminates the
+// session. Only client sessions of the current database can be signalled,
+// never the one of this connection. The session is looked up and signalled
+// in one statement so that its PID cannot be reused in between.
+func (r *PostgresRepository) SignalSession(pid int, terminate bool) error {
+	signal := "pg_cancel_backend"
+	if terminate {
+		signal = "pg_terminate_backend"
+	}
+
+	// False means the session ended before it was signalled
+	var signalled bool
+	err := r.db.QueryRow(`
+		SELECT `+signal+`(pid)
+		FROM pg_stat_activity
+		WHERE pid = $1
+			AND datname = current_database()
+			AND backend_type = 'client backend'
+			AND pid <> pg_backend_pid()
+	`, pid).Scan(&signalled)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !signalled) {
+		return fmt.Errorf("%w: no client session with pid %d in this database", ErrSessionNotFound, pid)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to signal session %d: %w", pid, err)
+	}
+	return nil
+}
+
+// GetStatements reads the pg_stat_statements counters of the current
+// database. Rows of a statement run at several nesting levels are summed.
+// Statements without a query ID cannot be told apart and are left out.
+func (r *PostgresRepository) GetStatements() ([]domain.PostgreSQLStatement, error) {
+	var installed, execColumns bool
+	err := r.db.QueryRow(`
+		SELECT
+			to_regclass('pg_stat_statements') IS NOT NULL,
+			EXISTS (
+				SELECT 1
+				FROM pg_attribute
+				WHERE attrelid = to_regclass('pg_stat_statements')
+					AND attname = 'total_exec_time'
+			)
+	`).Scan(&installed, &execColumns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up pg_stat_statements: %w", err)
+	}
+	if !installed {
+		return nil, ErrStatementsUnavailable
+	}
+
+	// PostgreSQL 13 renamed total_time to total_exec_time
+	totalTime := "total_time"
+	if execColumns {
+		totalTime = "total_exec_time"
+	}
+
+	rows, err := r.db.Query(`
+		SELECT
+			s.queryid,
+			COALESCE(MIN(u.rolname), ''),
+			LEFT(MIN(s.query), $1::int),
+			SUM(s.calls)::bigint,
+			SUM(s.`+totalTime+`)::float8,
+			SUM(s.rows)::bigint,
+			SUM(s.shared_blks_read)::bigint,
+			SUM(s.shared_blks_hit)::bigint,
+			SUM(s.temp_blks_read)::bigint,
+			SUM(s.temp_blks_written)::bigint
+		FROM pg_stat_statements s
+		LEFT JOIN pg_roles u ON u.oid = s.userid
+		WHERE s.dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+			AND s.queryid IS NOT NULL
+		GROUP BY s.queryid, s.userid
+	`, maxStatementQueryLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pg_stat_statements: %w", err)
+	}
+	defer rows.Close()
+
+	statements := make([]domain.PostgreSQLStatement, 0)
+	for rows.Next() {
+		var statement domain.PostgreSQLStatement
+		err := rows.Scan(
+			&statement.QueryID,
+			&statement.User,
+			&statement.Query,
+			&statement.Calls,
+			&statement.TotalTime,
+			&statement.Rows,
+			&statement.SharedBlocksRead,
+			&statement.SharedBlocksHit,
+			&statement.TempBlocksRead,
+			&statement.TempBlocksWritten,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read statement: %w", err)
+		}
+		if statement.Calls > 0 {
+			statement.MeanTime = statement.TotalTime / float64(statement.Calls)
+		}
+		statements = append(statements, statement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pg_stat_statements: %w", err)
+	}
+	return statements, nil
+}
@@ -3,6 +3,7 @@ package usecase
 import (
 	"log"
 	"sync"
+	"time"
 
 	"github.com/Danos/backend/internal/domain"
 	"github.com/Danos/backend/internal/infrastructure/kafka"
@@ -17,6 +18,38 @@ type MonitoringUsecase struct {
 	kafkaManager    *kafka.KafkaManager
 	postgresManager *postgres.PostgresManager
 	mysqlManager    *mysql.MySQLManager
+
+	// PostgreSQL counters of the previous sample and the rates since, per
+	// database
+	postgresMu      sync.Mutex
+	postgresSamples map[string]postgresSample
+	postgresRates   map[string]postgresRates
+	ratesStop       chan struct{}
+
+	// pg_stat_statements snapshots per database, and the slow statements
+	// counted at the latest one
+	statementsMu       sync.Mutex
+	statementSnapshots map[string][]statementSnapshot
+	slowStatements     map[string]int64
+	statementsStop     chan struct{}
+}
+
+// postgresRateInterval is how often PostgreSQL counters are sampled for the
+// transaction and query rates
+const postgresRateInterval = 15 * time.Second
+
+// postgresSample holds the cumulative counters rates are computed from
+type postgresSample struct {
+	counters repository.PostgresCounters
+	at       time.Time
+}
+
+// postgresRates are the rates of a database between its last two samples.
+// queriesFrom is empty when the queries rate is unknown.
+type postgresRates struct {
+	transactionsPerSec float64
+	queriesPerSec      float64
+	queriesFrom        string
 }
 
 func NewMonitoringUsecase(
@@ -30,6 +63,13 @@ func NewMonitoringUsecase(
 		kafkaManager:    kafkaManager,
 		postgresManager: postgresManager,
 		mysqlManager:    mysqlManager,
+		postgresSamples: make(map[string]postgresSample),
+		postgresRates:   make(map[string]postgresRates),
+		ratesStop:       make(chan struct{}),
+
+		statementSnapshots: make(map[string][]statementSnapshot),
+		slowStatements:     make(map[string]int64),
+		statementsStop:     make(chan struct{}),
 	}
 }
 
@@ -101,12 +141,119 @@ func (u *MonitoringUsecase) getPostgreSQLMetrics() []domain.PostgreSQLMetrics {
 			log.Printf("Error getting PostgreSQL metrics for %s: %v", name, err)
 			continue
 		}
+		metric.DatabaseSizeHuman = bytesToHuman(metric.DatabaseSize)
+		for i := range metric.Databases {
+			metric.Databases[i].SizeHuman = bytesToHuman(metric.Databases[i].Size)
+		}
+		u.applyPostgreSQLRates(&metric)
+		metric.SlowQueries = u.slowStatementCount(name)
 		metrics = append(metrics, metric)
 	}
 
 	return metrics
 }
 
+// applyPostgreSQLRates sets the rates of a database from its latest samples
+func (u *MonitoringUsecase) applyPostgreSQLRates(metric *domain.PostgreSQLMetrics) {
+	u.postgresMu.Lock()
+	rates := u.postgresRates[metric.Name]
+	u.postgresMu.Unlock()
+
+	metric.TransactionsPerSec = rates.transactionsPerSec
+	metric.QueriesPerSec = rates.queriesPerSec
+	metric.QueriesPerSecFrom = rates.queriesFrom
+}
+
+// StartPostgreSQLRates samples the counters of every PostgreSQL database now
+// and periodically after, until StopPostgreSQLRates. Sampling on a schedule
+// keeps the rate intervals independent of how often metrics are requested.
+func (u *MonitoringUsecase) StartPostgreSQLRates() {
+	go func() {
+		ticker := time.NewTicker(postgresRateInterval)
+		defer ticker.Stop()
+
+		u.samplePostgreSQLRates()
+		for {
+			select {
+			case <-ticker.C:
+				u.samplePostgreSQLRates()
+			case <-u.ratesStop:
+				return
+			}
+		}
+	}()
+	log.Printf("PostgreSQL rate sampling started, every %s", postgresRateInterval)
+}
+
+// StopPostgreSQLRates stops the sampling started by StartPostgreSQLRates
+func (u *MonitoringUsecase) StopPostgreSQLRates() {
+	close(u.ratesStop)
+}
+
+// samplePostgreSQLRates reads the counters of every database and computes
+// the rates since the previous sample. A database that cannot be read has
+// no rates until two samples succeed again.
+func (u *MonitoringUsecase) samplePostgreSQLRates() {
+	clients := u.postgresManager.GetAllClients()
+
+	for name, db := range clients {
+		counters, err := repository.NewPostgresRepository(db).GetCounters()
+		current := postgresSample{counters: counters, at: time.Now()}
+		if err != nil {
+			log.Printf("Error sampling PostgreSQL counters for %s: %v", name, err)
+			u.postgresMu.Lock()
+			delete(u.postgresSamples, name)
+			delete(u.postgresRates, name)
+			u.postgresMu.Unlock()
+			continue
+		}
+
+		u.postgresMu.Lock()
+		prev, ok := u.postgresSamples[name]
+		u.postgresSamples[name] = current
+		if ok {
+			u.postgresRates[name] = postgresSampleRates(prev, current)
+		}
+		u.postgresMu.Unlock()
+	}
+
+	// Databases no longer configured start over
+	u.postgresMu.Lock()
+	for name := range u.postgresSamples {
+		if _, ok := clients[name]; !ok {
+			delete(u.postgresSamples, name)
+			delete(u.postgresRates, name)
+		}
+	}
+	u.postgresMu.Unlock()
+}
+
+// postgresSampleRates computes the rates between two samples. Queries are the
+// statements counted by pg_stat_statements, or the transactions, a lower
+// bound, without the extension. A statistics reset leaves the rates at zero,
+// and so does a drop of the statement count, which evictions from
+// pg_stat_statements cause.
+func postgresSampleRates(prev, current postgresSample) postgresRates {
+	var rates postgresRates
+
+	elapsed := current.at.Sub(prev.at).Seconds()
+	if elapsed <= 0 || current.counters.Transactions < prev.counters.Transactions {
+		return rates
+	}
+	rates.transactionsPerSec = float64(current.counters.Transactions-prev.counters.Transactions) / elapsed
+
+	switch {
+	case !prev.counters.StatementsInstalled && !current.counters.StatementsInstalled:
+		rates.queriesPerSec = rates.transactionsPerSec
+		rates.queriesFrom = "transactions"
+	case prev.counters.StatementsInstalled && current.counters.StatementsInstalled &&
+		current.counters.Statements >= prev.counters.Statements:
+		rates.queriesPerSec = float64(current.counters.Statements-prev.counters.Statements) / elapsed
+		rates.queriesFrom = "pg_stat_statements"
+	}
+	return rates
+}
+
 func (u *MonitoringUsecase) getMySQLMetrics() []domain.MySQLMetrics {
 	clients := u.mysqlManager.GetAllClients()
 	metrics := make([]domain.MySQLMetrics, 0)
@@ -0,0 +1,333 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Danos/backend/internal/domain"
+	"github.com/Danos/backend/internal/repository"
+)
+
+// ErrActivityTrackingDisabled is returned for session requests on databases
+// without monitoring.track_activity
+var ErrActivityTrackingDisabled = errors.New("activity tracking is disabled")
+
+// ErrSessionNotFound is returned when cancelling or terminating a PID that
+// is not a client session of the database
+var ErrSessionNotFound = repository.ErrSessionNotFound
+
+// ErrStatementsUnavailable is returned for statement requests on databases
+// without the pg_stat_statements extension
+var ErrStatementsUnavailable = repository.ErrStatementsUnavailable
+
+// ErrInvalidStatementRequest is returned for top statement requests with an
+// unknown sort or out of range limits
+var ErrInvalidStatementRequest = errors.New("invalid statement request")
+
+// postgresActivity returns the repository of a database whose sessions may
+// be viewed and signalled
+func (u *MonitoringUsecase) postgresActivity(name string) (*repository.PostgresRepository, error) {
+	config, err := u.postgresManager.GetDatabaseConfig(name)
+	if err != nil {
+		return nil, err
+	}
+	if !config.Monitoring.TrackActivity {
+		return nil, fmt.Errorf("%w for PostgreSQL database %s", ErrActivityTrackingDisabled, name)
+	}
+
+	db, err := u.postgresManager.GetClient(name)
+	if err != nil {
+		return nil, err
+	}
+	return repository.NewPostgresRepository(db), nil
+}
+
+// GetPostgreSQLSessions lists the client sessions of a database
+func (u *MonitoringUsecase) GetPostgreSQLSessions(name string, filter domain.PostgreSQLSessionFilter) ([]domain.PostgreSQLSession, error) {
+	repo, err := u.postgresActivity(name)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetSessions(filter)
+}
+
+// CancelPostgreSQLSession cancels the running query of a session
+func (u *MonitoringUsecase) CancelPostgreSQLSession(name string, pid int) error {
+	repo, err := u.postgresActivity(name)
+	if err != nil {
+		return err
+	}
+	if err := repo.SignalSession(pid, false); err != nil {
+		return err
+	}
+	log.Printf("Cancelled query of PostgreSQL session %d on %s", pid, name)
+	return nil
+}
+
+// TerminatePostgreSQLSession closes a session, rolling back its transaction
+func (u *MonitoringUsecase) TerminatePostgreSQLSession(name string, pid int) error {
+	repo, err := u.postgresActivity(name)
+	if err != nil {
+		return err
+	}
+	if err := repo.SignalSession(pid, true); err != nil {
+		return err
+	}
+	log.Printf("Terminated PostgreSQL session %d on %s", pid, name)
+	return nil
+}
+
+// Settings of pg_stat_statements snapshots. Snapshots older than the longest
+// window are dropped, which keeps about an hour of history per database.
+const (
+	statementSnapshotInterval = 5 * time.Minute
+	maxStatementWindow        = time.Hour
+	defaultStatementWindow    = 60 // minutes
+	defaultStatementLimit     = 20
+	maxStatementLimit         = 100
+	defaultSlowQueryThreshold = 1000 // milliseconds
+)
+
+// statementSnapshot holds the counters of the statements of a database at
+// one time, by statementKey. Query texts are not kept.
+type statementSnapshot struct {
+	at         time.Time
+	statements map[string]domain.PostgreSQLStatement
+}
+
+// StartStatementSnapshots snapshots pg_stat_statements of every PostgreSQL
+// database now and periodically after, until StopStatementSnapshots
+func (u *MonitoringUsecase) StartStatementSnapshots() {
+	go func() {
+		ticker := time.NewTicker(statementSnapshotInterval)
+		defer ticker.Stop()
+
+		u.snapshotStatements()
+		for {
+			select {
+			case <-ticker.C:
+				u.snapshotStatements()
+			case <-u.statementsStop:
+				return
+			}
+		}
+	}()
+	log.Printf("PostgreSQL statement snapshots started, every %s", statementSnapshotInterval)
+}
+
+// StopStatementSnapshots stops the snapshots started by StartStatementSnapshots
+func (u *MonitoringUsecase) StopStatementSnapshots() {
+	close(u.statementsStop)
+}
+
+// snapshotStatements takes a snapshot of every database with
+// pg_stat_statements and counts its slow statements over the last window
+func (u *MonitoringUsecase) snapshotStatements() {
+	clients := u.postgresManager.GetAllClients()
+
+	for name, db := range clients {
+		statements, err := repository.NewPostgresRepository(db).GetStatements()
+		now := time.Now()
+		if err != nil {
+			if !errors.Is(err, repository.ErrStatementsUnavailable) {
+				log.Printf("Error snapshotting PostgreSQL statements for %s: %v", name, err)
+			}
+			u.statementsMu.Lock()
+			delete(u.statementSnapshots, name)
+			delete(u.slowStatements, name)
+			u.statementsMu.Unlock()
+			continue
+		}
+
+		snapshot := statementSnapshot{at: now, statements: make(map[string]domain.PostgreSQLStatement, len(statements))}
+		for _, statement := range statements {
+			statement.Query = ""
+			snapshot.statements[statementKey(statement)] = statement
+		}
+		threshold := u.slowQueryThreshold(name)
+
+		u.statementsMu.Lock()
+		history := u.statementSnapshots[name]
+
+		var slow int64
+		for _, statement := range statementDeltas(statements, baselineSnapshot(history, now, maxStatementWindow)) {
+			if statement.MeanTime > threshold {
+				slow++
+			}
+		}
+		u.slowStatements[name] = slow
+
+		kept := make([]statementSnapshot, 0, len(history)+1)
+		for _, s := range append(history, snapshot) {
+			if now.Sub(s.at) <= maxStatementWindow+statementSnapshotInterval {
+				kept = append(kept, s)
+			}
+		}
+		u.statementSnapshots[name] = kept
+		u.statementsMu.Unlock()
+	}
+
+	// Databases no longer configured start over
+	u.statementsMu.Lock()
+	for name := range u.statementSnapshots {
+		if _, ok := clients[name]; !ok {
+			delete(u.statementSnapshots, name)
+			delete(u.slowStatements, name)
+		}
+	}
+	u.statementsMu.Unlock()
+}
+
+// slowQueryThreshold returns the slow query threshold of a database in
+// milliseconds
+func (u *MonitoringUsecase) slowQueryThreshold(name string) float64 {
+	config, err := u.postgresManager.GetDatabaseConfig(name)
+	if err != nil || config.Monitoring.SlowQueryThreshold <= 0 {
+		return defaultSlowQueryThreshold
+	}
+	return float64(config.Monitoring.SlowQueryThreshold)
+}
+
+// slowStatementCount returns the number of statements slower on average than
+// the threshold at the latest snapshot of a database
+func (u *MonitoringUsecase) slowStatementCount(name string) int64 {
+	u.statementsMu.Lock()
+	defer u.statementsMu.Unlock()
+	return u.slowStatements[name]
+}
+
+// GetPostgreSQLTopStatements ranks the statements of a database by their
+// activity over a window, using the snapshot taken about a window ago
+func (u *MonitoringUsecase) GetPostgreSQLTopStatements(name string, req domain.PostgreSQLTopStatementsRequest) (*domain.PostgreSQLTopStatements, error) {
+	sortBy := req.Sort
+	if sortBy == "" {
+		sortBy = "total_time"
+	}
+	rank, err := statementRank(sortBy)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultStatementLimit
+	}
+	if limit > maxStatementLimit {
+		return nil, fmt.Errorf("%w: limit must be at most %d", ErrInvalidStatementRequest, maxStatementLimit)
+	}
+
+	window := req.Window
+	if window <= 0 {
+		window = defaultStatementWindow
+	}
+	if time.Duration(window)*time.Minute > maxStatementWindow {
+		return nil, fmt.Errorf("%w: window must be at most %d minutes", ErrInvalidStatementRequest, int(maxStatementWindow.Minutes()))
+	}
+
+	db, err := u.postgresManager.GetClient(name)
+	if err != nil {
+		return nil, err
+	}
+	statements, err := repository.NewPostgresRepository(db).GetStatements()
+	if err != nil {
+		if errors.Is(err, ErrStatementsUnavailable) {
+			return nil, fmt.Errorf("%w in PostgreSQL database %s, add it to shared_preload_libraries and run CREATE EXTENSION pg_stat_statements", err, name)
+		}
+		return nil, err
+	}
+	now := time.Now()
+
+	u.statementsMu.Lock()
+	base := baselineSnapshot(u.statementSnapshots[name], now, time.Duration(window)*time.Minute)
+	u.statementsMu.Unlock()
+
+	deltas := statementDeltas(statements, base)
+	sort.Slice(deltas, func(i, j int) bool {
+		a, b := rank(deltas[i]), rank(deltas[j])
+		if a != b {
+			return a > b
+		}
+		return deltas[i].QueryID < deltas[j].QueryID
+	})
+	if len(deltas) > limit {
+		deltas = deltas[:limit]
+	}
+
+	result := &domain.PostgreSQLTopStatements{
+		Database:   name,
+		Sort:       sortBy,
+		Until:      now,
+		Cumulative: base == nil,
+		Statements: deltas,
+	}
+	if base != nil {
+		since := base.at
+		result.Since = &since
+	}
+	return result, nil
+}
+
+// statementRank returns the value a ranking sorts statements by, descending
+func statementRank(sortBy string) (func(domain.PostgreSQLStatement) float64, error) {
+	switch sortBy {
+	case "total_time":
+		return func(s domain.PostgreSQLStatement) float64 { return s.TotalTime }, nil
+	case "mean_time":
+		return func(s domain.PostgreSQLStatement) float64 { return s.MeanTime }, nil
+	case "calls":
+		return func(s domain.PostgreSQLStatement) float64 { return float64(s.Calls) }, nil
+	case "rows":
+		return func(s domain.PostgreSQLStatement) float64 { return float64(s.Rows) }, nil
+	case "shared_blocks_read":
+		return func(s domain.PostgreSQLStatement) float64 { return float64(s.SharedBlocksRead) }, nil
+	case "temp_blocks":
+		return func(s domain.PostgreSQLStatement) float64 { return float64(s.TempBlocksRead + s.TempBlocksWritten) }, nil
+	default:
+		return nil, fmt.Errorf("%w: sort must be one of total_time, mean_time, calls, rows, shared_blocks_read or temp_blocks", ErrInvalidStatementRequest)
+	}
+}
+
+// baselineSnapshot returns the oldest snapshot at most a window, give or take
+// half a snapshot interval, before now, or nil when there is none
+func baselineSnapshot(history []statementSnapshot, now time.Time, window time.Duration) *statementSnapshot {
+	for i := range history {
+		if now.Sub(history[i].at) <= window+statementSnapshotInterval/2 {
+			return &history[i]
+		}
+	}
+	return nil
+}
+
+// statementDeltas subtracts the baseline counters from the current ones.
+// Statements new since the baseline, or whose counters went back after a
+// reset, count in full. Statements not called since are left out.
+func statementDeltas(current []domain.PostgreSQLStatement, base *statementSnapshot) []domain.PostgreSQLStatement {
+	deltas := make([]domain.PostgreSQLStatement, 0, len(current))
+	for _, statement := range current {
+		if base != nil {
+			if prev, ok := base.statements[statementKey(statement)]; ok && statement.Calls >= prev.Calls {
+				statement.Calls -= prev.Calls
+				statement.TotalTime -= prev.TotalTime
+				statement.Rows -= prev.Rows
+				statement.SharedBlocksRead -= prev.SharedBlocksRead
+				statement.SharedBlocksHit -= prev.SharedBlocksHit
+				statement.TempBlocksRead -= prev.TempBlocksRead
+				statement.TempBlocksWritten -= prev.TempBlocksWritten
+			}
+		}
+		if statement.Calls == 0 {
+			continue
+		}
+		statement.MeanTime = statement.TotalTime / float64(statement.Calls)
+		deltas = append(deltas, statement)
+	}
+	return deltas
+}
+
+// statementKey identifies a statement across snapshots
+func statementKey(statement domain.PostgreSQLStatement) string {
+	return fmt.Sprintf("%s/%d", statement.User, statement.QueryID)
+}
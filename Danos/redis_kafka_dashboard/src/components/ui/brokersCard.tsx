@@ -16,18 +16,18 @@ export function BrokersCard({ brokers }: { brokers: KafkaBroker[] }) {
             <div>
               <p className="font-semibold">{b.name}</p>
               <p className="text-sm text-muted-foreground">{b.host}</p>
-              <p className="text-sm mt-1">Status: {b.status}</p>
+              <p className="text-sm mt-1">
+                Status: {b.status}
+                {b.isController && " (controller)"}
+              </p>
             </div>
 
             <div className="text-right space-y-1">
               <p>
-                Disk: {b.diskUsed} / {b.diskTotal} ({b.diskUsage}%)
-              </p>
-              <p>CPU: {b.cpuUsage}%</p>
-              <p>Memory: {b.memoryUsage}%</p>
-              <p>
-                Net In/Out: {b.networkIn} / {b.networkOut}
+                Leaders / Replicas: {b.leaders} / {b.replicas}
               </p>
+              <p>Out-of-sync Replicas: {b.outOfSyncReplicas}</p>
+              <p>Net In: {b.networkIn}</p>
               <p>Message Rate: {b.messageRate}/s</p>
             </div>
           </div>
@@ -16,6 +16,8 @@ export function ClusterInfoCard({ info }: { info: ClusterInfo }) {
           <p>Message Rate: {info.totalMessageRate}/s</p>
           <p>Disk Usage: {info.totalDiskUsage}</p>
           <p>Avg Replication Factor: {info.avgReplicationFactor}</p>
+          <p>Under-replicated Partitions: {info.underReplicated}</p>
+          <p>Offline Partitions: {info.offlinePartitions}</p>
         </div>
       </CardContent>
     </Card>
@@ -22,6 +22,7 @@ export function ConsumerGroupsCard({ groups }: { groups: ConsumerGroup[] }) {
             <div className="text-right space-y-1">
               <p>Consumers: {g.consumers}</p>
               <p>Lag: {g.lag}</p>
+              <p>Lag Age: {Math.round(g.lagSeconds)}s</p>
               <p>Status: {g.status}</p>
             </div>
           </div>
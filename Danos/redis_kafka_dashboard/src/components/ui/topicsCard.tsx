@@ -1,5 +1,5 @@
 import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
-import type { TopicMetrics } from "@/models/generalModel";
+import type { TopicMetrics } from "@/models/kafkaModel";
 
 export function TopicsCard({ topics }: { topics: TopicMetrics[] }) {
   return (
@@ -26,7 +26,7 @@ export function TopicsCard({ topics }: { topics: TopicMetrics[] }) {
               <p>Message Rate: {t.messageRate}/s</p>
               <p>Size: {t.size}</p>
               <p>Retention: {t.retention}</p>
-              <p>Under-replicated: {t.underReplicated}</p>
+              <p>Min ISR: {t.minIsr}</p>
             </div>
           </div>
         ))}
@@ -37,3 +37,30 @@ export function calculateReplicationLag(
   const lag = masterReplOffset - replicaOffset;
   return lag < 0 ? 0 : lag;
 }
+
+export function formatBytes(bytes: number): string {
+  const units = ["B", "KB", "MB", "GB", "TB"];
+  let value = bytes;
+  let unit = 0;
+  while (value >= 1024 && unit < units.length - 1) {
+    value /= 1024;
+    unit++;
+  }
+  return `${value.toFixed(unit === 0 ? 0 : 1)}${units[unit]}`;
+}
+
+// formatDuration renders milliseconds as the largest whole unit, e.g. "7d"
+export function formatDuration(ms: number): string {
+  if (ms < 0) return "unlimited";
+
+  const units: [string, number][] = [
+    ["d", 86_400_000],
+    ["h", 3_600_000],
+    ["m", 60_000],
+    ["s", 1_000],
+  ];
+  for (const [unit, size] of units) {
+    if (ms >= size) return `${Math.round(ms / size)}${unit}`;
+  }
+  return `${ms}ms`;
+}
@@ -6,7 +6,7 @@ export interface TopicMetrics {
   messageRate: number;
   size: string;
   retention: string;
-  underReplicated: number;
+  minIsr: number; // in-sync replicas of the least replicated partition
 }
 
 export interface ClusterInfo {
@@ -17,6 +17,8 @@ export interface ClusterInfo {
   totalMessageRate: number; // messages per second
   totalDiskUsage: string; // contoh: "4.2TB"
   avgReplicationFactor: number;
+  underReplicated: number;
+  offlinePartitions: number;
 }
 
 export interface ConsumerGroup {
@@ -24,6 +26,7 @@ export interface ConsumerGroup {
   topic: string;
   consumers: number;
   lag: number;
+  lagSeconds: number; // age of the oldest unconsumed message
   status: "healthy" | "warning" | "offline";
 }
 
@@ -32,16 +35,89 @@ export interface KafkaBroker {
   name: string;
   host: string;
   status: "online" | "offline" | "warning";
+  isController: boolean;
+  version: string;
 
-  diskUsed: string; // misal "120GB"
-  diskTotal: string; // misal "500GB"
-  diskUsage: number; // misal 24 (%)
-
-  cpuUsage: number; // percent
-  memoryUsage: number; // percent
+  leaders: number;
+  replicas: number;
+  outOfSyncReplicas: number;
 
   networkIn: string; // misal "12 MB/s"
-  networkOut: string; // misal "10 MB/s"
-
   messageRate: number; // messages/s
 }
+
+// ==================== API payload of /api/v1/monitoring/kafka ====================
+
+export interface MonitoringKafkaTopic {
+  name: string;
+  partitions: number;
+  replication_factor: number;
+  isr_count: number;
+  messages_per_sec: number;
+  bytes_in_per_sec: number;
+  bytes_out_per_sec: number;
+  total_size: number;
+  retention_ms: number; // -1 when unlimited
+  retention_bytes: number; // -1 when unlimited
+}
+
+export interface MonitoringKafkaTopicLag {
+  topic: string;
+  partition: number;
+  lag: number;
+  lag_seconds: number;
+}
+
+export interface MonitoringKafkaConsumerGroup {
+  group_id: string;
+  state: string; // Stable, Dead, Empty, PreparingRebalance, CompletingRebalance
+  members: number;
+  lag: number;
+  lag_seconds: number;
+  topic_lags: MonitoringKafkaTopicLag[];
+  rebalance_storm: boolean;
+  rebalance_stuck: boolean;
+}
+
+export interface MonitoringKafkaCluster {
+  cluster_id: string;
+  controller_id: number;
+  status: string; // online, warning
+  brokers_online: number;
+  brokers_offline: number;
+  brokers_fenced: number;
+  topics: MonitoringKafkaTopic[];
+  consumer_groups: MonitoringKafkaConsumerGroup[];
+  total_partitions: number;
+  total_topics: number;
+  under_replicated_partitions: number;
+  offline_partitions: number;
+  active_controllers: number;
+  bytes_in_per_sec: number;
+  bytes_out_per_sec: number;
+  messages_in_per_sec: number;
+  mode: string; // kraft, zookeeper, empty when unknown
+}
+
+export interface MonitoringKafkaBroker {
+  broker_id: number;
+  host: string;
+  port: number;
+  rack?: string;
+  status: string; // online, degraded, fenced, offline
+  version: string;
+  is_controller: boolean;
+  leaders: number;
+  replicas: number;
+  out_of_sync_replicas: number;
+  leader_skew: number;
+  bytes_in_per_sec: number;
+  messages_in_per_sec: number;
+  metadata_lag: number | null;
+}
+
+export interface MonitoringKafkaData {
+  cluster: MonitoringKafkaCluster;
+  brokers: MonitoringKafkaBroker[];
+  timestamp: string;
+}
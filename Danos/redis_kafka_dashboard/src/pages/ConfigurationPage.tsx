@@ -44,34 +44,40 @@ redis:
 
 const defaultKafkaConfig = `# Kafka Configuration
 kafka:
-  brokers:
-    - "kafka-broker-1:9092"
-    - "kafka-broker-2:9092"
-    - "kafka-broker-3:9092"
-
-  security:
-    protocol: "SASL_SSL"
-    sasl_mechanism: "PLAIN"
-    username: "your-username"
-    password: "your-password"
-
-  monitoring:
-    interval: 30
-    topics:
-      - "orders"
-      - "payments"
-      - "notifications"
-
-    consumer_groups:
-      - "order-processor"
-      - "payment-handler"
-      - "notification-service"
+  # Each cluster is monitored separately; a top-level brokers list is still read as cluster "default"
+  clusters:
+    - name: "production"
+      brokers:
+        - "kafka-broker-1:9092"
+        - "kafka-broker-2:9092"
+        - "kafka-broker-3:9092"
+
+      security:
+        protocol: "SASL_SSL"
+        sasl_mechanism: "PLAIN"
+        username: "your-username"
+        password: "your-password"
+        # Broker certificates are verified; empty uses the system CA bundle
+        tls:
+          ca_location: "/etc/kafka/ca.pem"
 
-    metrics:
-      - broker_status
-      - topic_partitions
-      - consumer_lag
-      - message_rate`;
+      monitoring:
+        interval: 30
+        topics:
+          - "orders"
+          - "payments"
+          - "notifications"
+
+        consumer_groups:
+          - "order-processor"
+          - "payment-handler"
+          - "notification-service"
+
+        metrics:
+          - broker_status
+          - topic_partitions
+          - consumer_lag
+          - message_rate`;
 
 const defaultPostgresConfig = `# PostgreSQL Configuration
 postgresql:
@@ -464,7 +470,7 @@ export function ConfigurationPage() {
                 MessageSquare,
                 kafkaConfig,
                 setKafkaConfig,
-                "Define brokers, topics, consumer groups, and security settings.",
+                "Define named clusters with their brokers, topics, consumer groups, and security settings.",
               )}
 
             {activeTab === "postgresql" &&
@@ -1,3 +1,4 @@
+import { useState, useEffect } from 'react';
 import { Card, CardContent, CardHeader, CardTitle } from '@/components/ui/card';
 import { Badge } from '@/components/ui/badge';
 import { Button } from '@/components/ui/button';
@@ -24,25 +25,23 @@ import {
   TrendingUp
 } from 'lucide-react';
 import { Layout } from '@/components/Layout';
+import { kafkaService, toKafkaView, type KafkaView } from '@/services/kafkaService';
+import type { ClusterInfo, ConsumerGroup, KafkaBroker, MonitoringKafkaData, TopicMetrics } from '@/models/kafkaModel';
 
 // Mock Kafka data
-const kafkaBrokers = [
+const kafkaBrokers: KafkaBroker[] = [
   {
     id: 'kafka-001',
     name: 'Kafka Broker 1',
     host: '10.0.2.10:9092',
     status: 'online',
     isController: true,
-    diskUsage: 65,
-    diskTotal: '500GB',
-    diskUsed: '325GB',
-    cpuUsage: 45,
-    memoryUsage: 78,
-    networkIn: 120,
-    networkOut: 95,
-    messageRate: 15000,
-    uptime: '25d 8h 15m',
-    lastUpdate: '10:04 PM'
+    version: '3.7',
+    leaders: 42,
+    replicas: 126,
+    outOfSyncReplicas: 0,
+    networkIn: '120.0MB/s',
+    messageRate: 15000
   },
   {
     id: 'kafka-002',
@@ -50,16 +49,12 @@ const kafkaBrokers = [
     host: '10.0.2.11:9092',
     status: 'online',
     isController: false,
-    diskUsage: 72,
-    diskTotal: '500GB',
-    diskUsed: '360GB',
-    cpuUsage: 52,
-    memoryUsage: 82,
-    networkIn: 98,
-    networkOut: 87,
-    messageRate: 12500,
-    uptime: '25d 8h 12m',
-    lastUpdate: '10:04 PM'
+    version: '3.7',
+    leaders: 40,
+    replicas: 124,
+    outOfSyncReplicas: 0,
+    networkIn: '98.0MB/s',
+    messageRate: 12500
   },
   {
     id: 'kafka-003',
@@ -67,25 +62,21 @@ const kafkaBrokers = [
     host: '10.0.2.12:9092',
     status: 'warning',
     isController: false,
-    diskUsage: 88,
-    diskTotal: '500GB',
-    diskUsed: '440GB',
-    cpuUsage: 75,
-    memoryUsage: 92,
-    networkIn: 145,
-    networkOut: 132,
-    messageRate: 18000,
-    uptime: '20d 4h 30m',
-    lastUpdate: '10:03 PM'
+    version: '3.7',
+    leaders: 38,
+    replicas: 120,
+    outOfSyncReplicas: 2,
+    networkIn: '145.0MB/s',
+    messageRate: 18000
   }
 ];
 
-const kafkaTopics = [
+const kafkaTopics: TopicMetrics[] = [
   {
     name: 'orders',
     partitions: 12,
     replicas: 3,
-    underReplicated: 0,
+    minIsr: 3,
     messageRate: 8500,
     size: '2.4GB',
     retention: '7d',
@@ -95,7 +86,7 @@ const kafkaTopics = [
     name: 'payments',
     partitions: 8,
     replicas: 3,
-    underReplicated: 0,
+    minIsr: 3,
     messageRate: 5200,
     size: '1.8GB',
     retention: '30d',
@@ -105,7 +96,7 @@ const kafkaTopics = [
     name: 'notifications',
     partitions: 6,
     replicas: 3,
-    underReplicated: 2,
+    minIsr: 2,
     messageRate: 12000,
     size: '950MB',
     retention: '3d',
@@ -115,7 +106,7 @@ const kafkaTopics = [
     name: 'logs',
     partitions: 24,
     replicas: 2,
-    underReplicated: 0,
+    minIsr: 2,
     messageRate: 25000,
     size: '8.2GB',
     retention: '1d',
@@ -123,12 +114,13 @@ const kafkaTopics = [
   }
 ];
 
-const consumerGroups = [
+const consumerGroups: ConsumerGroup[] = [
   {
     name: 'order-processor',
     topic: 'orders',
     consumers: 3,
     lag: 150,
+    lagSeconds: 2,
     status: 'healthy'
   },
   {
@@ -136,6 +128,7 @@ const consumerGroups = [
     topic: 'payments',
     consumers: 2,
     lag: 45,
+    lagSeconds: 1,
     status: 'healthy'
   },
   {
@@ -143,6 +136,7 @@ const consumerGroups = [
     topic: 'notifications',
     consumers: 1,
     lag: 15000,
+    lagSeconds: 340,
     status: 'warning'
   },
   {
@@ -150,21 +144,48 @@ const consumerGroups = [
     topic: 'logs',
     consumers: 4,
     lag: 2500,
+    lagSeconds: 12,
     status: 'healthy'
   }
 ];
 
-const clusterInfo = {
+const clusterInfo: ClusterInfo = {
   totalBrokers: 3,
   totalTopics: 15,
   totalPartitions: 180,
   totalConsumerGroups: 8,
   totalMessageRate: 89000,
   totalDiskUsage: '4.2TB',
-  avgReplicationFactor: 2.8
+  avgReplicationFactor: 2.8,
+  underReplicated: 2,
+  offlinePartitions: 0
 };
 
+const mockKafka: KafkaView = { brokers: kafkaBrokers, topics: kafkaTopics, consumerGroups, clusterInfo };
+
 export function KafkaMonitorPage() {
+  const [clusters, setClusters] = useState<Record<string, MonitoringKafkaData>>({});
+  const [selectedCluster, setSelectedCluster] = useState<string>();
+
+  useEffect(() => {
+    const loadMonitoringKafka = async () => {
+      try {
+        const monitoringKafka = await kafkaService.getMonitoringKafka();
+        setClusters(monitoringKafka);
+        setSelectedCluster((current) =>
+          current && monitoringKafka[current] ? current : Object.keys(monitoringKafka).sort()[0],
+        );
+      } catch (error) {
+        console.error("Failed to load kafka metrics", error);
+      }
+    };
+    loadMonitoringKafka();
+  }, []);
+
+  const clusterNames = Object.keys(clusters).sort();
+  const kafka: KafkaView =
+    selectedCluster && clusters[selectedCluster] ? toKafkaView(clusters[selectedCluster]) : mockKafka;
+
   const getStatusIcon = (status: string) => {
     switch (status) {
       case 'online':
@@ -199,12 +220,27 @@ export function KafkaMonitorPage() {
   return (
     <Layout>
       <div className="space-y-6">
-        <div className="flex items-center gap-3">
-          <MessageSquare className="h-8 w-8 text-blue-400" />
-          <div>
-            <h1 className="text-3xl font-bold">Kafka Monitor</h1>
-            <p className="text-muted-foreground">Monitor Kafka brokers, topics, and consumer groups</p>
+        <div className="flex items-center justify-between gap-3">
+          <div className="flex items-center gap-3">
+            <MessageSquare className="h-8 w-8 text-blue-400" />
+            <div>
+              <h1 className="text-3xl font-bold">Kafka Monitor</h1>
+              <p className="text-muted-foreground">Monitor Kafka brokers, topics, and consumer groups</p>
+            </div>
           </div>
+          {clusterNames.length > 0 && (
+            <Select value={selectedCluster} onValueChange={setSelectedCluster}>
+              <SelectTrigger className="w-[200px]">
+                <Server className="h-4 w-4 mr-2" />
+                <SelectValue placeholder="Cluster" />
+              </SelectTrigger>
+              <SelectContent>
+                {clusterNames.map((name) => (
+                  <SelectItem key={name} value={name}>{name}</SelectItem>
+                ))}
+              </SelectContent>
+            </Select>
+          )}
         </div>
 
         <Tabs defaultValue="overview" className="space-y-6">
@@ -224,7 +260,7 @@ export function KafkaMonitorPage() {
                   <Server className="h-4 w-4 text-muted-foreground" />
                 </CardHeader>
                 <CardContent>
-                  <div className="metric-value">{clusterInfo.totalBrokers}</div>
+                  <div className="metric-value">{kafka.clusterInfo.totalBrokers}</div>
                   <p className="metric-label">Active brokers</p>
                 </CardContent>
               </Card>
@@ -235,8 +271,8 @@ export function KafkaMonitorPage() {
                   <Database className="h-4 w-4 text-muted-foreground" />
                 </CardHeader>
                 <CardContent>
-                  <div className="metric-value">{clusterInfo.totalTopics}</div>
-                  <p className="metric-label">{clusterInfo.totalPartitions} partitions</p>
+                  <div className="metric-value">{kafka.clusterInfo.totalTopics}</div>
+                  <p className="metric-label">{kafka.clusterInfo.totalPartitions} partitions</p>
                 </CardContent>
               </Card>
 
@@ -246,7 +282,7 @@ export function KafkaMonitorPage() {
                   <Zap className="h-4 w-4 text-muted-foreground" />
                 </CardHeader>
                 <CardContent>
-                  <div className="metric-value">{clusterInfo.totalMessageRate.toLocaleString()}</div>
+                  <div className="metric-value">{kafka.clusterInfo.totalMessageRate.toLocaleString()}</div>
                   <p className="metric-label">Messages per second</p>
                 </CardContent>
               </Card>
@@ -257,7 +293,7 @@ export function KafkaMonitorPage() {
                   <Users className="h-4 w-4 text-muted-foreground" />
                 </CardHeader>
                 <CardContent>
-                  <div className="metric-value">{clusterInfo.totalConsumerGroups}</div>
+                  <div className="metric-value">{kafka.clusterInfo.totalConsumerGroups}</div>
                   <p className="metric-label">Active groups</p>
                 </CardContent>
               </Card>
@@ -265,7 +301,7 @@ export function KafkaMonitorPage() {
 
             {/* Quick Status */}
             <div className="grid grid-cols-1 md:grid-cols-3 gap-4">
-              {kafkaBrokers.map((broker) => (
+              {kafka.brokers.map((broker) => (
                 <Card key={broker.id} className="p-4">
                   <div className="flex items-center justify-between mb-3">
                     <div className="flex items-center gap-2">
@@ -279,15 +315,14 @@ export function KafkaMonitorPage() {
                   </div>
                   <div className="space-y-2">
                     <div className="flex justify-between text-xs">
-                      <span className="text-muted-foreground">Disk</span>
-                      <span>{broker.diskUsage}%</span>
+                      <span className="text-muted-foreground">Leaders</span>
+                      <span>{broker.leaders} / {broker.replicas} replicas</span>
                     </div>
-                    <Progress value={broker.diskUsage} className="h-1" />
+                    <Progress value={broker.replicas ? (broker.leaders / broker.replicas) * 100 : 0} className="h-1" />
                     <div className="flex justify-between text-xs">
-                      <span className="text-muted-foreground">Memory</span>
-                      <span>{broker.memoryUsage}%</span>
+                      <span className="text-muted-foreground">Out-of-sync</span>
+                      <span className={broker.outOfSyncReplicas > 0 ? 'text-yellow-400' : ''}>{broker.outOfSyncReplicas}</span>
                     </div>
-                    <Progress value={broker.memoryUsage} className="h-1" />
                   </div>
                 </Card>
               ))}
@@ -325,7 +360,7 @@ export function KafkaMonitorPage() {
               </CardHeader>
               <CardContent>
                 <div className="space-y-4">
-                  {kafkaBrokers.map((broker) => (
+                  {kafka.brokers.map((broker) => (
                     <Card key={broker.id} className="p-6">
                       <div className="flex items-center justify-between mb-4">
                         <div className="flex items-center gap-3">
@@ -354,39 +389,37 @@ export function KafkaMonitorPage() {
                       <div className="grid grid-cols-2 md:grid-cols-4 lg:grid-cols-6 gap-4">
                         <div className="space-y-1">
                           <div className="flex items-center gap-1 text-xs text-muted-foreground">
-                            <HardDrive className="h-3 w-3" />
-                            Disk Usage
+                            <Cpu className="h-3 w-3" />
+                            Leaders
                           </div>
-                          <div className="text-sm font-medium">{broker.diskUsage}%</div>
-                          <div className="text-xs text-muted-foreground">{broker.diskUsed}</div>
-                          <Progress value={broker.diskUsage} className="h-1" />
+                          <div className="text-sm font-medium">{broker.leaders}</div>
+                          <Progress value={broker.replicas ? (broker.leaders / broker.replicas) * 100 : 0} className="h-1" />
                         </div>
 
                         <div className="space-y-1">
                           <div className="flex items-center gap-1 text-xs text-muted-foreground">
-                            <Cpu className="h-3 w-3" />
-                            CPU
+                            <HardDrive className="h-3 w-3" />
+                            Replicas
                           </div>
-                          <div className="text-sm font-medium">{broker.cpuUsage}%</div>
-                          <Progress value={broker.cpuUsage} className="h-1" />
+                          <div className="text-sm font-medium">{broker.replicas}</div>
                         </div>
 
                         <div className="space-y-1">
                           <div className="flex items-center gap-1 text-xs text-muted-foreground">
                             <Database className="h-3 w-3" />
-                            Memory
+                            Out-of-sync
+                          </div>
+                          <div className={`text-sm font-medium ${broker.outOfSyncReplicas > 0 ? 'text-yellow-400' : 'text-green-400'}`}>
+                            {broker.outOfSyncReplicas}
                           </div>
-                          <div className="text-sm font-medium">{broker.memoryUsage}%</div>
-                          <Progress value={broker.memoryUsage} className="h-1" />
                         </div>
 
                         <div className="space-y-1">
                           <div className="flex items-center gap-1 text-xs text-muted-foreground">
                             <Network className="h-3 w-3" />
-                            Network I/O
+                            Network In
                           </div>
-                          <div className="text-sm font-medium">{broker.networkIn}MB/s</div>
-                          <div className="text-xs text-muted-foreground">↓{broker.networkIn} ↑{broker.networkOut}</div>
+                          <div className="text-sm font-medium">{broker.networkIn}</div>
                         </div>
 
                         <div className="space-y-1">
@@ -400,10 +433,9 @@ export function KafkaMonitorPage() {
                         <div className="space-y-1">
                           <div className="flex items-center gap-1 text-xs text-muted-foreground">
                             <Clock className="h-3 w-3" />
-                            Uptime
+                            Version
                           </div>
-                          <div className="text-sm font-medium">{broker.uptime}</div>
-                          <div className="text-xs text-muted-foreground">{broker.lastUpdate}</div>
+                          <div className="text-sm font-medium">{broker.version || 'unknown'}</div>
                         </div>
                       </div>
                     </Card>
@@ -444,7 +476,7 @@ export function KafkaMonitorPage() {
               </CardHeader>
               <CardContent>
                 <div className="space-y-4">
-                  {kafkaTopics.map((topic) => (
+                  {kafka.topics.map((topic) => (
                     <Card key={topic.name} className="p-6">
                       <div className="flex items-center justify-between mb-4">
                         <div className="flex items-center gap-3">
@@ -479,9 +511,9 @@ export function KafkaMonitorPage() {
                         </div>
 
                         <div className="space-y-1">
-                          <div className="text-xs text-muted-foreground">Under-replicated</div>
-                          <div className={`text-sm font-medium ${topic.underReplicated > 0 ? 'text-yellow-400' : 'text-green-400'}`}>
-                            {topic.underReplicated}
+                          <div className="text-xs text-muted-foreground">Min ISR</div>
+                          <div className={`text-sm font-medium ${topic.minIsr < topic.replicas ? 'text-yellow-400' : 'text-green-400'}`}>
+                            {topic.minIsr}
                           </div>
                         </div>
 
@@ -538,7 +570,7 @@ export function KafkaMonitorPage() {
               </CardHeader>
               <CardContent>
                 <div className="space-y-4">
-                  {consumerGroups.map((group) => (
+                  {kafka.consumerGroups.map((group) => (
                     <Card key={group.name} className="p-6">
                       <div className="flex items-center justify-between mb-4">
                         <div className="flex items-center gap-3">
@@ -575,8 +607,8 @@ export function KafkaMonitorPage() {
                         </div>
 
                         <div className="space-y-1">
-                          <div className="text-xs text-muted-foreground">Topic</div>
-                          <div className="text-sm font-medium">{group.topic}</div>
+                          <div className="text-xs text-muted-foreground">Lag Age</div>
+                          <div className="text-sm font-medium">{Math.round(group.lagSeconds)}s</div>
                         </div>
 
                         <div className="space-y-1">
@@ -1,43 +1,121 @@
-import { get, getApi } from "@/lib/apiClient";
-import { MonitoringRedisData } from "@/models/monitoringModel";
-
-const MONITORING_ENDPOINTS = {
-  redis: "/api/v1/monitoring/redis",
-  kafka: "/api/v1/monitoring/kafka",
-  postgresql: "/api/v1/monitoring/postgresql",
-  mysql: "/api/v1/monitoring/mysql",
+import { getApi } from "@/lib/apiClient";
+import { formatBytes, formatDuration } from "@/lib/utils";
+import type {
+  ClusterInfo,
+  ConsumerGroup,
+  KafkaBroker,
+  MonitoringKafkaData,
+  TopicMetrics,
+} from "@/models/kafkaModel";
+
+const KAFKA_ENDPOINTS = {
+  monitoring: "/api/v1/monitoring/kafka",
 } as const;
 
-export type MonitoringType = keyof typeof MONITORING_ENDPOINTS;
+export interface KafkaResponse {
+  kafka: Record<string, MonitoringKafkaData>;
+}
+
+// KafkaView is a cluster's metrics in the shape the Kafka page renders
+export interface KafkaView {
+  brokers: KafkaBroker[];
+  topics: TopicMetrics[];
+  consumerGroups: ConsumerGroup[];
+  clusterInfo: ClusterInfo;
+}
 
-export type Monitoring = {
-  [K in MonitoringType]: string;
+export const kafkaService = {
+  // Metrics of every connected cluster, keyed by cluster name
+  async getMonitoringKafka(): Promise<Record<string, MonitoringKafkaData>> {
+    const res = await getApi<KafkaResponse>(KAFKA_ENDPOINTS.monitoring);
+    return res.kafka ?? {};
+  },
 };
 
-// Generic API response
-export interface ApiResponse<T> {
-  success: boolean;
-  data: T;
+export function toKafkaView(data: MonitoringKafkaData): KafkaView {
+  return {
+    brokers: toBrokers(data),
+    topics: toTopics(data),
+    consumerGroups: toConsumerGroups(data),
+    clusterInfo: toClusterInfo(data),
+  };
 }
 
-export interface RedisResponse {
-  redis: MonitoringRedisData[];
+function toBrokers(data: MonitoringKafkaData): KafkaBroker[] {
+  return (data.brokers ?? []).map((b) => ({
+    id: b.broker_id,
+    name: `Kafka Broker ${b.broker_id}`,
+    host: `${b.host}:${b.port}`,
+    // degraded (canary failing) and fenced brokers still answer but need attention
+    status:
+      b.status === "online"
+        ? "online"
+        : b.status === "offline"
+          ? "offline"
+          : "warning",
+    isController: b.is_controller,
+    version: b.version,
+    leaders: b.leaders,
+    replicas: b.replicas,
+    outOfSyncReplicas: b.out_of_sync_replicas,
+    networkIn: `${formatBytes(b.bytes_in_per_sec)}/s`,
+    messageRate: Math.round(b.messages_in_per_sec),
+  }));
 }
 
-export const monitoringService = {
-  async getAll(): Promise<Monitoring> {
-    const [redis, kafka, postgresql, mysql] = await Promise.all([
-      get(MONITORING_ENDPOINTS.redis),
-      get(MONITORING_ENDPOINTS.kafka),
-      get(MONITORING_ENDPOINTS.postgresql),
-      get(MONITORING_ENDPOINTS.mysql),
-    ]);
+function toTopics(data: MonitoringKafkaData): TopicMetrics[] {
+  return (data.cluster.topics ?? []).map((t) => ({
+    name: t.name,
+    partitions: t.partitions,
+    replicas: t.replication_factor,
+    status: t.isr_count < t.replication_factor ? "warning" : "healthy",
+    messageRate: Math.round(t.messages_per_sec),
+    size: formatBytes(t.total_size),
+    retention: formatDuration(t.retention_ms),
+    minIsr: t.isr_count,
+  }));
+}
 
-    return { redis, kafka, postgresql, mysql };
-  },
+function toConsumerGroups(data: MonitoringKafkaData): ConsumerGroup[] {
+  return (data.cluster.consumer_groups ?? []).map((g) => {
+    const topics = [...new Set((g.topic_lags ?? []).map((l) => l.topic))];
+    let status: ConsumerGroup["status"] = "healthy";
+    if (g.state === "Dead" || g.state === "Empty") {
+      status = "offline";
+    } else if (g.rebalance_storm || g.rebalance_stuck) {
+      status = "warning";
+    }
 
-  async getMonitoringRedis(): Promise<MonitoringRedisData[]> {
-    const res = await getApi<RedisResponse>(MONITORING_ENDPOINTS.redis);
-    return res.redis;
-  },
-};
+    return {
+      name: g.group_id,
+      topic: topics.join(", ") || "-",
+      consumers: g.members,
+      lag: g.lag,
+      lagSeconds: g.lag_seconds,
+      status,
+    };
+  });
+}
+
+function toClusterInfo(data: MonitoringKafkaData): ClusterInfo {
+  const topics = data.cluster.topics ?? [];
+  const totalSize = topics.reduce((sum, t) => sum + t.total_size, 0);
+  const totalReplication = topics.reduce(
+    (sum, t) => sum + t.replication_factor,
+    0,
+  );
+
+  return {
+    totalBrokers: (data.brokers ?? []).length,
+    totalTopics: data.cluster.total_topics,
+    totalPartitions: data.cluster.total_partitions,
+    totalConsumerGroups: (data.cluster.consumer_groups ?? []).length,
+    totalMessageRate: Math.round(data.cluster.messages_in_per_sec),
+    totalDiskUsage: formatBytes(totalSize),
+    avgReplicationFactor: topics.length
+      ? Math.round((totalReplication / topics.length) * 10) / 10
+      : 0,
+    underReplicated: data.cluster.under_replicated_partitions,
+    offlinePartitions: data.cluster.offline_partitions,
+  };
+}